@@ -0,0 +1,192 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/util"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Upper bound for the NDB lookups performed while admitting a Database,
+// kept below the default 10s timeout of the API server for webhooks.
+const NDB_VALIDATION_TIMEOUT = 5 * time.Second
+
+// Set by EnableNDBReferenceValidation, live validation is skipped when nil
+var ndbValidator *ndbReferenceValidator
+
+// +kubebuilder:object:generate:=false
+// Provides the NDB client of an NDBServer, implemented by the NDB client pool of the controllers
+type NDBClientProvider interface {
+	GetNDBClient(ctx context.Context, ndbServer *NDBServer) (*ndb_client.NDBClient, error)
+}
+
+// Enables the optional validation of the NDB entities referenced in the Database spec
+// (cluster, profiles, SLA and source database) against the NDB instance of the referenced NDBServer.
+// The NDB clients are shared with the controllers and the profiles, SLAs and clusters are served
+// from the NDB cache shared with the controllers (see ndb_api.SetCache).
+func EnableNDBReferenceValidation(k8sClient client.Client, ndbClients NDBClientProvider) {
+	ndbValidator = &ndbReferenceValidator{client: k8sClient, ndbClients: ndbClients}
+}
+
+// +kubebuilder:object:generate:=false
+// Validates the references in a Database spec against the live NDB instance
+type ndbReferenceValidator struct {
+	client     client.Client
+	ndbClients NDBClientProvider
+}
+
+// +kubebuilder:object:generate:=false
+// Entities fetched from NDB that are used for validation
+type ndbCatalog struct {
+	clusters []ndb_api.ClusterResponse
+	profiles []ndb_api.ProfileResponse
+	slas     []ndb_api.SLAResponse
+}
+
+// Validates the NDB references of the database. Returns field errors for references
+// that do not exist on NDB. Fails open, i.e. returns only warnings if the NDBServer,
+// its credentials or the NDB instance itself cannot be reached.
+func (v *ndbReferenceValidator) validate(ctx context.Context, database *Database, specPath *field.Path) (warnings admission.Warnings, errors field.ErrorList) {
+	databaselog.Info("Entering ndbReferenceValidator.validate", "name", database.Name)
+
+	ndbServer := &NDBServer{}
+	ndbServerName := types.NamespacedName{Namespace: database.GetNDBServerNamespace(), Name: database.Spec.NDBRef}
+	if err := v.client.Get(ctx, ndbServerName, ndbServer); err != nil {
+		warnings = append(warnings, fmt.Sprintf("Skipping NDB validation, could not fetch NDBServer %s: %s", ndbServerName.Name, err.Error()))
+		return
+	}
+	if !ndbServer.IsNamespaceAllowed(database.Namespace) {
+		errors = append(errors, field.Forbidden(field.NewPath("spec").Child("ndbRef"),
+			fmt.Sprintf("NDBServer %s does not allow Databases from namespace %s", ndbServerName, database.Namespace)))
+		return
+	}
+
+	ndbClient, err := v.ndbClients.GetNDBClient(ctx, ndbServer)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("Skipping NDB validation, could not read credentials of NDBServer %s: %s", ndbServerName.Name, err.Error()))
+		return
+	}
+
+	catalog, err := v.getCatalog(ctx, ndbClient)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("Skipping NDB validation, NDB is unreachable: %s", err.Error()))
+		return
+	}
+
+	if database.Spec.IsClone {
+		clone := database.Spec.Clone
+		validateClusterReference(catalog, clone.ClusterId, clone.ClusterName, specPath, &errors)
+		validateProfileReferences(catalog, clone.Type, clone.Profiles, specPath.Child("profiles"), &errors)
+		if _, err := ndb_api.GetDatabaseById(ctx, ndbClient, clone.SourceDatabaseId); ndb_api.IsNotFound(err) {
+			errors = append(errors, field.Invalid(specPath.Child("sourceDatabaseId"), clone.SourceDatabaseId, "Source database not found on NDB"))
+		} else if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Skipping the validation of the source database, NDB is unreachable: %s", err.Error()))
+		}
+	} else {
+		instance := database.Spec.Instance
+		validateClusterReference(catalog, instance.ClusterId, instance.ClusterName, specPath, &errors)
+		validateProfileReferences(catalog, instance.Type, instance.Profiles, specPath.Child("profiles"), &errors)
+		if instance.TMInfo != nil {
+			validateSLAReference(catalog, instance.TMInfo.SLAName, specPath.Child("timeMachine").Child("sla"), &errors)
+		}
+	}
+
+	databaselog.Info("Exiting ndbReferenceValidator.validate", "name", database.Name, "errors", len(errors))
+	return
+}
+
+// Returns the clusters, profiles and SLAs of the NDB server, served from the NDB cache if enabled
+func (v *ndbReferenceValidator) getCatalog(ctx context.Context, ndbClient *ndb_client.NDBClient) (catalog *ndbCatalog, err error) {
+	catalog = &ndbCatalog{}
+	if catalog.clusters, err = ndb_api.GetAllClusters(ctx, ndbClient); err != nil {
+		return nil, err
+	}
+	if catalog.profiles, err = ndb_api.GetAllProfiles(ctx, ndbClient); err != nil {
+		return nil, err
+	}
+	if catalog.slas, err = ndb_api.GetAllSLAs(ctx, ndbClient); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Checks that the cluster referenced by id or by name exists on NDB
+func validateClusterReference(catalog *ndbCatalog, clusterId, clusterName string, path *field.Path, errors *field.ErrorList) {
+	if clusterName != "" {
+		if _, err := util.FindFirst(catalog.clusters, func(c ndb_api.ClusterResponse) bool { return c.Name == clusterName }); err != nil {
+			*errors = append(*errors, field.Invalid(path.Child("clusterName"), clusterName, "Cluster not found on NDB"))
+		}
+		return
+	}
+	if _, err := util.FindFirst(catalog.clusters, func(c ndb_api.ClusterResponse) bool { return c.Id == clusterId }); err != nil {
+		*errors = append(*errors, field.Invalid(path.Child("clusterId"), clusterId, "Cluster not found on NDB"))
+	}
+}
+
+func validateSLAReference(catalog *ndbCatalog, slaName string, path *field.Path, errors *field.ErrorList) {
+	if slaName == "" {
+		return
+	}
+	if _, err := util.FindFirst(catalog.slas, func(s ndb_api.SLAResponse) bool { return s.Name == slaName }); err != nil {
+		*errors = append(*errors, field.Invalid(path, slaName, "SLA not found on NDB"))
+	}
+}
+
+// Checks that every profile specified by id and/or name exists on NDB, is READY and matches the engine.
+// Compute profiles are engine agnostic.
+func validateProfileReferences(catalog *ndbCatalog, databaseType string, profiles *Profiles, path *field.Path, errors *field.ErrorList) {
+	if profiles == nil {
+		return
+	}
+	engine := ndb_api.GetDatabaseEngineName(databaseType)
+	profilesToValidate := []struct {
+		profile     Profile
+		profileType string
+		path        *field.Path
+	}{
+		{profiles.Compute, common.PROFILE_TYPE_COMPUTE, path.Child("compute")},
+		{profiles.Software, common.PROFILE_TYPE_SOFTWARE, path.Child("software")},
+		{profiles.Network, common.PROFILE_TYPE_NETWORK, path.Child("network")},
+		{profiles.DbParam, common.PROFILE_TYPE_DATABASE_PARAMETER, path.Child("dbParam")},
+		{profiles.DbParamInstance, common.PROFILE_TYPE_DATABASE_PARAMETER, path.Child("dbParamInstance")},
+	}
+	for _, p := range profilesToValidate {
+		if p.profile.Id == "" && p.profile.Name == "" {
+			continue
+		}
+		_, err := util.FindFirst(catalog.profiles, func(r ndb_api.ProfileResponse) bool {
+			return r.Type == p.profileType &&
+				r.Status == common.PROFILE_STATUS_READY &&
+				(p.profileType == common.PROFILE_TYPE_COMPUTE || r.EngineType == engine) &&
+				(p.profile.Id == "" || r.Id == p.profile.Id) &&
+				(p.profile.Name == "" || r.Name == p.profile.Name)
+		})
+		if err != nil {
+			*errors = append(*errors, field.Invalid(p.path, p.profile, fmt.Sprintf("No READY %s profile matching the given id/name found on NDB", p.profileType)))
+		}
+	}
+}
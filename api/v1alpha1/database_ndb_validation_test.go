@@ -0,0 +1,214 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	MOCK_CLUSTER_ID           = "1d3f7b55-3e57-4f4f-9a3b-5b7e8d5f3c11"
+	MOCK_SOURCE_DB_ID         = "8e1b6a3c-0b0b-4a5e-8c1e-2f5b8a8d9c22"
+	MOCK_FAILING_SOURCE_DB_ID = "5c2d9e4f-7a1b-4c3d-9e8f-3a6b9c0d1e33"
+)
+
+// Provides NDB clients created from the NDBServer spec, without retries to keep the tests fast
+type testNDBClientProvider struct{}
+
+func (testNDBClientProvider) GetNDBClient(ctx context.Context, ndbServer *NDBServer) (*ndb_client.NDBClient, error) {
+	options := ndb_client.DefaultClientOptions()
+	options.MaxRetries = 0
+	return ndb_client.NewNDBClientWithAuthMethod(ndbServer.Spec.AuthMethod, "username", "password", ndbServer.Spec.Server, "", true, options), nil
+}
+
+// Returns a mock NDB server with one cluster, one SLA, one compute profile and one source database.
+// The number of calls made to the catalog endpoints is tracked in catalogCalls.
+func getNDBValidationTestServer(catalogCalls *int) *httptest.Server {
+	responses := map[string]interface{}{
+		"/clusters":                       []ndb_api.ClusterResponse{{Id: MOCK_CLUSTER_ID, Name: "cluster-1"}},
+		"/slas":                           []ndb_api.SLAResponse{{Id: "sla-id", Name: "GOLD"}},
+		"/profiles":                       []ndb_api.ProfileResponse{{Id: "compute-id", Name: "small", Type: common.PROFILE_TYPE_COMPUTE, Status: common.PROFILE_STATUS_READY}},
+		"/databases/" + MOCK_SOURCE_DB_ID: ndb_api.DatabaseResponse{Id: MOCK_SOURCE_DB_ID},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/databases/"+MOCK_FAILING_SOURCE_DB_ID {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path != "/databases/"+MOCK_SOURCE_DB_ID {
+			*catalogCalls++
+		}
+		resp, _ := json.Marshal(response)
+		w.WriteHeader(http.StatusOK)
+		w.Write(resp)
+	}))
+}
+
+func getNDBValidationTestValidator(server string) *ndbReferenceValidator {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&NDBServer{
+			ObjectMeta: metav1.ObjectMeta{Name: NDB_REF, Namespace: NAMESPACE},
+			Spec:       NDBServerSpec{Server: server, CredentialSecret: "ndb-secret"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ndb-secret", Namespace: NAMESPACE},
+			Data: map[string][]byte{
+				common.SECRET_DATA_KEY_USERNAME: []byte("username"),
+				common.SECRET_DATA_KEY_PASSWORD: []byte("password"),
+			},
+		},
+	).Build()
+	return &ndbReferenceValidator{client: fakeClient, ndbClients: testNDBClientProvider{}}
+}
+
+func TestNDBReferenceValidator_validate(t *testing.T) {
+	catalogCalls := 0
+	server := getNDBValidationTestServer(&catalogCalls)
+	defer server.Close()
+	validator := getNDBValidationTestValidator(server.URL)
+	ndb_api.SetCache(ndb_api.NewCache(time.Minute))
+	defer ndb_api.SetCache(nil)
+
+	validDatabase := func() *Database {
+		database := createDefaultDatabase("db")
+		database.Spec.Instance.ClusterId = MOCK_CLUSTER_ID
+		database.Spec.Instance.TMInfo = &DBTimeMachineInfo{SLAName: "GOLD"}
+		return database
+	}
+	validClone := func() *Database {
+		clone := createDefaultClone("clone")
+		clone.Spec.Clone.ClusterId = MOCK_CLUSTER_ID
+		clone.Spec.Clone.SourceDatabaseId = MOCK_SOURCE_DB_ID
+		return clone
+	}
+
+	tests := []struct {
+		name         string
+		database     func() *Database
+		wantErrors   int
+		wantWarnings int
+	}{
+		{
+			name:     "Test 1: valid database references are accepted",
+			database: validDatabase,
+		},
+		{
+			name: "Test 2: unknown cluster, SLA and profile are rejected",
+			database: func() *Database {
+				database := validDatabase()
+				database.Spec.Instance.ClusterId = DEFAULT_UUID
+				database.Spec.Instance.TMInfo.SLAName = "PLATINUM"
+				database.Spec.Instance.Profiles.Compute.Name = "does-not-exist"
+				return database
+			},
+			wantErrors: 3,
+		},
+		{
+			name:     "Test 3: valid clone references are accepted",
+			database: validClone,
+		},
+		{
+			name: "Test 4: unknown source database is rejected",
+			database: func() *Database {
+				clone := validClone()
+				clone.Spec.Clone.SourceDatabaseId = DEFAULT_UUID
+				return clone
+			},
+			wantErrors: 1,
+		},
+		{
+			name: "Test 5: failure to fetch the source database is accepted with a warning",
+			database: func() *Database {
+				clone := validClone()
+				clone.Spec.Clone.SourceDatabaseId = MOCK_FAILING_SOURCE_DB_ID
+				return clone
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "Test 6: missing NDBServer fails open with a warning",
+			database: func() *Database {
+				database := validDatabase()
+				database.Spec.NDBRef = "does-not-exist"
+				return database
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "Test 7: NDBServer not allowing the namespace of the database is rejected",
+			database: func() *Database {
+				database := validDatabase()
+				database.Namespace = "tenant"
+				database.Spec.NDBRefNamespace = NAMESPACE
+				return database
+			},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, errors := validator.validate(context.TODO(), tt.database(), field.NewPath("spec"))
+			if len(errors) != tt.wantErrors {
+				t.Errorf("validate() errors = %v, want %d errors", errors, tt.wantErrors)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("validate() warnings = %v, want %d warnings", warnings, tt.wantWarnings)
+			}
+		})
+	}
+
+	// clusters, profiles and slas should have been fetched only once
+	if catalogCalls != 3 {
+		t.Errorf("expected the catalog to be served from the cache, got %d catalog calls", catalogCalls)
+	}
+}
+
+func TestNDBReferenceValidator_validate_unreachableNDB(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	validator := getNDBValidationTestValidator(server.URL)
+
+	warnings, errors := validator.validate(context.TODO(), createDefaultDatabase("db"), field.NewPath("spec"))
+	if len(errors) != 0 || len(warnings) != 1 {
+		t.Errorf("validate() should fail open with a warning, got errors = %v, warnings = %v", errors, warnings)
+	}
+}
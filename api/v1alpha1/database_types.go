@@ -30,11 +30,28 @@ type DatabaseSpec struct {
 	// +kubebuilder:validation:Required
 	NDBRef string `json:"ndbRef"`
 	// +optional
+	// Namespace of the NDBServer referenced by ndbRef, defaults to the namespace of the Database.
+	// The NDBServer must list the namespace of the Database in its allowedNamespaces.
+	NDBRefNamespace string `json:"ndbRefNamespace,omitempty"`
+	// +optional
 	IsClone bool `json:"isClone"`
 	// +optional
 	Instance *Instance `json:"databaseInstance"`
 	// +optional
 	Clone *Clone `json:"clone"`
+	// +optional
+	// Re-attempts of the creation after it failed on NDB
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+	// +kubebuilder:validation:Enum=Report;Enforce
+	// +optional
+	// Action on the differences between the spec and the database on NDB, default Report.
+	// Enforce reapplies the description, SLA and snapshot schedule on NDB, the other differences are only reported.
+	DriftPolicy string `json:"driftPolicy,omitempty"`
+	// +optional
+	// NDB tags (name: value) of the database or clone, its time machine and database server.
+	// The tags have to be defined on NDB for the entity types, they take precedence over the
+	// tags propagated from the labels and annotations.
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // DatabaseStatus defines the observed state of Database
@@ -46,6 +63,44 @@ type DatabaseStatus struct {
 	Type                      string `json:"type"`
 	CreationOperationId       string `json:"creationOperationId"`
 	DeregistrationOperationId string `json:"deregistrationOperationId"`
+	// +optional
+	// Id of the cluster the database was created on, resolved from the clusterName if specified
+	ClusterId string `json:"clusterId,omitempty"`
+	// +optional
+	// Number of times the creation has been re-attempted
+	CreationRetries int `json:"creationRetries,omitempty"`
+	// +optional
+	// Reason of the last failure of the creation
+	LastCreationFailure string `json:"lastCreationFailure,omitempty"`
+	// +optional
+	// Time of the last failure of the creation, the automatic retries back off from it
+	LastCreationFailureTime *metav1.Time `json:"lastCreationFailureTime,omitempty"`
+	// +optional
+	// Id of the operation deleting the partially created database before the creation is re-attempted
+	CleanupOperationId string `json:"cleanupOperationId,omitempty"`
+	// +optional
+	// Cleanup on NDB the deletion of the Database is waiting for
+	PendingCleanup string `json:"pendingCleanup,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// Latest observations of the state of the database, the Drifted condition reports differences with NDB
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +optional
+	// Fields of the spec that differ from the database on NDB
+	Drift []DriftedField `json:"drift,omitempty"`
+	// +optional
+	// Time the database was last compared with NDB
+	DriftCheckTime *metav1.Time `json:"driftCheckTime,omitempty"`
+	// +optional
+	// Tags (name: value) last applied on NDB to the database or clone, its time machine and database server
+	Tags map[string]string `json:"tags,omitempty"`
+	// +optional
+	// SLA and snapshot schedule of the time machine of the database instance on NDB
+	TimeMachine *TimeMachineStatus `json:"timeMachine,omitempty"`
+	// +optional
+	// Names of the tags not defined on NDB by entity type, they are skipped and applied once they are defined
+	UndefinedTags map[string][]string `json:"undefinedTags,omitempty"`
 }
 
 // Database is the Schema for the databases API
@@ -85,9 +140,13 @@ type Instance struct {
 	// +optional
 	// Description of the database instance
 	Description string `json:"description"`
-	// Id of the cluster to provision the database on
+	// +optional
+	// Id of the cluster to provision the database on, either clusterId or clusterName must be specified
 	ClusterId string `json:"clusterId"`
 	// +optional
+	// Name of the cluster to provision the database on, resolved to the cluster id on NDB
+	ClusterName string `json:"clusterName"`
+	// +optional
 	Profiles *Profiles `json:"profiles"`
 	// Name of the secret holding the credentials for the database instance (password and ssh key)
 	CredentialSecret string `json:"credentialSecret"`
@@ -117,9 +176,13 @@ type Clone struct {
 	Description string `json:"description"`
 	// Type of parent clone
 	Type string `json:"type"`
-	// Id of the cluster to clone the database on
+	// +optional
+	// Id of the cluster to clone the database on, either clusterId or clusterName must be specified
 	ClusterId string `json:"clusterId"`
 	// +optional
+	// Name of the cluster to clone the database on, resolved to the cluster id on NDB
+	ClusterName string `json:"clusterName"`
+	// +optional
 	Profiles *Profiles `json:"profiles"`
 	// Name of the secret holding the credentials for the database instance (password and ssh key)
 	CredentialSecret string `json:"credentialSecret"`
@@ -135,6 +198,102 @@ type Clone struct {
 	AdditionalArguments map[string]string `json:"additionalArguments"`
 }
 
+// Automatic re-attempts of the creation after the creation operation failed on NDB.
+// The creation can also be re-attempted at any time with the ndb.nutanix.com/retry annotation.
+type RetryPolicy struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// Maximum number of automatic re-attempts of the creation, default 0 (only the annotation re-attempts it)
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// Seconds to wait after a failure before re-attempting the creation, doubled after every re-attempt up to an hour, default 60
+	BackoffSeconds int `json:"backoffSeconds,omitempty"`
+}
+
+// Field of the spec that differs from the database on NDB
+type DriftedField struct {
+	// Path of the field in the spec
+	Field string `json:"field"`
+	// Value of the field in the spec
+	Desired string `json:"desired"`
+	// Value of the field on NDB
+	Actual string `json:"actual"`
+}
+
+// SLA, retention and snapshot schedule of a time machine on NDB, as reported by NDB
+type TimeMachineStatus struct {
+	// +optional
+	// Id of the time machine on NDB
+	Id string `json:"id,omitempty"`
+	// +optional
+	// Id of the SLA of the time machine
+	SLAId string `json:"slaId,omitempty"`
+	// +optional
+	// Name of the SLA of the time machine
+	SLAName string `json:"sla,omitempty"`
+	// +optional
+	// Daily snapshot time in HH:MM:SS (24 hour format)
+	DailySnapshotTime string `json:"dailySnapshotTime,omitempty"`
+	// +optional
+	// Number of snapshots per day
+	SnapshotsPerDay int `json:"snapshotsPerDay,omitempty"`
+	// +optional
+	// Log catch up frequency in minutes
+	LogCatchUpFrequency int `json:"logCatchUpFrequency,omitempty"`
+	// +optional
+	// Day of the week for weekly snapshot
+	WeeklySnapshotDay string `json:"weeklySnapshotDay,omitempty"`
+	// +optional
+	// Day of the month for monthly snapshot
+	MonthlySnapshotDay int `json:"monthlySnapshotDay,omitempty"`
+	// +optional
+	// Start month for the quarterly snapshot
+	QuarterlySnapshotMonth string `json:"quarterlySnapshotMonth,omitempty"`
+	// +optional
+	// Day of the month for yearly snapshot
+	YearlySnapshotDay int `json:"yearlySnapshotDay,omitempty"`
+	// +optional
+	// Month for the yearly snapshot
+	YearlySnapshotMonth string `json:"yearlySnapshotMonth,omitempty"`
+	// +optional
+	// Whether the continuous log catch up is enabled
+	LogCatchUpEnabled *bool `json:"logCatchUpEnabled,omitempty"`
+	// +optional
+	// Whether the weekly snapshot is enabled
+	WeeklySnapshotEnabled *bool `json:"weeklySnapshotEnabled,omitempty"`
+	// +optional
+	// Whether the monthly snapshot is enabled
+	MonthlySnapshotEnabled *bool `json:"monthlySnapshotEnabled,omitempty"`
+	// +optional
+	// Whether the quarterly snapshot is enabled
+	QuarterlySnapshotEnabled *bool `json:"quarterlySnapshotEnabled,omitempty"`
+	// +optional
+	// Whether the yearly snapshot is enabled
+	YearlySnapshotEnabled *bool `json:"yearlySnapshotEnabled,omitempty"`
+	// +optional
+	// Number of days the continuous logs are retained as per the SLA
+	ContinuousRetention int `json:"continuousRetention,omitempty"`
+	// +optional
+	// Number of days the daily snapshots are retained as per the SLA
+	DailyRetention int `json:"dailyRetention,omitempty"`
+	// +optional
+	// Number of weeks the weekly snapshots are retained as per the SLA
+	WeeklyRetention int `json:"weeklyRetention,omitempty"`
+	// +optional
+	// Number of months the monthly snapshots are retained as per the SLA
+	MonthlyRetention int `json:"monthlyRetention,omitempty"`
+	// +optional
+	// Number of quarters the quarterly snapshots are retained as per the SLA
+	QuarterlyRetention int `json:"quarterlyRetention,omitempty"`
+	// +optional
+	// Number of years the yearly snapshots are retained as per the SLA
+	YearlyRetention int `json:"yearlyRetention,omitempty"`
+	// +optional
+	// Time machine details of the spec last applied on NDB, the spec is applied again when it changes
+	AppliedSpec *DBTimeMachineInfo `json:"appliedSpec,omitempty"`
+}
+
 // Time Machine details
 type DBTimeMachineInfo struct {
 	// +optional
@@ -165,6 +324,27 @@ type DBTimeMachineInfo struct {
 	// Feb => Feb, May, Aug, Nov.
 	// Mar => Mar, Jun, Sep, Dec.
 	QuarterlySnapshotMonth string `json:"quarterlySnapshotMonth"`
+	// +optional
+	// Day of the month for yearly snapshot, default 31
+	YearlySnapshotDay int `json:"yearlySnapshotDay,omitempty"`
+	// +optional
+	// Month for the yearly snapshot (Jan to Dec), default Dec
+	YearlySnapshotMonth string `json:"yearlySnapshotMonth,omitempty"`
+	// +optional
+	// Enables the continuous log catch up, default true
+	LogCatchUpEnabled *bool `json:"logCatchUpEnabled,omitempty"`
+	// +optional
+	// Enables the weekly snapshot, default true
+	WeeklySnapshotEnabled *bool `json:"weeklySnapshotEnabled,omitempty"`
+	// +optional
+	// Enables the monthly snapshot, default true
+	MonthlySnapshotEnabled *bool `json:"monthlySnapshotEnabled,omitempty"`
+	// +optional
+	// Enables the quarterly snapshot, default true
+	QuarterlySnapshotEnabled *bool `json:"quarterlySnapshotEnabled,omitempty"`
+	// +optional
+	// Enables the yearly snapshot, default false
+	YearlySnapshotEnabled *bool `json:"yearlySnapshotEnabled,omitempty"`
 }
 
 type Profiles struct {
@@ -17,12 +17,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -68,24 +70,47 @@ func (r *Database) ValidateCreate() (admission.Warnings, error) {
 		path = "Instance"
 	}
 
-	getDatabaseWebhookHandler(r).validateCreate(&r.Spec, errors, field.NewPath("spec").Child(path))
+	specPath := field.NewPath("spec").Child(path)
+	getDatabaseWebhookHandler(r).validateCreate(&r.Spec, errors, specPath)
+
+	if r.Spec.NDBRefNamespace != "" {
+		for _, msg := range validation.IsDNS1123Label(r.Spec.NDBRefNamespace) {
+			*errors = append(*errors, field.Invalid(field.NewPath("spec").Child("ndbRefNamespace"), r.Spec.NDBRefNamespace, msg))
+		}
+	}
+
+	// References are looked up on NDB only if the spec is syntactically valid
+	var warnings admission.Warnings
+	if ndbValidator != nil && len(*errors) == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), NDB_VALIDATION_TIMEOUT)
+		defer cancel()
+		var ndbErrors field.ErrorList
+		warnings, ndbErrors = ndbValidator.validate(ctx, r, specPath)
+		*errors = append(*errors, ndbErrors...)
+	}
 
 	combined_err := util.CombineFieldErrors(*errors)
 
-	databaselog.Info("ValidateCreate webhook response...", "combined_err", combined_err)
+	databaselog.Info("ValidateCreate webhook response...", "combined_err", combined_err, "warnings", warnings)
 
 	databaselog.Info("Exiting ValidateCreate!")
 
-	return nil, combined_err
+	return warnings, combined_err
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *Database) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	databaselog.Info("validate update", "name", r.Name)
 
+	// The changes to the time machine of a provisioned database are applied on NDB in place
+	errors := &field.ErrorList{}
+	if !r.Spec.IsClone && r.Spec.Instance != nil && r.Spec.Instance.TMInfo != nil {
+		validateTimeMachine(r.Spec.Instance.TMInfo, field.NewPath("spec").Child("Instance").Child("timeMachine"), errors)
+	}
+
 	// TODO: This method will be used to make fields immutable.
 	// Here you can reject the updates to any fields. I think we should mark everything immutable by default.
-	return nil, nil
+	return nil, util.CombineFieldErrors(*errors)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -0,0 +1,50 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+)
+
+// Returns the namespace of the NDBServer referenced by the Database
+func (r *Database) GetNDBServerNamespace() string {
+	if r.Spec.NDBRefNamespace != "" {
+		return r.Spec.NDBRefNamespace
+	}
+	return r.Namespace
+}
+
+// Returns true if Databases in the given namespace may reference this NDBServer.
+// The namespace of the NDBServer itself is always allowed.
+func (r *NDBServer) IsNamespaceAllowed(namespace string) bool {
+	if namespace == r.Namespace {
+		return true
+	}
+	for _, allowed := range r.Spec.AllowedNamespaces {
+		if allowed == namespace || allowed == common.NDB_ALLOWED_NAMESPACES_ALL {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the Database references this NDBServer from an allowed namespace
+func (r *NDBServer) IsReferencedBy(database *Database) bool {
+	return database.Spec.NDBRef == r.Name &&
+		database.GetNDBServerNamespace() == r.Namespace &&
+		r.IsNamespaceAllowed(database.Namespace)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestNDBServer_IsReferencedBy(t *testing.T) {
+	tests := []struct {
+		name              string
+		allowedNamespaces []string
+		databaseNamespace string
+		ndbRefNamespace   string
+		want              bool
+	}{
+		{
+			name:              "Test 1: database in the namespace of the NDBServer",
+			databaseNamespace: NAMESPACE,
+			want:              true,
+		},
+		{
+			name:              "Test 2: database in another namespace referencing the NDBServer without being allowed",
+			databaseNamespace: "tenant",
+			ndbRefNamespace:   NAMESPACE,
+			want:              false,
+		},
+		{
+			name:              "Test 3: database in an allowed namespace",
+			allowedNamespaces: []string{"tenant"},
+			databaseNamespace: "tenant",
+			ndbRefNamespace:   NAMESPACE,
+			want:              true,
+		},
+		{
+			name:              "Test 4: database in any namespace when all namespaces are allowed",
+			allowedNamespaces: []string{"*"},
+			databaseNamespace: "tenant",
+			ndbRefNamespace:   NAMESPACE,
+			want:              true,
+		},
+		{
+			name:              "Test 5: database in an allowed namespace referencing an NDBServer with the same name in its own namespace",
+			allowedNamespaces: []string{"tenant"},
+			databaseNamespace: "tenant",
+			want:              false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ndbServer := createDefaultNDBServer()
+			ndbServer.Spec.AllowedNamespaces = tt.allowedNamespaces
+			database := createDefaultDatabase("db")
+			database.Namespace = tt.databaseNamespace
+			database.Spec.NDBRefNamespace = tt.ndbRefNamespace
+			if got := ndbServer.IsReferencedBy(database); got != tt.want {
+				t.Errorf("IsReferencedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -30,6 +30,85 @@ type NDBServerSpec struct {
 	// +optional
 	// Skip server's certificate and hostname verification
 	SkipCertificateVerification bool `json:"skipCertificateVerification"`
+	// +kubebuilder:validation:Enum=token;basic
+	// +kubebuilder:default:=token
+	// +optional
+	// Authentication used for the requests to NDB. token fetches short-lived tokens from NDB using the credentials
+	// and falls back to basic if NDB does not issue tokens, basic sends the credentials with every request
+	AuthMethod string `json:"authMethod,omitempty"`
+	// +optional
+	// Namespaces, other than the namespace of the NDBServer, whose Databases may reference this NDBServer.
+	// "*" allows all namespaces.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// +optional
+	// Publishes all the databases and clones on NDB, including the ones not managed by the operator,
+	// in ConfigMaps owned by the NDBServer
+	Inventory *NDBServerInventory `json:"inventory,omitempty"`
+	// +optional
+	// Timeouts, retries and rate limits of the requests sent to NDB
+	ClientOptions *NDBServerClientOptions `json:"clientOptions,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// Maximum number of Databases being provisioned or cloned on NDB through this NDBServer at once,
+	// further Databases are QUEUED until a provisioning operation completes. 0 (default) is unlimited.
+	MaxConcurrentProvisioning int `json:"maxConcurrentProvisioning,omitempty"`
+	// +optional
+	// Deprovisioning of the orphans, the databases and clones created on NDB by the operator whose Database
+	// custom resource no longer exists. The orphans are reported in the status regardless of the policy.
+	OrphanPolicy *NDBServerOrphanPolicy `json:"orphanPolicy,omitempty"`
+	// +optional
+	// Labels of the Databases referencing this NDBServer propagated as NDB tags, keyed by the label
+	// with the name of the tag as value (e.g. team: team, example.com/cost-center: cost-center)
+	TagLabels map[string]string `json:"tagLabels,omitempty"`
+}
+
+// Deprovisioning of the databases and clones created on NDB by the operator without a Database custom resource
+type NDBServerOrphanPolicy struct {
+	// +optional
+	// Deprovisions the orphans, with their time machines and database servers, once the grace period has elapsed
+	Deprovision bool `json:"deprovision,omitempty"`
+	// +kubebuilder:validation:Minimum=300
+	// +optional
+	// Seconds a database or clone has to stay orphaned before it is deprovisioned, default 86400 (a day),
+	// at least 300 (the interval of the orphan scans)
+	GracePeriodSeconds *int `json:"gracePeriodSeconds,omitempty"`
+}
+
+// Configuration of the requests sent to NDB
+type NDBServerClientOptions struct {
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// Timeout in seconds of a single request to NDB, default 30
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	// Maximum number of retries of the GET requests failing with a connection error or a 5xx response, default 3
+	MaxRetries *int `json:"maxRetries,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// Sustained number of requests per second sent to NDB, default 10
+	RequestsPerSecond int `json:"requestsPerSecond,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// Number of requests that may be sent at once above the sustained rate, default 20
+	Burst int `json:"burst,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// Maximum number of requests outstanding on NDB at once, default 10
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+}
+
+// Configuration of the inventory of all the databases on NDB
+type NDBServerInventory struct {
+	// +optional
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:default:=500
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2000
+	// +optional
+	// Maximum number of databases stored in a single inventory ConfigMap
+	PageSize int `json:"pageSize"`
 }
 
 // NDBServerStatus defines the observed state of NDBServer
@@ -38,10 +117,54 @@ type NDBServerStatus struct {
 	LastUpdated      string                           `json:"lastUpdated"`
 	Databases        map[string]NDBServerDatabaseInfo `json:"databases"`
 	ReconcileCounter ReconcileCounter                 `json:"reconcileCounter"`
+	// +optional
+	// Names of the ConfigMaps holding the inventory of all the databases on NDB, in page order
+	InventoryConfigMaps []string `json:"inventoryConfigMaps,omitempty"`
+	// +optional
+	// Read-only catalog of the clusters, profiles and SLAs available on NDB
+	Catalog *NDBServerCatalog `json:"catalog,omitempty"`
+	// +optional
+	// Databases (namespace/name) waiting to be provisioned or cloned because maxConcurrentProvisioning is reached, in queue order
+	ProvisioningQueue []string `json:"provisioningQueue,omitempty"`
+	// +optional
+	// Databases and clones created on NDB by the operator (tagged with its cluster id) whose Database custom resource no longer exists
+	Orphans []NDBServerOrphan `json:"orphans,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// Latest observations of the periodic tasks of the NDBServer, the CatalogSynced and OrphansScanned conditions
+	// report the failures of the catalog refresh and of the orphan scan
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type ReconcileCounter struct {
 	Database int `json:"database"`
+	// +optional
+	Catalog int `json:"catalog,omitempty"`
+	// +optional
+	Orphans int `json:"orphans,omitempty"`
+}
+
+// Database or clone on NDB without a Database custom resource
+type NDBServerOrphan struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// DATABASE, CLONE or DATABASE_SERVER
+	Type string `json:"type"`
+	// +optional
+	// UID of the Database custom resource the database, clone or database server was created for
+	DatabaseUID string `json:"databaseUid,omitempty"`
+	// +optional
+	// Database servers of the database or clone, deleted after it when it is deprovisioned
+	DBServerIds []string `json:"dbServerIds,omitempty"`
+	// Time the orphan was first detected, the grace period of its deprovisioning starts from it
+	DetectedAt metav1.Time `json:"detectedAt"`
+	// +optional
+	// Number of consecutive scans that found the orphan, it is only deprovisioned once found by two scans
+	Scans int `json:"scans,omitempty"`
+	// +optional
+	// Id of the operation deprovisioning the orphan
+	DeprovisionOperationId string `json:"deprovisionOperationId,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -82,3 +205,52 @@ type NDBServerDatabaseInfo struct {
 	IPAddress     string `json:"ipAddress"`
 	Type          string `json:"type"`
 }
+
+// Clusters, profiles and SLAs available on NDB that can be referenced in the Database specs
+type NDBServerCatalog struct {
+	// +optional
+	Clusters []NDBServerCatalogCluster `json:"clusters,omitempty"`
+	// +optional
+	// Profiles grouped by database type (generic for the compute profiles)
+	Profiles map[string][]NDBServerCatalogProfile `json:"profiles,omitempty"`
+	// +optional
+	SLAs []NDBServerCatalogSLA `json:"slas,omitempty"`
+}
+
+type NDBServerCatalogCluster struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	Version        string `json:"version"`
+	HypervisorType string `json:"hypervisorType"`
+}
+
+type NDBServerCatalogProfile struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Topology string `json:"topology"`
+	Status   string `json:"status"`
+	// +optional
+	// Latest version of the profile, the older versions are not published to keep the status small
+	LatestVersion *NDBServerCatalogProfileVersion `json:"latestVersion,omitempty"`
+}
+
+type NDBServerCatalogProfileVersion struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+// SLA with its retention values, continuous retention is in days and the others in number of snapshots
+type NDBServerCatalogSLA struct {
+	Id                  string `json:"id"`
+	Name                string `json:"name"`
+	ContinuousRetention int    `json:"continuousRetention"`
+	DailyRetention      int    `json:"dailyRetention"`
+	WeeklyRetention     int    `json:"weeklyRetention"`
+	MonthlyRetention    int    `json:"monthlyRetention"`
+	QuarterlyRetention  int    `json:"quarterlyRetention"`
+	YearlyRetention     int    `json:"yearlyRetention"`
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/util"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var ndbserverlog = logf.Log.WithName("ndbserver-resource")
+
+// The NDB server url must point to the versioned API base, for example https://10.0.0.1:8443/era/v0.9
+var ndbServerAPIBasePathRegex = regexp.MustCompile(`^/era/v[0-9]+(\.[0-9]+)*$`)
+
+// Timeout for the kubernetes API calls made while validating an NDBServer
+const NDBSERVER_VALIDATION_TIMEOUT = 5 * time.Second
+
+// Client used to look up the credential secret and the referencing Databases, set in SetupWebhookWithManager
+var ndbServerWebhookClient client.Client
+
+func (r *NDBServer) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	ndbServerWebhookClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-ndb-nutanix-com-v1alpha1-ndbserver,mutating=true,failurePolicy=fail,sideEffects=None,groups=ndb.nutanix.com,resources=ndbservers,verbs=create;update,versions=v1alpha1,name=mndbserver.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &NDBServer{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *NDBServer) Default() {
+	ndbserverlog.Info("Entering Default()...", "name", r.Name)
+
+	server := strings.TrimRight(strings.TrimSpace(r.Spec.Server), "/")
+	if server != r.Spec.Server {
+		ndbserverlog.Info(fmt.Sprintf("Initializing Server to: %s.", server))
+		r.Spec.Server = server
+	}
+
+	ndbserverlog.Info("Exiting Default()!")
+}
+
+// +kubebuilder:webhook:path=/validate-ndb-nutanix-com-v1alpha1-ndbserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=ndb.nutanix.com,resources=ndbservers,verbs=create;update,versions=v1alpha1,name=vndbserver.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &NDBServer{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *NDBServer) ValidateCreate() (admission.Warnings, error) {
+	ndbserverlog.Info("Entering ValidateCreate...", "name", r.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), NDBSERVER_VALIDATION_TIMEOUT)
+	defer cancel()
+
+	errors := &field.ErrorList{}
+	warnings := r.validateSpec(ctx, errors, field.NewPath("spec"), true)
+
+	combined_err := util.CombineFieldErrors(*errors)
+
+	ndbserverlog.Info("ValidateCreate webhook response...", "combined_err", combined_err, "warnings", warnings)
+
+	ndbserverlog.Info("Exiting ValidateCreate!")
+
+	return warnings, combined_err
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *NDBServer) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	ndbserverlog.Info("Entering ValidateUpdate...", "name", r.Name)
+
+	// The NDBServer is being deleted, the updates removing its finalizers must not be blocked
+	if r.DeletionTimestamp != nil {
+		ndbserverlog.Info("Exiting ValidateUpdate, the NDBServer is being deleted!")
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), NDBSERVER_VALIDATION_TIMEOUT)
+	defer cancel()
+
+	errors := &field.ErrorList{}
+	specPath := field.NewPath("spec")
+	// The credential secret is only read when it changes, a secret deleted or rotated
+	// afterwards must not block the unrelated updates of the NDBServer
+	oldNDBServer, ok := old.(*NDBServer)
+	validateSecret := !ok || oldNDBServer.Spec.CredentialSecret != r.Spec.CredentialSecret
+	warnings := r.validateSpec(ctx, errors, specPath, validateSecret)
+
+	// The databases provisioned through this NDBServer live on the NDB instance
+	// it points to, changing the server would orphan them.
+	if ok && oldNDBServer.Spec.Server != r.Spec.Server && ndbServerWebhookClient != nil {
+		databases, err := r.getReferencingDatabases(ctx)
+		if err != nil {
+			*errors = append(*errors, field.InternalError(specPath.Child("server"), fmt.Errorf("could not list the Databases referencing this NDBServer: %w", err)))
+		} else if len(databases) > 0 {
+			*errors = append(*errors, field.Forbidden(specPath.Child("server"),
+				fmt.Sprintf("Server cannot be updated while Databases reference this NDBServer: %s", strings.Join(databases, ", "))))
+		}
+	}
+
+	combined_err := util.CombineFieldErrors(*errors)
+
+	ndbserverlog.Info("ValidateUpdate webhook response...", "combined_err", combined_err, "warnings", warnings)
+
+	ndbserverlog.Info("Exiting ValidateUpdate!")
+
+	return warnings, combined_err
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *NDBServer) ValidateDelete() (admission.Warnings, error) {
+	ndbserverlog.Info("validate delete", "name", r.Name)
+
+	return nil, nil
+}
+
+// Validates the server url, the allowed namespaces and the credential secret, appends the validation errors to errors.
+// The content of the credential secret is only checked if validateSecret is true.
+// Returns the warnings for settings which are allowed but not recommended.
+func (r *NDBServer) validateSpec(ctx context.Context, errors *field.ErrorList, specPath *field.Path, validateSecret bool) (warnings admission.Warnings) {
+	serverPath := specPath.Child("server")
+	if err := util.ValidateURL(r.Spec.Server); err != nil {
+		*errors = append(*errors, field.Invalid(serverPath, r.Spec.Server, "Server must be a valid URL: "+err.Error()))
+	} else {
+		serverURL, _ := url.Parse(r.Spec.Server)
+		if serverURL.Scheme != "http" && serverURL.Scheme != "https" {
+			*errors = append(*errors, field.Invalid(serverPath, r.Spec.Server, "Server must use the http or https scheme"))
+		}
+		if !ndbServerAPIBasePathRegex.MatchString(serverURL.Path) {
+			*errors = append(*errors, field.Invalid(serverPath, r.Spec.Server, "Server must point to the NDB API base, for example https://<NDB IP>:8443/era/v0.9"))
+		}
+	}
+
+	allowedNamespacesPath := specPath.Child("allowedNamespaces")
+	for i, namespace := range r.Spec.AllowedNamespaces {
+		if namespace == common.NDB_ALLOWED_NAMESPACES_ALL {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			*errors = append(*errors, field.Invalid(allowedNamespacesPath.Index(i), namespace, msg))
+		}
+	}
+
+	secretPath := specPath.Child("credentialSecret")
+	if r.Spec.CredentialSecret == "" {
+		*errors = append(*errors, field.Required(secretPath, "CredentialSecret must be provided in the NDBServer Spec"))
+		return
+	}
+	if ndbServerWebhookClient == nil || !validateSecret {
+		return
+	}
+	secretData, err := util.GetAllDataFromSecret(ctx, ndbServerWebhookClient, r.Spec.CredentialSecret, r.Namespace)
+	if err != nil {
+		*errors = append(*errors, field.Invalid(secretPath, r.Spec.CredentialSecret, "CredentialSecret could not be read: "+err.Error()))
+		return
+	}
+	for _, key := range []string{common.SECRET_DATA_KEY_USERNAME, common.SECRET_DATA_KEY_PASSWORD} {
+		if len(secretData[key]) == 0 {
+			*errors = append(*errors, field.Invalid(secretPath, r.Spec.CredentialSecret, fmt.Sprintf("CredentialSecret must contain a non-empty '%s' key", key)))
+		}
+	}
+	if r.Spec.SkipCertificateVerification && len(secretData[common.SECRET_DATA_KEY_CA_CERTIFICATE]) == 0 {
+		warnings = append(warnings, fmt.Sprintf("skipCertificateVerification is true and no '%s' is provided in the CredentialSecret, the NDB server's certificate will not be verified", common.SECRET_DATA_KEY_CA_CERTIFICATE))
+	}
+	return
+}
+
+// Returns the namespaced names of the Databases that reference this NDBServer from all allowed namespaces
+func (r *NDBServer) getReferencingDatabases(ctx context.Context) (names []string, err error) {
+	databaseList := &DatabaseList{}
+	if err = ndbServerWebhookClient.List(ctx, databaseList); err != nil {
+		return
+	}
+	for _, database := range databaseList.Items {
+		if r.IsReferencedBy(&database) {
+			names = append(names, database.Namespace+"/"+database.Name)
+		}
+	}
+	return
+}
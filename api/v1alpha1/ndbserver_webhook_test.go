@@ -0,0 +1,251 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	MOCK_NDB_SERVER_URL = "https://10.10.10.10:8443/era/v0.9"
+	MOCK_NDB_SECRET     = "ndb-secret"
+)
+
+// Sets the webhook client to a fake client containing the given objects, returns a function restoring it
+func setNDBServerWebhookTestClient(objects ...client.Object) func() {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = AddToScheme(scheme)
+	previous := ndbServerWebhookClient
+	ndbServerWebhookClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	return func() { ndbServerWebhookClient = previous }
+}
+
+func createDefaultNDBServer() *NDBServer {
+	return &NDBServer{
+		ObjectMeta: metav1.ObjectMeta{Name: NDB_REF, Namespace: NAMESPACE},
+		Spec: NDBServerSpec{
+			Server:           MOCK_NDB_SERVER_URL,
+			CredentialSecret: MOCK_NDB_SECRET,
+		},
+	}
+}
+
+func createNDBServerSecret(data map[string]string) *corev1.Secret {
+	secretData := make(map[string][]byte)
+	for key, value := range data {
+		secretData[key] = []byte(value)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: MOCK_NDB_SECRET, Namespace: NAMESPACE},
+		Data:       secretData,
+	}
+}
+
+func TestNDBServer_Default(t *testing.T) {
+	ndbServer := createDefaultNDBServer()
+	ndbServer.Spec.Server = " " + MOCK_NDB_SERVER_URL + "/ "
+	ndbServer.Default()
+	if ndbServer.Spec.Server != MOCK_NDB_SERVER_URL {
+		t.Errorf("Default() server = %s, want %s", ndbServer.Spec.Server, MOCK_NDB_SERVER_URL)
+	}
+}
+
+func TestNDBServer_ValidateCreate(t *testing.T) {
+	validSecret := createNDBServerSecret(map[string]string{
+		common.SECRET_DATA_KEY_USERNAME: "username",
+		common.SECRET_DATA_KEY_PASSWORD: "password",
+	})
+	tests := []struct {
+		name         string
+		ndbServer    func() *NDBServer
+		secret       *corev1.Secret
+		wantErr      bool
+		wantWarnings int
+	}{
+		{
+			name:      "Test 1: valid NDBServer is accepted",
+			ndbServer: createDefaultNDBServer,
+			secret:    validSecret,
+		},
+		{
+			name: "Test 2: invalid server url is rejected",
+			ndbServer: func() *NDBServer {
+				ndbServer := createDefaultNDBServer()
+				ndbServer.Spec.Server = "10.10.10.10:8443"
+				return ndbServer
+			},
+			secret:  validSecret,
+			wantErr: true,
+		},
+		{
+			name: "Test 3: server url without the API base is rejected",
+			ndbServer: func() *NDBServer {
+				ndbServer := createDefaultNDBServer()
+				ndbServer.Spec.Server = "https://10.10.10.10:8443"
+				return ndbServer
+			},
+			secret:  validSecret,
+			wantErr: true,
+		},
+		{
+			name:      "Test 4: missing secret is rejected",
+			ndbServer: createDefaultNDBServer,
+			wantErr:   true,
+		},
+		{
+			name:      "Test 5: secret without password is rejected",
+			ndbServer: createDefaultNDBServer,
+			secret:    createNDBServerSecret(map[string]string{common.SECRET_DATA_KEY_USERNAME: "username"}),
+			wantErr:   true,
+		},
+		{
+			name: "Test 6: invalid allowed namespace is rejected",
+			ndbServer: func() *NDBServer {
+				ndbServer := createDefaultNDBServer()
+				ndbServer.Spec.AllowedNamespaces = []string{"tenant", "Not_A_Namespace"}
+				return ndbServer
+			},
+			secret:  validSecret,
+			wantErr: true,
+		},
+		{
+			name: "Test 7: skipCertificateVerification without a CA certificate is accepted with a warning",
+			ndbServer: func() *NDBServer {
+				ndbServer := createDefaultNDBServer()
+				ndbServer.Spec.SkipCertificateVerification = true
+				return ndbServer
+			},
+			secret:       validSecret,
+			wantWarnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []client.Object{}
+			if tt.secret != nil {
+				objects = append(objects, tt.secret)
+			}
+			defer setNDBServerWebhookTestClient(objects...)()
+
+			warnings, err := tt.ndbServer().ValidateCreate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("ValidateCreate() warnings = %v, want %d warnings", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestNDBServer_ValidateUpdate(t *testing.T) {
+	secret := createNDBServerSecret(map[string]string{
+		common.SECRET_DATA_KEY_USERNAME: "username",
+		common.SECRET_DATA_KEY_PASSWORD: "password",
+	})
+	database := createDefaultDatabase("db")
+	tenantDatabase := createDefaultDatabase("tenant-db")
+	tenantDatabase.Namespace = "tenant"
+	tenantDatabase.Spec.NDBRefNamespace = NAMESPACE
+
+	tests := []struct {
+		name              string
+		objects           []client.Object
+		allowedNamespaces []string
+		newServer         string
+		newSecret         string
+		deleting          bool
+		wantErr           bool
+	}{
+		{
+			name:      "Test 1: server update is allowed when no Database references the NDBServer",
+			objects:   []client.Object{secret},
+			newServer: "https://10.10.10.11:8443/era/v0.9",
+		},
+		{
+			name:      "Test 2: server update is rejected when a Database references the NDBServer",
+			objects:   []client.Object{secret, database},
+			newServer: "https://10.10.10.11:8443/era/v0.9",
+			wantErr:   true,
+		},
+		{
+			name:      "Test 3: updates not changing the server are allowed when a Database references the NDBServer",
+			objects:   []client.Object{secret, database},
+			newServer: MOCK_NDB_SERVER_URL,
+		},
+		{
+			name:              "Test 4: server update is rejected when a Database from an allowed namespace references the NDBServer",
+			objects:           []client.Object{secret, tenantDatabase},
+			allowedNamespaces: []string{"tenant"},
+			newServer:         "https://10.10.10.11:8443/era/v0.9",
+			wantErr:           true,
+		},
+		{
+			name:      "Test 5: server update is allowed when the referencing Database's namespace is not allowed",
+			objects:   []client.Object{secret, tenantDatabase},
+			newServer: "https://10.10.10.11:8443/era/v0.9",
+		},
+		{
+			name:      "Test 6: updates not changing the secret are allowed when the secret was deleted",
+			newServer: MOCK_NDB_SERVER_URL,
+		},
+		{
+			name:      "Test 7: changing the secret to a missing secret is rejected",
+			objects:   []client.Object{secret},
+			newServer: MOCK_NDB_SERVER_URL,
+			newSecret: "missing-secret",
+			wantErr:   true,
+		},
+		{
+			name:      "Test 8: updates of an NDBServer being deleted are allowed",
+			newServer: "invalid-url",
+			newSecret: "missing-secret",
+			deleting:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setNDBServerWebhookTestClient(tt.objects...)()
+
+			oldNDBServer := createDefaultNDBServer()
+			newNDBServer := createDefaultNDBServer()
+			oldNDBServer.Spec.AllowedNamespaces = tt.allowedNamespaces
+			newNDBServer.Spec.AllowedNamespaces = tt.allowedNamespaces
+			newNDBServer.Spec.Server = tt.newServer
+			if tt.newSecret != "" {
+				newNDBServer.Spec.CredentialSecret = tt.newSecret
+			}
+			if tt.deleting {
+				newNDBServer.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			}
+			_, err := newNDBServer.ValidateUpdate(oldNDBServer)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -59,9 +59,7 @@ func (v *CloningWebhookHandler) validateCreate(spec *DatabaseSpec, errors *field
 		*errors = append(*errors, field.Invalid(clonePath.Child("name"), clone.Name, "A valid Clone Name must be specified"))
 	}
 
-	if err := util.ValidateUUID(clone.ClusterId); err != nil {
-		*errors = append(*errors, field.Invalid(clonePath.Child("clusterId"), clone.ClusterId, "ClusterId field must be a valid UUID"))
-	}
+	validateCluster(clone.ClusterId, clone.ClusterName, clonePath, errors)
 
 	if clone.CredentialSecret == "" {
 		*errors = append(*errors, field.Invalid(clonePath.Child("credentialSecret"), clone.CredentialSecret, "CredentialSecret must be provided in the Clone Spec"))
@@ -125,42 +123,57 @@ func (v *ProvisioningWebhookHandler) defaulter(spec *DatabaseSpec) {
 	}
 
 	// TMInfo defaulting logic
-	if spec.Instance.TMInfo.SnapshotsPerDay == 0 {
+	spec.Instance.TMInfo.SetDefaults()
+
+	databaselog.Info("Exiting defaulter for provisioning")
+}
+
+// Sets the defaults of the SLA and the snapshot schedule of the time machine details that are not set
+func (tmInfo *DBTimeMachineInfo) SetDefaults() {
+	if tmInfo.SnapshotsPerDay == 0 {
 		databaselog.Info(fmt.Sprintf("Initializing TMInfo.SnapshotsPerDay to: %d", 1))
-		spec.Instance.TMInfo.SnapshotsPerDay = 1
+		tmInfo.SnapshotsPerDay = 1
 	}
 
-	if spec.Instance.TMInfo.SLAName == "" {
+	if tmInfo.SLAName == "" {
 		databaselog.Info(fmt.Sprintf("Initializing TMInfo.SLAName to: %s", common.SLA_NAME_NONE))
-		spec.Instance.TMInfo.SLAName = common.SLA_NAME_NONE
+		tmInfo.SLAName = common.SLA_NAME_NONE
 	}
 
-	if spec.Instance.TMInfo.DailySnapshotTime == "" {
+	if tmInfo.DailySnapshotTime == "" {
 		databaselog.Info(fmt.Sprintf("Initializing TMInfo.DailySnapshotTime to: %s", "04:00:00"))
-		spec.Instance.TMInfo.DailySnapshotTime = "04:00:00"
+		tmInfo.DailySnapshotTime = "04:00:00"
 	}
 
-	if spec.Instance.TMInfo.LogCatchUpFrequency == 0 {
+	if tmInfo.LogCatchUpFrequency == 0 {
 		databaselog.Info(fmt.Sprintf("Initializing TMInfo.LogCatchUpFrequency to: %d", 30))
-		spec.Instance.TMInfo.LogCatchUpFrequency = 30
+		tmInfo.LogCatchUpFrequency = 30
 	}
 
-	if spec.Instance.TMInfo.WeeklySnapshotDay == "" {
+	if tmInfo.WeeklySnapshotDay == "" {
 		databaselog.Info(fmt.Sprintf("Initializing TMInfo.WeeklySnapshotDay to: %s", "FRIDAY"))
-		spec.Instance.TMInfo.WeeklySnapshotDay = "FRIDAY"
+		tmInfo.WeeklySnapshotDay = "FRIDAY"
 	}
 
-	if spec.Instance.TMInfo.MonthlySnapshotDay == 0 {
+	if tmInfo.MonthlySnapshotDay == 0 {
 		databaselog.Info(fmt.Sprintf("Initializing TMInfo.MonthlySnapshotDay to: %d", 15))
-		spec.Instance.TMInfo.MonthlySnapshotDay = 15
+		tmInfo.MonthlySnapshotDay = 15
 	}
 
-	if spec.Instance.TMInfo.QuarterlySnapshotMonth == "" {
+	if tmInfo.QuarterlySnapshotMonth == "" {
 		databaselog.Info(fmt.Sprintf("Initializing TMInfo.QuarterlySnapshotMonth to: %s", "Jan"))
-		spec.Instance.TMInfo.QuarterlySnapshotMonth = "Jan"
+		tmInfo.QuarterlySnapshotMonth = "Jan"
 	}
 
-	databaselog.Info("Exiting defaulter for provisioning")
+	if tmInfo.YearlySnapshotDay == 0 {
+		databaselog.Info(fmt.Sprintf("Initializing TMInfo.YearlySnapshotDay to: %d", 31))
+		tmInfo.YearlySnapshotDay = 31
+	}
+
+	if tmInfo.YearlySnapshotMonth == "" {
+		databaselog.Info(fmt.Sprintf("Initializing TMInfo.YearlySnapshotMonth to: %s", "Dec"))
+		tmInfo.YearlySnapshotMonth = "Dec"
+	}
 }
 
 func (v *ProvisioningWebhookHandler) validateCreate(spec *DatabaseSpec, errors *field.ErrorList, instancePath *field.Path) {
@@ -174,9 +187,7 @@ func (v *ProvisioningWebhookHandler) validateCreate(spec *DatabaseSpec, errors *
 		*errors = append(*errors, field.Invalid(instancePath.Child("name"), instance.Name, "A valid Database Instance Name must be specified"))
 	}
 
-	if err := util.ValidateUUID(instance.ClusterId); err != nil {
-		*errors = append(*errors, field.Invalid(instancePath.Child("clusterId"), instance.ClusterId, "ClusterId field must be a valid UUID"))
-	}
+	validateCluster(instance.ClusterId, instance.ClusterName, instancePath, errors)
 
 	if instance.Size < 10 {
 		*errors = append(*errors, field.Invalid(instancePath.Child("size"), instance.Size, "Initial Database size must be specified with a value 10 GBs or more"))
@@ -199,6 +210,17 @@ func (v *ProvisioningWebhookHandler) validateCreate(spec *DatabaseSpec, errors *
 	}
 
 	// validating time machine info
+	validateTimeMachine(tmInfo, tmPath, errors)
+
+	if err := additionalArgumentsValidationCheck(spec.IsClone, instance.Type, instance.AdditionalArguments); err != nil {
+		*errors = append(*errors, field.Invalid(instancePath.Child("additionalArguments"), instance.AdditionalArguments, err.Error()))
+	}
+
+	databaselog.Info("Exiting validateCreate for provisioning")
+}
+
+// Validates the snapshot schedule of the time machine, on creation and on the updates applied in place
+func validateTimeMachine(tmInfo *DBTimeMachineInfo, tmPath *field.Path, errors *field.ErrorList) {
 	dailySnapshotTimeRegex := regexp.MustCompile(`^(2[0-3]|[01][0-9]):[0-5][0-9]:[0-5][0-9]$`)
 	if isMatch := dailySnapshotTimeRegex.MatchString(tmInfo.DailySnapshotTime); !isMatch {
 		*errors = append(*errors, field.Invalid(tmPath.Child("dailySnapshotTime"), tmInfo.DailySnapshotTime, "Invalid time format for the daily snapshot time. Use the 24-hour format (HH:MM:SS)."))
@@ -230,11 +252,15 @@ func (v *ProvisioningWebhookHandler) validateCreate(spec *DatabaseSpec, errors *
 		))
 	}
 
-	if err := additionalArgumentsValidationCheck(spec.IsClone, instance.Type, instance.AdditionalArguments); err != nil {
-		*errors = append(*errors, field.Invalid(instancePath.Child("additionalArguments"), instance.AdditionalArguments, err.Error()))
+	if days, isPresent := api.AllowedYearlySnapshotMonths[tmInfo.YearlySnapshotMonth]; !isPresent {
+		*errors = append(*errors, field.Invalid(tmPath.Child("yearlySnapshotMonth"), tmInfo.YearlySnapshotMonth,
+			fmt.Sprintf("Yearly snapshot month must be specified. Valid values are: %s", reflect.ValueOf(api.AllowedYearlySnapshotMonths).MapKeys()),
+		))
+	} else if tmInfo.YearlySnapshotDay < 1 || tmInfo.YearlySnapshotDay > days {
+		*errors = append(*errors, field.Invalid(tmPath.Child("yearlySnapshotDay"), tmInfo.YearlySnapshotDay,
+			fmt.Sprintf("Yearly snapshot day value must be between 1 and %d for %s", days, tmInfo.YearlySnapshotMonth),
+		))
 	}
-
-	databaselog.Info("Exiting validateCreate for provisioning")
 }
 
 func initializeObjects(spec *DatabaseSpec) {
@@ -278,3 +304,17 @@ func initializeObjects(spec *DatabaseSpec) {
 
 	databaselog.Info("Exiting initializeObjects logic!")
 }
+
+// Validates that either a valid clusterId UUID or a clusterName is specified, but not both
+func validateCluster(clusterId, clusterName string, path *field.Path, errors *field.ErrorList) {
+	switch {
+	case clusterId != "" && clusterName != "":
+		*errors = append(*errors, field.Invalid(path.Child("clusterName"), clusterName, "Only one of ClusterId or ClusterName must be specified"))
+	case clusterName != "":
+		// The cluster name is resolved on NDB
+	default:
+		if err := util.ValidateUUID(clusterId); err != nil {
+			*errors = append(*errors, field.Invalid(path.Child("clusterId"), clusterId, "ClusterId field must be a valid UUID, or ClusterName must be specified"))
+		}
+	}
+}
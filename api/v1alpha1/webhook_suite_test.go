@@ -35,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -100,6 +101,9 @@ var _ = BeforeEach(func() {
 	err = admissionv1beta1.AddToScheme(scheme)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = clientgoscheme.AddToScheme(scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	//+kubebuilder:scaffold:scheme
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
@@ -121,6 +125,9 @@ var _ = BeforeEach(func() {
 	err = (&Database{}).SetupWebhookWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = (&NDBServer{}).SetupWebhookWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
 	//+kubebuilder:scaffold:webhook
 
 	go func() {
@@ -172,6 +179,25 @@ var _ = Describe("Webhook Tests", func() {
 			Expect(errMsg).To(ContainSubstring("ClusterId field must be a valid UUID"))
 		})
 
+		It("Should not error out for ClusterName instead of ClusterId", func() {
+			database := createDefaultDatabase("db-cluster-name")
+			database.Spec.Instance.ClusterId = ""
+			database.Spec.Instance.ClusterName = "cluster"
+
+			err := k8sClient.Create(context.Background(), database)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should check for both ClusterId and ClusterName", func() {
+			database := createDefaultDatabase("db-cluster-id-and-name")
+			database.Spec.Instance.ClusterName = "cluster"
+
+			err := k8sClient.Create(context.Background(), database)
+			Expect(err).To(HaveOccurred())
+			errMsg := err.(*errors.StatusError).ErrStatus.Message
+			Expect(errMsg).To(ContainSubstring("Only one of ClusterId or ClusterName must be specified"))
+		})
+
 		It("Should check for missing CredentialSecret", func() {
 			database := createDefaultDatabase("db3")
 			database.Spec.Instance.CredentialSecret = ""
@@ -212,6 +238,37 @@ var _ = Describe("Webhook Tests", func() {
 			Expect(errMsg).To(ContainSubstring("A valid database type must be specified. Valid values are: "))
 		})
 
+		It("Should check for invalid yearlySnapshotMonth", func() {
+			database := createDefaultDatabase("db-yearly-month")
+			database.Spec.Instance.TMInfo = &DBTimeMachineInfo{YearlySnapshotMonth: "December"}
+
+			err := k8sClient.Create(context.Background(), database)
+			Expect(err).To(HaveOccurred())
+			errMsg := err.(*errors.StatusError).ErrStatus.Message
+			Expect(errMsg).To(ContainSubstring("Yearly snapshot month must be specified. Valid values are: "))
+		})
+
+		It("Should check for yearlySnapshotDay beyond the end of the month", func() {
+			database := createDefaultDatabase("db-yearly-day")
+			database.Spec.Instance.TMInfo = &DBTimeMachineInfo{YearlySnapshotDay: 30, YearlySnapshotMonth: "Feb"}
+
+			err := k8sClient.Create(context.Background(), database)
+			Expect(err).To(HaveOccurred())
+			errMsg := err.(*errors.StatusError).ErrStatus.Message
+			Expect(errMsg).To(ContainSubstring("Yearly snapshot day value must be between 1 and 28 for Feb"))
+		})
+
+		It("Should check the time machine on update", func() {
+			database := createDefaultDatabase("db-tm-update")
+			Expect(k8sClient.Create(context.Background(), database)).To(Succeed())
+
+			database.Spec.Instance.TMInfo.QuarterlySnapshotMonth = "Apr"
+			err := k8sClient.Update(context.Background(), database)
+			Expect(err).To(HaveOccurred())
+			errMsg := err.(*errors.StatusError).ErrStatus.Message
+			Expect(errMsg).To(ContainSubstring("Quarterly snapshot month must be specified. Valid values are: "))
+		})
+
 		When("Profiles missing", func() {
 			It("Should not error out for missing Profiles: Open-source engines", func() {
 				database := createDefaultDatabase("db7")
@@ -376,6 +433,15 @@ var _ = Describe("Webhook Tests", func() {
 			Expect(errMsg).To(ContainSubstring("ClusterId field must be a valid UUID"))
 		})
 
+		It("Should not error out for ClusterName instead of ClusterId", func() {
+			clone := createDefaultClone("clone-cluster-name")
+			clone.Spec.Clone.ClusterId = ""
+			clone.Spec.Clone.ClusterName = "cluster"
+
+			err := k8sClient.Create(context.Background(), clone)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("Should check for missing CredentialSecret", func() {
 			clone := createDefaultClone("clone3")
 			clone.Spec.Clone.CredentialSecret = ""
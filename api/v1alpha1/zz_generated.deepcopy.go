@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -54,6 +55,31 @@ func (in *Clone) DeepCopy() *Clone {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DBTimeMachineInfo) DeepCopyInto(out *DBTimeMachineInfo) {
 	*out = *in
+	if in.LogCatchUpEnabled != nil {
+		in, out := &in.LogCatchUpEnabled, &out.LogCatchUpEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WeeklySnapshotEnabled != nil {
+		in, out := &in.WeeklySnapshotEnabled, &out.WeeklySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MonthlySnapshotEnabled != nil {
+		in, out := &in.MonthlySnapshotEnabled, &out.MonthlySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.QuarterlySnapshotEnabled != nil {
+		in, out := &in.QuarterlySnapshotEnabled, &out.QuarterlySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.YearlySnapshotEnabled != nil {
+		in, out := &in.YearlySnapshotEnabled, &out.YearlySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DBTimeMachineInfo.
@@ -72,7 +98,7 @@ func (in *Database) DeepCopyInto(out *Database) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Database.
@@ -138,6 +164,18 @@ func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
 		*out = new(Clone)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
@@ -153,6 +191,54 @@ func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseStatus) DeepCopyInto(out *DatabaseStatus) {
 	*out = *in
+	if in.LastCreationFailureTime != nil {
+		in, out := &in.LastCreationFailureTime, &out.LastCreationFailureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]DriftedField, len(*in))
+		copy(*out, *in)
+	}
+	if in.DriftCheckTime != nil {
+		in, out := &in.DriftCheckTime, &out.DriftCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TimeMachine != nil {
+		in, out := &in.TimeMachine, &out.TimeMachine
+		*out = new(TimeMachineStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UndefinedTags != nil {
+		in, out := &in.UndefinedTags, &out.UndefinedTags
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseStatus.
@@ -165,6 +251,21 @@ func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedField) DeepCopyInto(out *DriftedField) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedField.
+func (in *DriftedField) DeepCopy() *DriftedField {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedField)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Instance) DeepCopyInto(out *Instance) {
 	*out = *in
@@ -181,7 +282,7 @@ func (in *Instance) DeepCopyInto(out *Instance) {
 	if in.TMInfo != nil {
 		in, out := &in.TMInfo, &out.TMInfo
 		*out = new(DBTimeMachineInfo)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.AdditionalArguments != nil {
 		in, out := &in.AdditionalArguments, &out.AdditionalArguments
@@ -207,7 +308,7 @@ func (in *NDBServer) DeepCopyInto(out *NDBServer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -229,6 +330,133 @@ func (in *NDBServer) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerCatalog) DeepCopyInto(out *NDBServerCatalog) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]NDBServerCatalogCluster, len(*in))
+		copy(*out, *in)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make(map[string][]NDBServerCatalogProfile, len(*in))
+		for key, val := range *in {
+			var outVal []NDBServerCatalogProfile
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]NDBServerCatalogProfile, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.SLAs != nil {
+		in, out := &in.SLAs, &out.SLAs
+		*out = make([]NDBServerCatalogSLA, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerCatalog.
+func (in *NDBServerCatalog) DeepCopy() *NDBServerCatalog {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerCatalog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerCatalogCluster) DeepCopyInto(out *NDBServerCatalogCluster) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerCatalogCluster.
+func (in *NDBServerCatalogCluster) DeepCopy() *NDBServerCatalogCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerCatalogCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerCatalogProfile) DeepCopyInto(out *NDBServerCatalogProfile) {
+	*out = *in
+	if in.LatestVersion != nil {
+		in, out := &in.LatestVersion, &out.LatestVersion
+		*out = new(NDBServerCatalogProfileVersion)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerCatalogProfile.
+func (in *NDBServerCatalogProfile) DeepCopy() *NDBServerCatalogProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerCatalogProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerCatalogProfileVersion) DeepCopyInto(out *NDBServerCatalogProfileVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerCatalogProfileVersion.
+func (in *NDBServerCatalogProfileVersion) DeepCopy() *NDBServerCatalogProfileVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerCatalogProfileVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerCatalogSLA) DeepCopyInto(out *NDBServerCatalogSLA) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerCatalogSLA.
+func (in *NDBServerCatalogSLA) DeepCopy() *NDBServerCatalogSLA {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerCatalogSLA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerClientOptions) DeepCopyInto(out *NDBServerClientOptions) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerClientOptions.
+func (in *NDBServerClientOptions) DeepCopy() *NDBServerClientOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerClientOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NDBServerDatabaseInfo) DeepCopyInto(out *NDBServerDatabaseInfo) {
 	*out = *in
@@ -244,6 +472,21 @@ func (in *NDBServerDatabaseInfo) DeepCopy() *NDBServerDatabaseInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerInventory) DeepCopyInto(out *NDBServerInventory) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerInventory.
+func (in *NDBServerInventory) DeepCopy() *NDBServerInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NDBServerList) DeepCopyInto(out *NDBServerList) {
 	*out = *in
@@ -276,9 +519,77 @@ func (in *NDBServerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerOrphan) DeepCopyInto(out *NDBServerOrphan) {
+	*out = *in
+	if in.DBServerIds != nil {
+		in, out := &in.DBServerIds, &out.DBServerIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.DetectedAt.DeepCopyInto(&out.DetectedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerOrphan.
+func (in *NDBServerOrphan) DeepCopy() *NDBServerOrphan {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerOrphan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NDBServerOrphanPolicy) DeepCopyInto(out *NDBServerOrphanPolicy) {
+	*out = *in
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerOrphanPolicy.
+func (in *NDBServerOrphanPolicy) DeepCopy() *NDBServerOrphanPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NDBServerOrphanPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NDBServerSpec) DeepCopyInto(out *NDBServerSpec) {
 	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(NDBServerInventory)
+		**out = **in
+	}
+	if in.ClientOptions != nil {
+		in, out := &in.ClientOptions, &out.ClientOptions
+		*out = new(NDBServerClientOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OrphanPolicy != nil {
+		in, out := &in.OrphanPolicy, &out.OrphanPolicy
+		*out = new(NDBServerOrphanPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TagLabels != nil {
+		in, out := &in.TagLabels, &out.TagLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerSpec.
@@ -302,6 +613,35 @@ func (in *NDBServerStatus) DeepCopyInto(out *NDBServerStatus) {
 		}
 	}
 	out.ReconcileCounter = in.ReconcileCounter
+	if in.InventoryConfigMaps != nil {
+		in, out := &in.InventoryConfigMaps, &out.InventoryConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Catalog != nil {
+		in, out := &in.Catalog, &out.Catalog
+		*out = new(NDBServerCatalog)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvisioningQueue != nil {
+		in, out := &in.ProvisioningQueue, &out.ProvisioningQueue
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Orphans != nil {
+		in, out := &in.Orphans, &out.Orphans
+		*out = make([]NDBServerOrphan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NDBServerStatus.
@@ -363,3 +703,63 @@ func (in *ReconcileCounter) DeepCopy() *ReconcileCounter {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeMachineStatus) DeepCopyInto(out *TimeMachineStatus) {
+	*out = *in
+	if in.LogCatchUpEnabled != nil {
+		in, out := &in.LogCatchUpEnabled, &out.LogCatchUpEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WeeklySnapshotEnabled != nil {
+		in, out := &in.WeeklySnapshotEnabled, &out.WeeklySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MonthlySnapshotEnabled != nil {
+		in, out := &in.MonthlySnapshotEnabled, &out.MonthlySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.QuarterlySnapshotEnabled != nil {
+		in, out := &in.QuarterlySnapshotEnabled, &out.QuarterlySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.YearlySnapshotEnabled != nil {
+		in, out := &in.YearlySnapshotEnabled, &out.YearlySnapshotEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AppliedSpec != nil {
+		in, out := &in.AppliedSpec, &out.AppliedSpec
+		*out = new(DBTimeMachineInfo)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeMachineStatus.
+func (in *TimeMachineStatus) DeepCopy() *TimeMachineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeMachineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
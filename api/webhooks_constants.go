@@ -37,3 +37,19 @@ var AllowedQuarterlySnapshotMonths = map[string]bool{
 	"Feb": true,
 	"Mar": true,
 }
+
+// Months of the yearly snapshot with their number of days
+var AllowedYearlySnapshotMonths = map[string]int{
+	"Jan": 31,
+	"Feb": 28,
+	"Mar": 31,
+	"Apr": 30,
+	"May": 31,
+	"Jun": 30,
+	"Jul": 31,
+	"Aug": 31,
+	"Sep": 30,
+	"Oct": 31,
+	"Nov": 30,
+	"Dec": 31,
+}
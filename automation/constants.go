@@ -18,6 +18,8 @@ const (
 	NDB_SECRET_PASSWORD_ENV = "NDB_SECRET_PASSWORD"
 	NDB_SERVER_ENV          = "NDB_SERVER"
 	NX_CLUSTER_ID_ENV       = "NX_CLUSTER_ID"
+	// Set to true when NDB_SERVER is the NDB simulator, the app pods and connectivity tests are skipped
+	NDB_SIMULATOR_ENV = "NDB_SIMULATOR"
 
 	MONGO_SI_CLONING_NAME_ENV    = "MONGO_SI_CLONING_NAME"
 	MSSQL_SI_CLONING_NAME_ENV    = "MSSQL_SI_CLONING_NAME"
@@ -145,6 +145,10 @@ func (suite *MySQLCloningSingleInstanceTestSuite) TestCloningSuccess() {
 
 // Tests if app is able to connect to clone via GET request
 func (suite *MySQLCloningSingleInstanceTestSuite) TestAppConnectivity() {
+	if util.IsNDBSimulator() {
+		suite.T().Skip("The databases of the NDB simulator do not exist, skipping the app connectivity test.")
+	}
+
 	logger := util.GetLogger(suite.ctx)
 
 	resp, err := suite.tsm.GetAppResponse(suite.ctx, suite.setupTypes, suite.clientset, automation.MYSQL_SI_CLONING_LOCAL_PORT)
@@ -145,6 +145,10 @@ func (suite *MongoProvisioningSingleInstanceTestSuite) TestProvisioningSuccess()
 
 // Tests if app is able to connect to database via GET request
 func (suite *MongoProvisioningSingleInstanceTestSuite) TestAppConnectivity() {
+	if util.IsNDBSimulator() {
+		suite.T().Skip("The databases of the NDB simulator do not exist, skipping the app connectivity test.")
+	}
+
 	logger := util.GetLogger(suite.ctx)
 
 	resp, err := suite.tsm.GetAppResponse(suite.ctx, suite.setupTypes, suite.clientset, automation.MONGO_SI_PROVISONING_LOCAL_PORT)
@@ -144,6 +144,10 @@ func (suite *MSSQLProvisioningSingleInstanceTestSuite) TestProvisioningSuccess()
 
 // Tests if app is able to connect to database via GET request
 func (suite *MSSQLProvisioningSingleInstanceTestSuite) TestAppConnectivity() {
+	if util.IsNDBSimulator() {
+		suite.T().Skip("The databases of the NDB simulator do not exist, skipping the app connectivity test.")
+	}
+
 	logger := util.GetLogger(suite.ctx)
 
 	resp, err := suite.tsm.GetAppResponse(suite.ctx, suite.setupTypes, suite.clientset, automation.MSSQL_SI_PROVISONING_LOCAL_PORT)
@@ -145,6 +145,10 @@ func (suite *MySQLProvisioningSingleInstanceTestSuite) TestProvisioningSuccess()
 
 // Tests if app is able to connect to database via GET request
 func (suite *MySQLProvisioningSingleInstanceTestSuite) TestAppConnectivity() {
+	if util.IsNDBSimulator() {
+		suite.T().Skip("The databases of the NDB simulator do not exist, skipping the app connectivity test.")
+	}
+
 	logger := util.GetLogger(suite.ctx)
 
 	resp, err := suite.tsm.GetAppResponse(suite.ctx, suite.setupTypes, suite.clientset, automation.MYSQL_SI_PROVISONING_LOCAL_PORT)
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -106,6 +107,13 @@ func CheckRequiredEnv(ctx context.Context) (err error) {
 	return nil
 }
 
+// Returns true if the tests run against the NDB simulator (NDB_SIMULATOR=true).
+// The simulated databases do not exist, so no app is deployed to connect to them.
+func IsNDBSimulator() bool {
+	isSimulator, _ := strconv.ParseBool(os.Getenv(automation.NDB_SIMULATOR_ENV))
+	return isSimulator
+}
+
 // Setup kubeconfig
 func SetupKubeconfig(ctx context.Context) (config *rest.Config, err error) {
 	logger := GetLogger(ctx)
@@ -195,12 +203,17 @@ func SetupTypeTemplates(ctx context.Context) (setupTypes *SetupTypes, err error)
 		logMsg += fmt.Sprintf("DbSecret with path %s created. ", automation.DB_SECRET_PATH)
 	}
 
-	// Create appPod template from automation.APP_POD_PATH
-	appPod := &corev1.Pod{}
-	if err := CreateTypeFromPath(appPod, automation.APP_POD_PATH); err != nil {
-		errMsg += fmt.Sprintf("App Pod with path %s failed! %v. ", automation.APP_POD_PATH, err)
+	// Create appPod template from automation.APP_POD_PATH, no app is deployed against the NDB simulator
+	var appPod *corev1.Pod
+	if IsNDBSimulator() {
+		logMsg += "App Pod skipped, running against the NDB simulator. "
 	} else {
-		logMsg += fmt.Sprintf("App Pod with path %s created. ", automation.APP_POD_PATH)
+		appPod = &corev1.Pod{}
+		if err := CreateTypeFromPath(appPod, automation.APP_POD_PATH); err != nil {
+			errMsg += fmt.Sprintf("App Pod with path %s failed! %v. ", automation.APP_POD_PATH, err)
+		} else {
+			logMsg += fmt.Sprintf("App Pod with path %s created. ", automation.APP_POD_PATH)
+		}
 	}
 
 	setupTypes = &SetupTypes{
@@ -115,7 +115,7 @@ func provisionOrClone(ctx context.Context, st *SetupTypes, clientset *kubernetes
 		} else {
 			logger.Printf("Pod %s created.\n", st.AppPod.Name)
 		}
-	} else {
+	} else if !IsNDBSimulator() {
 		logger.Printf("Error while fetching app pod type %s. AppPod is nil.\n", st.DbSecret.Name)
 	}
 
@@ -262,7 +262,7 @@ func deprovisionOrDeclone(ctx context.Context, st *SetupTypes, clientset *kubern
 		} else {
 			logger.Printf("Pod %s deleted.\n", st.AppPod.Name)
 		}
-	} else {
+	} else if !IsNDBSimulator() {
 		logger.Printf("Error while fetching app pod type %s. AppPod is nil.\n", st.DbSecret.Name)
 	}
 
@@ -0,0 +1,66 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Runs the NDB API simulator as a standalone server, for running the operator and the automation tests on a laptop
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_simulator"
+)
+
+func main() {
+	var bindAddress, certFile, keyFile, databases string
+	var options ndb_simulator.Options
+	flag.StringVar(&bindAddress, "bind-address", ":8090", "The address the simulator binds to.")
+	flag.StringVar(&options.Username, "username", "", "The username accepted by the simulator, any credentials are accepted if empty.")
+	flag.StringVar(&options.Password, "password", "", "The password accepted by the simulator.")
+	flag.DurationVar(&options.OperationDuration, "operation-duration", ndb_simulator.DEFAULT_OPERATION_DURATION,
+		"The time taken by the operations (provisioning, cloning, deletion, snapshots).")
+	flag.StringVar(&options.ClusterId, "cluster-id", ndb_simulator.DEFAULT_CLUSTER_ID, "The id of the simulated cluster.")
+	flag.StringVar(&options.ClusterName, "cluster-name", ndb_simulator.DEFAULT_CLUSTER_NAME, "The name of the simulated cluster.")
+	flag.StringVar(&databases, "databases", "",
+		"Comma separated engine:name pairs of the databases existing at startup, e.g. to be used as clone sources "+
+			"(postgres_database:operator-postgres,mysql_database:operator-mysql).")
+	flag.StringVar(&certFile, "tls-cert-file", "", "The TLS certificate file, the simulator serves plain HTTP if empty.")
+	flag.StringVar(&keyFile, "tls-key-file", "", "The TLS private key file.")
+	flag.Parse()
+
+	simulator := ndb_simulator.New(options)
+	for _, entry := range strings.Split(databases, ",") {
+		if entry == "" {
+			continue
+		}
+		engine, name, found := strings.Cut(entry, ":")
+		if !found || engine == "" || name == "" {
+			log.Fatalf("Invalid database %q, expected engine:name", entry)
+		}
+		log.Printf("Added database %s (%s) with id %s", name, engine, simulator.AddDatabase(name, engine))
+	}
+
+	log.Printf("Serving the NDB API on %s%s", bindAddress, ndb_simulator.API_PREFIX)
+	var err error
+	if certFile != "" {
+		err = http.ListenAndServeTLS(bindAddress, certFile, keyFile, simulator)
+	} else {
+		err = http.ListenAndServe(bindAddress, simulator)
+	}
+	log.Fatal(err)
+}
@@ -18,12 +18,39 @@ package common
 
 // Constants are defined in lexographical order
 const (
+	// Annotation re-attempting the creation of a Database in CREATION ERROR, removed once the creation is re-attempted
+	ANNOTATION_RETRY = "ndb.nutanix.com/retry"
+
+	AUTH_METHOD_BASIC = "basic"
+	AUTH_METHOD_TOKEN = "token"
+
 	AUTH_RESPONSE_STATUS_SUCCESS = "success"
 
+	CONDITION_REASON_CATALOG_FETCHED           = "CatalogFetched"
+	CONDITION_REASON_CATALOG_FETCH_FAILED      = "CatalogFetchFailed"
+	CONDITION_REASON_DRIFT_CHECK_FAILED        = "DriftCheckFailed"
+	CONDITION_REASON_DRIFT_CHECK_NOT_SUPPORTED = "DriftCheckNotSupported"
+	CONDITION_REASON_DRIFT_DETECTED            = "DriftDetected"
+	CONDITION_REASON_DRIFT_ENFORCED            = "DriftEnforced"
+	CONDITION_REASON_IN_SYNC                   = "InSync"
+	CONDITION_REASON_ORPHANS_SCANNED           = "OrphansScanned"
+	CONDITION_REASON_ORPHAN_SCAN_FAILED        = "OrphanScanFailed"
+
+	// Condition of an NDBServer reporting the outcome of the last refresh of the catalog
+	CONDITION_TYPE_CATALOG_SYNCED = "CatalogSynced"
+	// Condition of a Database whose spec differs from the database on NDB
+	CONDITION_TYPE_DRIFTED = "Drifted"
+	// Condition of an NDBServer reporting the outcome of the last orphan scan
+	CONDITION_TYPE_ORPHANS_SCANNED = "OrphansScanned"
+
+	DATABASE_CREATION_RETRY_DEFAULT_BACKOFF_SECONDS = 60
+	DATABASE_CREATION_RETRY_MAX_BACKOFF_SECONDS     = 3600
+
 	DATABASE_CR_STATUS_CREATING       = "CREATING"
 	DATABASE_CR_STATUS_CREATION_ERROR = "CREATION ERROR"
 	DATABASE_CR_STATUS_DELETING       = "DELETING"
 	DATABASE_CR_STATUS_NOT_FOUND      = "NOT FOUND"
+	DATABASE_CR_STATUS_QUEUED         = "QUEUED"
 	DATABASE_CR_STATUS_READY          = "READY"
 
 	DATABASE_DEFAULT_PORT_MONGODB  = 27017
@@ -31,6 +58,8 @@ const (
 	DATABASE_DEFAULT_PORT_MYSQL    = 3306
 	DATABASE_DEFAULT_PORT_POSTGRES = 5432
 
+	DATABASE_DRIFT_CHECK_INTERVAL_SECONDS = 300
+
 	DATABASE_ENGINE_TYPE_GENERIC  = "Generic"
 	DATABASE_ENGINE_TYPE_MONGODB  = "mongodb_database"
 	DATABASE_ENGINE_TYPE_MSSQL    = "sqlserver_database"
@@ -48,20 +77,54 @@ const (
 	DATABASE_TYPE_POSTGRES = "postgres"
 	DATABASE_TYPES         = "mssql, mysql, postgres, mongodb"
 
+	DRIFT_POLICY_ENFORCE = "Enforce"
+	DRIFT_POLICY_REPORT  = "Report"
+
 	FINALIZER_DATABASE_SERVER = "ndb.nutanix.com/finalizerserver"
 	FINALIZER_INSTANCE        = "ndb.nutanix.com/finalizerinstance"
 
+	NDB_ALLOWED_NAMESPACES_ALL = "*"
+
 	NDB_CR_STATUS_AUTHENTICATION_ERROR = "Authentication Error"
 	NDB_CR_STATUS_CREDENTIAL_ERROR     = "Credential Error"
 	NDB_CR_STATUS_ERROR                = "Error"
 	NDB_CR_STATUS_OK                   = "Ok"
 
-	NDB_PARAM_PASSWORD       = "password"
-	NDB_PARAM_SSH_PUBLIC_KEY = "ssh_public_key"
-	NDB_PARAM_USERNAME       = "username"
+	NDB_INVENTORY_CONFIGMAP_DATA_KEY = "databases.json"
+	NDB_INVENTORY_DEFAULT_PAGE_SIZE  = 500
+	NDB_INVENTORY_LABEL_NDBSERVER    = "ndb.nutanix.com/inventory-of"
+
+	NDB_LIST_PAGE_SIZE = 500
+
+	NDB_ORPHAN_DEFAULT_GRACE_PERIOD_SECONDS = 86400
+	NDB_ORPHAN_MIN_SCANS                    = 2
+	NDB_ORPHAN_TYPE_CLONE                   = "CLONE"
+	NDB_ORPHAN_TYPE_DATABASE                = "DATABASE"
+	NDB_ORPHAN_TYPE_DATABASE_SERVER         = "DATABASE_SERVER"
 
+	NDB_PARAM_DATABASE_SERVER_TAGS = "databaseServerTags"
+	NDB_PARAM_PASSWORD             = "password"
+	NDB_PARAM_SSH_PUBLIC_KEY       = "ssh_public_key"
+	NDB_PARAM_TAGS                 = "tags"
+	NDB_PARAM_TIME_MACHINE_TAGS    = "timeMachineTags"
+	NDB_PARAM_USERNAME             = "username"
+
+	NDB_RECONCILE_CATALOG_COUNTER  = 20
 	NDB_RECONCILE_DATABASE_COUNTER = 4
 	NDB_RECONCILE_INTERVAL_SECONDS = 15
+	NDB_RECONCILE_ORPHAN_COUNTER   = 20
+
+	// Tag on the databases, clones and database servers created by the operator, its value is the id of the Kubernetes cluster
+	// of the operator (--cluster-id, the UID of the kube-system namespace by default). The tag has to be defined on NDB like
+	// ndb-operator-uid.
+	NDB_TAG_NAME_CLUSTER_ID = "ndb-operator-cluster"
+	// Tags on the databases, clones, time machines and database servers created by the operator, their values are
+	// the name and namespace of the Database custom resource. The tags are only added if they are defined on NDB.
+	NDB_TAG_NAME_CR_NAME      = "ndb-operator-name"
+	NDB_TAG_NAME_CR_NAMESPACE = "ndb-operator-namespace"
+	// Tag on the databases, clones and database servers created by the operator, its value is the UID of the Database
+	// custom resource. The tag has to be defined on NDB for the DATABASE, CLONE and DATABASE_SERVER entity types.
+	NDB_TAG_NAME_CR_UID = "ndb-operator-uid"
 
 	PROFILE_DEFAULT_OOB_SMALL_COMPUTE = "DEFAULT_OOB_SMALL_COMPUTE"
 
@@ -75,7 +138,11 @@ const (
 	PROFILE_TYPE_NETWORK                     = "Network"
 	PROFILE_TYPE_SOFTWARE                    = "Software"
 
-	PROPERTY_NAME_VM_IP = "vm_ip"
+	PROPERTY_NAME_COMPUTE_PROFILE_ID  = "compute_profile_id"
+	PROPERTY_NAME_DATABASE_SIZE       = "database_size"
+	PROPERTY_NAME_NETWORK_PROFILE_ID  = "network_profile_id"
+	PROPERTY_NAME_SOFTWARE_PROFILE_ID = "software_profile_id"
+	PROPERTY_NAME_VM_IP               = "vm_ip"
 
 	SECRET_DATA_KEY_CA_CERTIFICATE = "ca_certificate"
 	SECRET_DATA_KEY_PASSWORD       = "password"
@@ -84,6 +151,14 @@ const (
 
 	SLA_NAME_NONE = "NONE"
 
+	// Prefix of the annotations of the Database custom resources propagated as NDB tags, the rest of the key is the tag name
+	TAG_ANNOTATION_PREFIX           = "tags.ndb.nutanix.com/"
+	TAG_ENTITY_TYPE_CLONE           = "CLONE"
+	TAG_ENTITY_TYPE_DATABASE        = "DATABASE"
+	TAG_ENTITY_TYPE_DATABASE_SERVER = "DATABASE_SERVER"
+	TAG_ENTITY_TYPE_TIME_MACHINE    = "TIME_MACHINE"
+	TAG_STATUS_ENABLED              = "ENABLED"
+
 	TIMEZONE_UTC = "UTC"
 
 	TOPOLOGY_ALL      = "ALL"
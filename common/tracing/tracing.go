@@ -0,0 +1,95 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Name of the tracer and of the service in the exported spans
+	TRACER_NAME  = "github.com/nutanix-cloud-native/ndb-operator"
+	SERVICE_NAME = "ndb-operator"
+
+	// Attributes of the spans
+	ATTRIBUTE_DATABASE_NAME      = attribute.Key("ndb.database.name")
+	ATTRIBUTE_DATABASE_NAMESPACE = attribute.Key("ndb.database.namespace")
+	ATTRIBUTE_ENTITY_ID          = attribute.Key("ndb.entity.id")
+	ATTRIBUTE_NDB_SERVER_NAME    = attribute.Key("ndb.server.name")
+	ATTRIBUTE_OPERATION_ID       = attribute.Key("ndb.operation.id")
+	ATTRIBUTE_OPERATION_STATUS   = attribute.Key("ndb.operation.status")
+)
+
+// Options of the exporter of the spans
+type Options struct {
+	// host:port of the OTLP gRPC endpoint (of a collector) the spans are exported to, tracing is disabled if empty
+	Endpoint string
+	// Disables TLS for the connection to the endpoint
+	Insecure bool
+	// Fraction of the traces that are sampled, between 0 and 1
+	SamplingRatio float64
+}
+
+// Sets up the global tracer provider exporting the spans to the OTLP endpoint and the propagation
+// of the trace context in the requests sent to NDB. Returns a function flushing and stopping the
+// exporter. The spans are not recorded if tracing is not set up.
+func Setup(ctx context.Context, options Options) (shutdown func(context.Context) error, err error) {
+	shutdown = func(context.Context) error { return nil }
+	if options.Endpoint == "" {
+		return
+	}
+	exporterOptions := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(options.Endpoint)}
+	if options.Insecure {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOptions...)
+	if err != nil {
+		return
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SamplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(SERVICE_NAME))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	shutdown = tracerProvider.Shutdown
+	return
+}
+
+// Starts a span with the operator's tracer, the span must be ended by the caller
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TRACER_NAME).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// Records the error on the span and sets its status, no-op if err is nil
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// A local OTLP collector recording the names of the received spans
+type testCollector struct {
+	collectortrace.UnimplementedTraceServiceServer
+	spans chan string
+}
+
+func (c *testCollector) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	for _, resourceSpans := range req.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				c.spans <- span.Name
+			}
+		}
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+// Starts a local OTLP collector, returns its address and a function stopping it
+func startTestCollector(t *testing.T) (*testCollector, string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := &testCollector{spans: make(chan string, 10)}
+	server := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(server, collector)
+	go server.Serve(listener)
+	return collector, listener.Addr().String(), server.Stop
+}
+
+// Tests the Setup function, tests the following cases:
+//  1. Tracing is disabled without an endpoint
+//  2. Spans are exported to the endpoint
+func TestSetup(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+	ctx := context.Background()
+
+	// 1. Tracing is disabled without an endpoint
+	shutdown, err := Setup(ctx, Options{})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(ctx))
+	_, span := StartSpan(ctx, "disabled")
+	assert.False(t, span.IsRecording())
+	span.End()
+
+	// 2. Spans are exported to the endpoint
+	collector, endpoint, stop := startTestCollector(t)
+	defer stop()
+	shutdown, err = Setup(ctx, Options{Endpoint: endpoint, Insecure: true, SamplingRatio: 1})
+	assert.NoError(t, err)
+	spanCtx, span := StartSpan(ctx, "enabled", ATTRIBUTE_DATABASE_NAME.String("db"))
+	assert.True(t, span.IsRecording())
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(spanCtx, carrier)
+	assert.NotEmpty(t, carrier.Get("traceparent"))
+	span.End()
+	// Shutting down flushes the spans
+	assert.NoError(t, shutdown(ctx))
+	assert.Equal(t, "enabled", <-collector.spans)
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nutanix-cloud-native/ndb-operator/api"
 	"github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
 	"github.com/nutanix-cloud-native/ndb-operator/common"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
@@ -33,6 +34,15 @@ var (
 		"Jan": "JANUARY",
 		"Feb": "FEBRUARY",
 		"Mar": "MARCH",
+		"Apr": "APRIL",
+		"May": "MAY",
+		"Jun": "JUNE",
+		"Jul": "JULY",
+		"Aug": "AUGUST",
+		"Sep": "SEPTEMBER",
+		"Oct": "OCTOBER",
+		"Nov": "NOVEMBER",
+		"Dec": "DECEMBER",
 	}
 )
 
@@ -68,7 +78,12 @@ func (d *Database) GetDescription() string {
 	return description
 }
 
+// Returns the cluster id recorded in the status (resolved from the cluster name if specified),
+// falls back to the cluster id in the spec
 func (d *Database) GetClusterId() string {
+	if d.Status.ClusterId != "" {
+		return d.Status.ClusterId
+	}
 	if d.IsClone() {
 		return d.Spec.Clone.ClusterId
 	}
@@ -181,12 +196,24 @@ func (d *Database) GetTMScheduleForInstance() (schedule ndb_api.Schedule, err er
 	}
 	hh, mm, ss := hhmmss.Hour(), hhmmss.Minute(), hhmmss.Second()
 
+	// The quarterly snapshots start in one of the months of the first quarter
 	quarterlySnapshotStartMonth, ok := MONTH_MAP[tmInfo.QuarterlySnapshotMonth]
-	if !ok {
+	if !ok || (tmInfo.QuarterlySnapshotMonth != "" && !api.AllowedQuarterlySnapshotMonths[tmInfo.QuarterlySnapshotMonth]) {
 		err = fmt.Errorf("month %s not allowed for QuarterlySnapshotMonth", tmInfo.QuarterlySnapshotMonth)
 		return
 	}
 
+	yearlySnapshotDay, yearlySnapshotMonth := tmInfo.YearlySnapshotDay, "DECEMBER"
+	if yearlySnapshotDay == 0 {
+		yearlySnapshotDay = 31
+	}
+	if tmInfo.YearlySnapshotMonth != "" {
+		if yearlySnapshotMonth, ok = MONTH_MAP[tmInfo.YearlySnapshotMonth]; !ok {
+			err = fmt.Errorf("month %s not allowed for YearlySnapshotMonth", tmInfo.YearlySnapshotMonth)
+			return
+		}
+	}
+
 	schedule = ndb_api.Schedule{
 		SnapshotTimeOfDay: ndb_api.SnapshotTimeOfDay{
 			Hours:   hh,
@@ -195,36 +222,44 @@ func (d *Database) GetTMScheduleForInstance() (schedule ndb_api.Schedule, err er
 		},
 
 		ContinuousSchedule: ndb_api.ContinuousSchedule{
-			Enabled:           true,
+			Enabled:           isScheduleEnabled(tmInfo.LogCatchUpEnabled, true),
 			LogBackupInterval: tmInfo.LogCatchUpFrequency,
 			SnapshotsPerDay:   tmInfo.SnapshotsPerDay,
 		},
 
 		WeeklySchedule: ndb_api.WeeklySchedule{
-			Enabled:   true,
+			Enabled:   isScheduleEnabled(tmInfo.WeeklySnapshotEnabled, true),
 			DayOfWeek: tmInfo.WeeklySnapshotDay,
 		},
 
 		MonthlySchedule: ndb_api.MonthlySchedule{
-			Enabled:    true,
+			Enabled:    isScheduleEnabled(tmInfo.MonthlySnapshotEnabled, true),
 			DayOfMonth: tmInfo.MonthlySnapshotDay,
 		},
 
 		QuarterlySchedule: ndb_api.QuarterlySchedule{
-			Enabled:    true,
+			Enabled:    isScheduleEnabled(tmInfo.QuarterlySnapshotEnabled, true),
 			StartMonth: quarterlySnapshotStartMonth,
 			DayOfMonth: tmInfo.MonthlySnapshotDay,
 		},
 
 		YearlySchedule: ndb_api.YearlySchedule{
-			Enabled:    false,
-			DayOfMonth: 31,
-			Month:      "DECEMBER",
+			Enabled:    isScheduleEnabled(tmInfo.YearlySnapshotEnabled, false),
+			DayOfMonth: yearlySnapshotDay,
+			Month:      yearlySnapshotMonth,
 		},
 	}
 	return
 }
 
+// Returns the value of an optional flag enabling a schedule of the time machine, defaultValue if it is not specified
+func isScheduleEnabled(enabled *bool, defaultValue bool) bool {
+	if enabled == nil {
+		return defaultValue
+	}
+	return *enabled
+}
+
 func (d *Database) GetCloneSourceDBId() string {
 	return d.Spec.Clone.SourceDatabaseId
 }
@@ -252,6 +252,28 @@ func TestDatabase_GetClusterId(t *testing.T) {
 			t.Errorf("Database.GetClusterId() gotClusterId= %v, want %v", gotClusterId, wantClusterId)
 		}
 	})
+
+	name = "Contains ClusterId resolved from ClusterName in the status"
+	database = Database{
+		Database: v1alpha1.Database{
+			Spec: v1alpha1.DatabaseSpec{
+				Instance: &v1alpha1.Instance{
+					ClusterName: "test-cluster-name",
+				},
+			},
+			Status: v1alpha1.DatabaseStatus{
+				ClusterId: "test-resolved-cluster-id",
+			},
+		},
+	}
+	wantClusterId = "test-resolved-cluster-id"
+
+	t.Run(name, func(t *testing.T) {
+		gotClusterId := database.GetClusterId()
+		if gotClusterId != wantClusterId {
+			t.Errorf("Database.GetClusterId() gotClusterId= %v, want %v", gotClusterId, wantClusterId)
+		}
+	})
 }
 
 // Tests the GetTMScheduleForInstance() function against the following:
@@ -261,7 +283,11 @@ func TestDatabase_GetClusterId(t *testing.T) {
 // 4. DailySnapshotTime has incorrect values for seconds, returns an error
 // 5. DailySnapshotTime has incorrect values (all), returns an error
 // 6. DailySnapshotTime has incorrect format, returns an error
+// 7. Yearly snapshot is enabled and the other schedules are disabled, returns them in the schedule
+// 8. QuarterlySnapshotMonth is not a month of the first quarter, returns an error
+// 9. YearlySnapshotMonth has incorrect value, returns an error
 func TestDatabase_GetTMScheduleForInstance(t *testing.T) {
+	enabled, disabled := true, false
 
 	tests := []struct {
 		name         string
@@ -360,6 +386,56 @@ func TestDatabase_GetTMScheduleForInstance(t *testing.T) {
 			wantSchedule: ndb_api.Schedule{},
 			wantErr:      true,
 		},
+		{
+			name: "Yearly snapshot is enabled and the other schedules are disabled, returns them in the schedule",
+			database: Database{
+				Database: v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						Instance: &v1alpha1.Instance{
+							TMInfo: &v1alpha1.DBTimeMachineInfo{Name: "tm-name", Description: "tm-description", SLAName: "sla-name", DailySnapshotTime: "12:34:56", SnapshotsPerDay: 1, LogCatchUpFrequency: 30, WeeklySnapshotDay: "FRIDAY", MonthlySnapshotDay: 15, QuarterlySnapshotMonth: "Mar",
+								YearlySnapshotDay: 30, YearlySnapshotMonth: "Jun", LogCatchUpEnabled: &disabled, WeeklySnapshotEnabled: &disabled, MonthlySnapshotEnabled: &disabled, QuarterlySnapshotEnabled: &disabled, YearlySnapshotEnabled: &enabled},
+						},
+					},
+				},
+			},
+			wantSchedule: ndb_api.Schedule{
+				SnapshotTimeOfDay:  ndb_api.SnapshotTimeOfDay{Hours: 12, Minutes: 34, Seconds: 56},
+				ContinuousSchedule: ndb_api.ContinuousSchedule{Enabled: false, LogBackupInterval: 30, SnapshotsPerDay: 1},
+				WeeklySchedule:     ndb_api.WeeklySchedule{Enabled: false, DayOfWeek: "FRIDAY"},
+				MonthlySchedule:    ndb_api.MonthlySchedule{Enabled: false, DayOfMonth: 15},
+				QuarterlySchedule:  ndb_api.QuarterlySchedule{Enabled: false, StartMonth: "MARCH", DayOfMonth: 15},
+				YearlySchedule:     ndb_api.YearlySchedule{Enabled: true, DayOfMonth: 30, Month: "JUNE"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "QuarterlySnapshotMonth is not a month of the first quarter, returns an error",
+			database: Database{
+				Database: v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						Instance: &v1alpha1.Instance{
+							TMInfo: &v1alpha1.DBTimeMachineInfo{Name: "tm-name", Description: "tm-description", SLAName: "sla-name", DailySnapshotTime: "12:34:56", SnapshotsPerDay: 1, LogCatchUpFrequency: 30, WeeklySnapshotDay: "FRIDAY", MonthlySnapshotDay: 15, QuarterlySnapshotMonth: "Apr"},
+						},
+					},
+				},
+			},
+			wantSchedule: ndb_api.Schedule{},
+			wantErr:      true,
+		},
+		{
+			name: "YearlySnapshotMonth has incorrect value, returns an error",
+			database: Database{
+				Database: v1alpha1.Database{
+					Spec: v1alpha1.DatabaseSpec{
+						Instance: &v1alpha1.Instance{
+							TMInfo: &v1alpha1.DBTimeMachineInfo{Name: "tm-name", Description: "tm-description", SLAName: "sla-name", DailySnapshotTime: "12:34:56", SnapshotsPerDay: 1, LogCatchUpFrequency: 30, WeeklySnapshotDay: "FRIDAY", MonthlySnapshotDay: 15, QuarterlySnapshotMonth: "Jan", YearlySnapshotMonth: "December"},
+						},
+					},
+				},
+			},
+			wantSchedule: ndb_api.Schedule{},
+			wantErr:      true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
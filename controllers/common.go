@@ -24,6 +24,7 @@ import (
 
 	"github.com/nutanix-cloud-native/ndb-operator/common"
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
@@ -33,9 +34,20 @@ const (
 	EVENT_CREATION_STARTED   = "CreationStarted"
 	EVENT_CREATION_FAILED    = "CreationFailed"
 	EVENT_CREATION_COMPLETED = "CreationCompleted"
+	EVENT_CREATION_QUEUED    = "CreationQueued"
+	EVENT_CREATION_REJECTED  = "CreationRejected"
+	EVENT_CREATION_RESUMED   = "CreationResumed"
+	EVENT_CREATION_RETRIED   = "CreationRetried"
+
+	EVENT_CREATION_CLEANUP_STARTED = "CreationCleanupStarted"
+	EVENT_CREATION_CLEANUP_FAILED  = "CreationCleanupFailed"
+
+	EVENT_CLUSTER_RESOLUTION_FAILED = "ClusterResolutionFailed"
 
 	EVENT_INVALID_CREDENTIALS = "InvalidCredentials"
 
+	EVENT_NDB_SERVER_ACCESS_DENIED = "NDBServerAccessDenied"
+
 	EVENT_REQUEST_GENERATION         = "RequestGenerated"
 	EVENT_REQUEST_GENERATION_FAILURE = "RequestGenerationFailed"
 	EVENT_NDB_REQUEST_FAILED         = "NDBRequestFailed"
@@ -44,12 +56,22 @@ const (
 	EVENT_DEREGISTRATION_FAILED    = "DeregistrationFailed"
 	EVENT_DEREGISTRATION_COMPLETED = "DeregistrationCompleted"
 
+	EVENT_DELETION_WAITING_FOR_OPERATION = "DeletionWaitingForOperation"
+
 	EVENT_CR_CREATED              = "CustomResourceCreated"
 	EVENT_CR_DELETED              = "CustomResourceDeleted"
 	EVENT_CR_STATUS_UPDATE_FAILED = "CustomResourceStatusUpdateFailed"
 
 	EVENT_EXTERNAL_DELETE = "ExternalDeleteDetected"
 
+	EVENT_DRIFT_DETECTED = "DriftDetected"
+	EVENT_DRIFT_ENFORCED = "DriftEnforced"
+
+	EVENT_TAGS_APPLIED        = "TagsApplied"
+	EVENT_UID_TAG_NOT_APPLIED = "UIDTagNotApplied"
+
+	EVENT_TIME_MACHINE_UPDATED = "TimeMachineUpdated"
+
 	EVENT_RESOURCE_LOOKUP_ERROR = "ResourceLookupError"
 
 	EVENT_SERVICE_SETUP_FAILED  = "ServiceSetupFailed"
@@ -57,6 +79,12 @@ const (
 
 	EVENT_WAITING_FOR_NDB_RECONCILE = "WaitingForNDBReconcile"
 	EVENT_WAITING_FOR_IP_ADDRESS    = "WaitingForIPAddress"
+
+	// Paths of the fields of the spec compared with the database on NDB
+	DRIFT_FIELD_DESCRIPTION  = "spec.databaseInstance.description"
+	DRIFT_FIELD_PROFILES     = "spec.databaseInstance.profiles"
+	DRIFT_FIELD_SIZE         = "spec.databaseInstance.size"
+	DRIFT_FIELD_TIME_MACHINE = "spec.databaseInstance.timeMachine"
 )
 
 // doNotRequeue Finished processing. No need to put back on the reconcile queue.
@@ -79,6 +107,15 @@ func requeueWithTimeout(t int) (ctrl.Result, error) {
 	return ctrl.Result{RequeueAfter: time.Second * time.Duration(math.Abs(float64(t)))}, nil
 }
 
+// requeueOnUpdateErr Failed to update an entity on NDB. A conflict (the entity changed or an operation
+// is in progress on it) is retried after the reconcile interval, other errors are retried with backoff.
+func requeueOnUpdateErr(err error) (ctrl.Result, error) {
+	if ndb_api.IsConflict(err) {
+		return requeueWithTimeout(common.DATABASE_RECONCILE_INTERVAL_SECONDS)
+	}
+	return requeueOnErr(err)
+}
+
 // Returns the credentials(username, password and caCertificate) for NDB
 // Returns an error if reading the secret containing credentials fails
 func getNDBCredentialsFromSecret(ctx context.Context, k8sClient client.Client, name, namespace string) (username, password, caCert string, err error) {
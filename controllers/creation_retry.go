@@ -0,0 +1,195 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Records the failure of the creation in the status, the automatic retries back off from its time
+func setCreationFailure(databaseStatus *ndbv1alpha1.DatabaseStatus, reason string) {
+	now := metav1.Now()
+	databaseStatus.Status = common.DATABASE_CR_STATUS_CREATION_ERROR
+	databaseStatus.LastCreationFailure = reason
+	databaseStatus.LastCreationFailureTime = &now
+}
+
+// Returns the time at which the retry policy re-attempts the creation after the last failure.
+// retry is false if the policy does not re-attempt it (anymore).
+func getCreationRetryTime(retryPolicy *ndbv1alpha1.RetryPolicy, databaseStatus ndbv1alpha1.DatabaseStatus) (retryTime time.Time, retry bool) {
+	if retryPolicy == nil || databaseStatus.CreationRetries >= retryPolicy.MaxAttempts || databaseStatus.LastCreationFailureTime == nil {
+		return
+	}
+	backoffSeconds := retryPolicy.BackoffSeconds
+	if backoffSeconds <= 0 {
+		backoffSeconds = common.DATABASE_CREATION_RETRY_DEFAULT_BACKOFF_SECONDS
+	}
+	// Doubled after every re-attempt
+	for i := 0; i < databaseStatus.CreationRetries && backoffSeconds < common.DATABASE_CREATION_RETRY_MAX_BACKOFF_SECONDS; i++ {
+		backoffSeconds *= 2
+	}
+	backoffSeconds = int(math.Min(float64(backoffSeconds), common.DATABASE_CREATION_RETRY_MAX_BACKOFF_SECONDS))
+	return databaseStatus.LastCreationFailureTime.Add(time.Duration(backoffSeconds) * time.Second), true
+}
+
+// Handles a database in CREATION ERROR. The creation is re-attempted when the ndb.nutanix.com/retry annotation
+// is set or when the retry policy's backoff has elapsed, after deleting the database (and its time machine and
+// database server) partially created on NDB by the failed operation. Otherwise the request is not requeued.
+func (r *DatabaseReconciler) handleCreationError(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+	retryRequested := metav1.HasAnnotation(database.ObjectMeta, common.ANNOTATION_RETRY)
+	if !retryRequested {
+		retryTime, retry := getCreationRetryTime(database.Spec.RetryPolicy, database.Status)
+		if !retry {
+			return doNotRequeue()
+		}
+		if wait := time.Until(retryTime); wait > 0 {
+			log.Info("Waiting to re-attempt the creation of the database", "retryTime", retryTime)
+			return requeueWithTimeout(int(math.Ceil(wait.Seconds())))
+		}
+	}
+
+	// NDB does not allow a second database with the name of the failed one
+	if database.Status.Id != "" {
+		cleanedUp, err := r.cleanupFailedCreation(ctx, database, ndbClient)
+		if err != nil {
+			return requeueOnErr(err)
+		}
+		if !cleanedUp {
+			return requeueWithTimeout(common.DATABASE_RECONCILE_INTERVAL_SECONDS)
+		}
+	}
+
+	if retryRequested {
+		log.Info("Removing annotation " + common.ANNOTATION_RETRY)
+		delete(database.Annotations, common.ANNOTATION_RETRY)
+		if err := r.Update(ctx, database); err != nil {
+			log.Error(err, "Failed to remove the retry annotation")
+			return requeueOnErr(err)
+		}
+	}
+
+	// The database is provisioned again from the empty status
+	retries := database.Status.CreationRetries + 1
+	log.Info("Re-attempting the creation of the database", "attempt", retries)
+	database.Status = ndbv1alpha1.DatabaseStatus{
+		CreationRetries:         retries,
+		LastCreationFailure:     database.Status.LastCreationFailure,
+		LastCreationFailureTime: database.Status.LastCreationFailureTime,
+	}
+	if err := r.Status().Update(ctx, database); err != nil {
+		errStatement := "Failed to update status of database custom resource"
+		log.Error(err, errStatement)
+		r.recorder.Eventf(database, "Warning", EVENT_CR_STATUS_UPDATE_FAILED, "Error: %s. %s.", errStatement, err.Error())
+		return requeueOnErr(err)
+	}
+	r.recorder.Eventf(database, "Normal", EVENT_CREATION_RETRIED, "Re-attempting the creation of the database, retry %d", retries)
+	return requeue()
+}
+
+// Deletes the database or clone partially created by the failed creation operation, then its database server.
+// Each deletion is tracked through status.cleanupOperationId, cleanedUp is true once both have passed
+// or if there is nothing left to delete on NDB.
+func (r *DatabaseReconciler) cleanupFailedCreation(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient) (cleanedUp bool, err error) {
+	log := ctrllog.FromContext(ctx)
+	databaseStatus := &database.Status
+	if databaseStatus.CleanupOperationId == "" {
+		var entity *ndb_api.DatabaseResponse
+		var task *ndb_api.TaskInfoSummaryResponse
+		entity, err = getNDBDatabase(ctx, ndbClient, database)
+		if err == nil && database.Spec.IsClone {
+			task, err = ndb_api.DeprovisionClone(ctx, ndbClient, databaseStatus.Id, ndb_api.GenerateDeprovisionCloneRequest())
+		} else if err == nil {
+			task, err = ndb_api.DeprovisionDatabase(ctx, ndbClient, databaseStatus.Id, ndb_api.GenerateDeprovisionDatabaseRequest())
+		}
+		if ndb_api.IsNotFound(err) {
+			log.Info("Failed database not found on NDB, nothing to clean up", "id", databaseStatus.Id)
+			return true, nil
+		}
+		if err != nil {
+			errStatement := "Failed to delete the database partially created on NDB"
+			log.Error(err, errStatement)
+			r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+			return
+		}
+		databaseStatus.CleanupOperationId = task.OperationId
+		if len(entity.DatabaseNodes) > 0 {
+			databaseStatus.DatabaseServerId = entity.DatabaseNodes[0].DatabaseServerId
+		}
+		r.recorder.Eventf(database, "Normal", EVENT_CREATION_CLEANUP_STARTED, "Deleting the database %s partially created on NDB before re-attempting the creation", databaseStatus.Id)
+		return false, r.updateCleanupStatus(ctx, database)
+	}
+
+	cleanupOp, err := ndb_api.GetOperationById(ctx, ndbClient, databaseStatus.CleanupOperationId)
+	if err != nil {
+		message := fmt.Sprintf("NDB API to fetch operation by id failed. OperationId: %s:, error: %s", databaseStatus.CleanupOperationId, err.Error())
+		r.recorder.Event(database, "Warning", EVENT_NDB_REQUEST_FAILED, message)
+		return
+	}
+	switch ndb_api.GetOperationStatus(cleanupOp) {
+	case ndb_api.OPERATION_STATUS_FAILED:
+		// The deletion is requested again by the next reconcile
+		message := fmt.Sprintf("Cleanup operation terminated. status: %s, message: %s, operationId: %s", cleanupOp.Status, cleanupOp.Message, cleanupOp.Id)
+		log.Info(message)
+		r.recorder.Event(database, "Warning", EVENT_CREATION_CLEANUP_FAILED, message)
+		databaseStatus.CleanupOperationId = ""
+		return false, r.updateCleanupStatus(ctx, database)
+	case ndb_api.OPERATION_STATUS_PASSED:
+		if databaseStatus.DatabaseServerId == "" {
+			return true, nil
+		}
+		task, err := ndb_api.DeprovisionDatabaseServer(ctx, ndbClient, databaseStatus.DatabaseServerId, ndb_api.GenerateDeprovisionDatabaseServerRequest())
+		if ndb_api.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			errStatement := fmt.Sprintf("Failed to delete the database server %s partially created on NDB", databaseStatus.DatabaseServerId)
+			log.Error(err, errStatement)
+			r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+			return false, err
+		}
+		// The database server deletion is the last cleanup operation
+		databaseStatus.CleanupOperationId = task.OperationId
+		databaseStatus.DatabaseServerId = ""
+		return false, r.updateCleanupStatus(ctx, database)
+	default:
+		// Wait for the operation to complete
+		return false, nil
+	}
+}
+
+// Updates the status of the database with the progress of the cleanup
+func (r *DatabaseReconciler) updateCleanupStatus(ctx context.Context, database *ndbv1alpha1.Database) (err error) {
+	log := ctrllog.FromContext(ctx)
+	if err = r.Status().Update(ctx, database); err != nil {
+		errStatement := "Failed to update status of database custom resource"
+		log.Error(err, errStatement)
+		r.recorder.Eventf(database, "Warning", EVENT_CR_STATUS_UPDATE_FAILED, "Error: %s. %s.", errStatement, err.Error())
+	}
+	return
+}
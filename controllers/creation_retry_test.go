@@ -0,0 +1,85 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+)
+
+// Tests the getCreationRetryTime function, tests the following cases:
+// 1. No retry policy, the creation is not re-attempted
+// 2. The attempts of the retry policy are used up
+// 3. The first retry waits for the default backoff
+// 4. The backoff is doubled after every retry
+// 5. The backoff is capped at an hour
+func TestGetCreationRetryTime(t *testing.T) {
+	failureTime := metav1.NewTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name          string
+		retryPolicy   *ndbv1alpha1.RetryPolicy
+		status        ndbv1alpha1.DatabaseStatus
+		wantRetry     bool
+		wantRetryTime time.Time
+	}{
+		{
+			name:   "no retry policy",
+			status: ndbv1alpha1.DatabaseStatus{LastCreationFailureTime: &failureTime},
+		},
+		{
+			name:        "attempts used up",
+			retryPolicy: &ndbv1alpha1.RetryPolicy{MaxAttempts: 2},
+			status:      ndbv1alpha1.DatabaseStatus{CreationRetries: 2, LastCreationFailureTime: &failureTime},
+		},
+		{
+			name:          "default backoff",
+			retryPolicy:   &ndbv1alpha1.RetryPolicy{MaxAttempts: 2},
+			status:        ndbv1alpha1.DatabaseStatus{LastCreationFailureTime: &failureTime},
+			wantRetry:     true,
+			wantRetryTime: failureTime.Add(time.Minute),
+		},
+		{
+			name:          "doubled backoff",
+			retryPolicy:   &ndbv1alpha1.RetryPolicy{MaxAttempts: 5, BackoffSeconds: 10},
+			status:        ndbv1alpha1.DatabaseStatus{CreationRetries: 2, LastCreationFailureTime: &failureTime},
+			wantRetry:     true,
+			wantRetryTime: failureTime.Add(40 * time.Second),
+		},
+		{
+			name:          "capped backoff",
+			retryPolicy:   &ndbv1alpha1.RetryPolicy{MaxAttempts: 100, BackoffSeconds: 600},
+			status:        ndbv1alpha1.DatabaseStatus{CreationRetries: 50, LastCreationFailureTime: &failureTime},
+			wantRetry:     true,
+			wantRetryTime: failureTime.Add(time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryTime, retry := getCreationRetryTime(tt.retryPolicy, tt.status)
+			if retry != tt.wantRetry {
+				t.Fatalf("getCreationRetryTime() retry = %v, want %v", retry, tt.wantRetry)
+			}
+			if retry && !retryTime.Equal(tt.wantRetryTime) {
+				t.Errorf("getCreationRetryTime() retryTime = %v, want %v", retryTime, tt.wantRetryTime)
+			}
+		})
+	}
+}
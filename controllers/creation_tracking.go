@@ -0,0 +1,154 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Difference between the clocks of NDB and the Kubernetes API server tolerated when
+// matching the databases on NDB that are not tagged with the UID of a Database custom resource
+const CREATION_LOOKUP_CLOCK_SKEW = time.Minute
+
+// Returns the name of the database or clone on NDB and the entity type of its tags
+func getNDBEntity(database *ndbv1alpha1.Database) (name, entityType string) {
+	if database.Spec.IsClone {
+		return database.Spec.Clone.Name, common.TAG_ENTITY_TYPE_CLONE
+	}
+	return database.Spec.Instance.Name, common.TAG_ENTITY_TYPE_DATABASE
+}
+
+// Fetches the database or clone of the Database custom resource from NDB by the id in its status
+func getNDBDatabase(ctx context.Context, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database) (*ndb_api.DatabaseResponse, error) {
+	if database.Spec.IsClone {
+		return ndb_api.GetCloneById(ctx, ndbClient, database.Status.Id)
+	}
+	return ndb_api.GetDatabaseById(ctx, ndbClient, database.Status.Id)
+}
+
+// Returns the tags of the database or clone, of its time machine and of its database server for the creation request (see getDesiredTags),
+// including the UID of the Database custom resource and the id of the Kubernetes cluster of the operator (if known).
+// The tags not defined on NDB for the entity types are skipped, the request is not tagged if they cannot be fetched.
+// A Warning event is recorded if the database or clone is not tagged with the UID, an untagged database is only
+// adopted by a later reconcile if NDB lists its creation operation (see findCreatedDatabase).
+func (r *DatabaseReconciler) getCreationTags(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, database *ndbv1alpha1.Database, ndbServer *ndbv1alpha1.NDBServer) (tags, timeMachineTags, dbServerTags []ndb_api.Tag) {
+	log := ctrllog.FromContext(ctx)
+	desired := getDesiredTags(database, ndbServer, r.ClusterId)
+	_, entityType := getNDBEntity(database)
+	tags, err := resolveTags(ctx, ndbClient, entityType, desired)
+	if err != nil {
+		log.Info("Could not fetch the tags defined on NDB, the request is not tagged", "error", err.Error())
+		r.recorder.Eventf(database, "Warning", EVENT_UID_TAG_NOT_APPLIED, "The %s is not tagged with the UID of the Database, the tags defined on NDB could not be fetched: %s", entityType, err.Error())
+		return
+	}
+	if _, found := ndb_api.GetTagValue(tags, common.NDB_TAG_NAME_CR_UID); !found {
+		r.recorder.Eventf(database, "Warning", EVENT_UID_TAG_NOT_APPLIED, "The %s is not tagged with the UID of the Database, the %s tag is not defined on NDB for the %s entity", entityType, common.NDB_TAG_NAME_CR_UID, entityType)
+	}
+	timeMachineTags, err = resolveTags(ctx, ndbClient, common.TAG_ENTITY_TYPE_TIME_MACHINE, desired)
+	if err != nil {
+		log.Info("Could not fetch the tags defined on NDB, the time machine is not tagged", "error", err.Error())
+	}
+	dbServerTags, err = resolveTags(ctx, ndbClient, common.TAG_ENTITY_TYPE_DATABASE_SERVER, desired)
+	if err != nil {
+		log.Info("Could not fetch the tags defined on NDB, the database server is not tagged", "error", err.Error())
+	}
+	return
+}
+
+// Returns the database or clone created on NDB for the Database custom resource by an earlier reconcile
+// whose status update was lost, with the id of its creation operation (empty if NDB does not list it).
+// An untagged database is only adopted if NDB lists its creation operation.
+// Returns nil if there is none, the database then has to be created.
+func findCreatedDatabase(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, database *ndbv1alpha1.Database) (created *ndb_api.DatabaseResponse, operationId string, err error) {
+	log := ctrllog.FromContext(ctx)
+	name, _ := getNDBEntity(database)
+	listOptions := ndb_api.ListOptions{ValueType: "name", Value: name}
+	var candidates []ndb_api.DatabaseResponse
+	if database.Spec.IsClone {
+		candidates, err = ndb_api.ListClones(ctx, ndbClient, listOptions)
+	} else {
+		candidates, err = ndb_api.ListDatabases(ctx, ndbClient, listOptions)
+	}
+	if err != nil {
+		return
+	}
+	for i := range candidates {
+		// The name filter is applied again in case the NDB version does not support filtering
+		if candidates[i].Name != name || !isCreatedFor(candidates[i], database) {
+			continue
+		}
+		if operationId, err = getCreationOperationId(ctx, ndbClient, candidates[i].Id); err != nil {
+			return
+		}
+		if _, tagged := ndb_api.GetTagValue(candidates[i].Tags, common.NDB_TAG_NAME_CR_UID); !tagged && operationId == "" {
+			// Without the UID tag, only the provisioning or cloning operation ties the database to the Database
+			log.Info("Skipping the untagged database with the name of the Database, NDB does not list its creation operation", "id", candidates[i].Id)
+			continue
+		}
+		created = &candidates[i]
+		break
+	}
+	if created == nil {
+		return
+	}
+	log.Info("Found the database created on NDB for the Database", "id", created.Id, "status", created.Status, "operation id", operationId)
+	return
+}
+
+// Returns true if the database or clone on NDB may have been created for the Database custom resource: it is tagged
+// with the UID of the custom resource or, if it is not tagged, it was created after the custom resource.
+// Untagged databases created before the custom resource belong to someone else and are never adopted.
+func isCreatedFor(entity ndb_api.DatabaseResponse, database *ndbv1alpha1.Database) bool {
+	if uid, found := ndb_api.GetTagValue(entity.Tags, common.NDB_TAG_NAME_CR_UID); found {
+		return uid == string(database.UID)
+	}
+	dateCreated, err := time.ParseInLocation(ndb_api.OPERATION_TIME_LAYOUT, entity.DateCreated, time.UTC)
+	if err != nil {
+		return false
+	}
+	return !dateCreated.Before(database.CreationTimestamp.Add(-CREATION_LOOKUP_CLOCK_SKEW))
+}
+
+// Returns the id of the creation operation of the entity, the earliest of its operations on NDB.
+// Returns an empty id if NDB does not list any operation for the entity.
+func getCreationOperationId(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, entityId string) (operationId string, err error) {
+	operations, err := ndb_api.ListOperations(ctx, ndbClient, ndb_api.ListOptions{Filters: map[string]string{"entity-id": entityId}})
+	if err != nil {
+		return
+	}
+	var earliest time.Time
+	for i := range operations {
+		if operations[i].EntityId != "" && operations[i].EntityId != entityId {
+			continue
+		}
+		startTime, err := ndb_api.GetOperationStartTime(&operations[i])
+		if err != nil {
+			continue
+		}
+		if operationId == "" || startTime.Before(earliest) {
+			operationId, earliest = operations[i].Id, startTime
+		}
+	}
+	return
+}
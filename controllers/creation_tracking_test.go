@@ -0,0 +1,167 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/controller_adapters"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_simulator"
+)
+
+// Tests the findCreatedDatabase function, tests the following cases:
+//  1. No database with the name on NDB
+//  2. A database tagged with the UID of the Database is found with its creation operation
+//  3. A database tagged with the UID of another Database is not adopted
+//  4. An untagged database created after the Database without a creation operation is not adopted
+//  5. An untagged database created before the Database is not adopted
+//  6. An untagged database provisioned after the Database is found with its creation operation
+func TestFindCreatedDatabase(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	simulator := ndb_simulator.New(ndb_simulator.Options{Now: func() time.Time { return now }})
+	server := httptest.NewServer(simulator)
+	defer server.Close()
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL+ndb_simulator.API_PREFIX, "", true)
+
+	newDatabase := func(name, uid string, created time.Time) *ndbv1alpha1.Database {
+		database := &ndbv1alpha1.Database{
+			ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid), CreationTimestamp: metav1.NewTime(created)},
+			Spec: ndbv1alpha1.DatabaseSpec{
+				Instance: &ndbv1alpha1.Instance{
+					Name:      name,
+					ClusterId: ndb_simulator.DEFAULT_CLUSTER_ID,
+					Size:      10,
+					Type:      common.DATABASE_TYPE_POSTGRES,
+				},
+			},
+		}
+		database.Default()
+		return database
+	}
+	provision := func(database *ndbv1alpha1.Database) string {
+		// The tags of the operator defined on the simulator are added to the database, its time machine and its database server
+		reconciler := &DatabaseReconciler{ClusterId: "cluster-1", recorder: record.NewFakeRecorder(10)}
+		tags, timeMachineTags, dbServerTags := reconciler.getCreationTags(ctx, ndbClient, database, &ndbv1alpha1.NDBServer{})
+		reqData := map[string]interface{}{
+			common.NDB_PARAM_PASSWORD:             "password",
+			common.NDB_PARAM_SSH_PUBLIC_KEY:       "ssh-rsa AAAA",
+			common.NDB_PARAM_TAGS:                 tags,
+			common.NDB_PARAM_TIME_MACHINE_TAGS:    timeMachineTags,
+			common.NDB_PARAM_DATABASE_SERVER_TAGS: dbServerTags,
+		}
+		req, err := ndb_api.GenerateProvisioningRequest(ctx, ndbClient, &controller_adapters.Database{Database: *database}, reqData)
+		assert.NoError(t, err)
+		for _, tags := range [][]ndb_api.Tag{req.Tags, req.TimeMachineInfo.Tags, req.Nodes[0].Tags} {
+			assert.Len(t, tags, 4)
+			uid, _ := ndb_api.GetTagValue(tags, common.NDB_TAG_NAME_CR_UID)
+			assert.Equal(t, string(database.UID), uid)
+			clusterId, _ := ndb_api.GetTagValue(tags, common.NDB_TAG_NAME_CLUSTER_ID)
+			assert.Equal(t, "cluster-1", clusterId)
+		}
+		task, err := ndb_api.ProvisionDatabase(ctx, ndbClient, req)
+		assert.NoError(t, err)
+		return task.OperationId
+	}
+
+	// 1. No database with the name on NDB
+	created, _, err := findCreatedDatabase(ctx, ndbClient, newDatabase("missing", "1", now))
+	assert.NoError(t, err)
+	assert.Nil(t, created)
+
+	// 2. A database tagged with the UID of the Database is found with its creation operation
+	tagged := newDatabase("tagged", "2", now.Add(-time.Hour))
+	operationId := provision(tagged)
+	created, creationOperationId, err := findCreatedDatabase(ctx, ndbClient, tagged)
+	assert.NoError(t, err)
+	assert.Equal(t, "tagged", created.Name)
+	assert.Equal(t, operationId, creationOperationId)
+
+	// 3. A database tagged with the UID of another Database is not adopted
+	created, _, err = findCreatedDatabase(ctx, ndbClient, newDatabase("tagged", "3", now.Add(-time.Hour)))
+	assert.NoError(t, err)
+	assert.Nil(t, created)
+
+	// 4. An untagged database created after the Database without a creation operation is not adopted
+	simulator.AddDatabase("untagged", common.DATABASE_ENGINE_TYPE_POSTGRES)
+	created, _, err = findCreatedDatabase(ctx, ndbClient, newDatabase("untagged", "4", now.Add(-time.Minute)))
+	assert.NoError(t, err)
+	assert.Nil(t, created)
+
+	// 5. An untagged database created before the Database is not adopted
+	created, _, err = findCreatedDatabase(ctx, ndbClient, newDatabase("untagged", "5", now.Add(time.Hour)))
+	assert.NoError(t, err)
+	assert.Nil(t, created)
+
+	// 6. An untagged database provisioned after the Database is found with its creation operation
+	provisioned := newDatabase("provisioned", "6", now.Add(-time.Minute))
+	operationId = provision(provisioned)
+	created, _, err = findCreatedDatabase(ctx, ndbClient, provisioned)
+	assert.NoError(t, err)
+	assert.True(t, simulator.ModifyDatabase(created.Id, func(db *ndb_api.DatabaseResponse, _ *ndb_api.TimeMachineResponse) {
+		db.Tags = nil
+	}))
+	created, creationOperationId, err = findCreatedDatabase(ctx, ndbClient, provisioned)
+	assert.NoError(t, err)
+	assert.Equal(t, "provisioned", created.Name)
+	assert.Equal(t, operationId, creationOperationId)
+}
+
+// Tests the getCreationTags function, tests the following cases:
+//  1. The database is tagged with the UID of the Database, no event is recorded
+//  2. A Warning event is recorded if the tags defined on NDB cannot be fetched
+func TestGetCreationTags(t *testing.T) {
+	ctx := context.Background()
+	simulator := ndb_simulator.New(ndb_simulator.Options{})
+	server := httptest.NewServer(simulator)
+	defer server.Close()
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL+ndb_simulator.API_PREFIX, "", true)
+	database := &ndbv1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns", UID: types.UID("uid-1")},
+		Spec:       ndbv1alpha1.DatabaseSpec{Instance: &ndbv1alpha1.Instance{Name: "db"}},
+	}
+
+	// 1. The database is tagged with the UID of the Database, no event is recorded
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &DatabaseReconciler{ClusterId: "cluster-1", recorder: recorder}
+	tags, _, _ := reconciler.getCreationTags(ctx, ndbClient, database, &ndbv1alpha1.NDBServer{})
+	uid, _ := ndb_api.GetTagValue(tags, common.NDB_TAG_NAME_CR_UID)
+	assert.Equal(t, "uid-1", uid)
+	assert.Empty(t, recorder.Events)
+
+	// 2. A Warning event is recorded if the tags defined on NDB cannot be fetched
+	simulator.AddFault(ndb_simulator.Fault{Method: http.MethodGet, Path: "tags", StatusCode: http.StatusBadRequest})
+	tags, _, _ = reconciler.getCreationTags(ctx, ndbClient, database, &ndbv1alpha1.NDBServer{})
+	assert.Empty(t, tags)
+	if assert.Len(t, recorder.Events, 1) {
+		assert.True(t, strings.Contains(<-recorder.Events, EVENT_UID_TAG_NOT_APPLIED))
+	}
+}
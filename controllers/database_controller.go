@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,8 +35,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
-	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
 )
 
 // Annotation for generating RBAC role for writing Events
@@ -43,6 +45,7 @@ import (
 // +kubebuilder:rbac:groups="core",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="core",resources=endpoints,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="core",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="core",resources=namespaces,verbs=get
 // +kubebuilder:rbac:groups=ndb.nutanix.com,resources=databases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ndb.nutanix.com,resources=databases/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ndb.nutanix.com,resources=databases/finalizers,verbs=update
@@ -50,17 +53,31 @@ import (
 // DatabaseReconciler reconciles a Database object
 type DatabaseReconciler struct {
 	client.Client
-	Scheme   *runtime.Scheme
-	recorder record.EventRecorder
+	Scheme *runtime.Scheme
+	// Shared with the NDBServer controller
+	NDBClients *NDBClientManager
+	// Id of the Kubernetes cluster, tagged on the databases and clones created on NDB
+	ClusterId string
+	// Reads the Databases from the API server rather than the cache to count the provisioning slots
+	APIReader client.Reader
+	recorder  record.EventRecorder
 }
 
 // The Reconcile method is where the controller logic resides.
-func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	log := ctrllog.FromContext(ctx)
 	log.Info("<==============================Reconcile Started=============================>")
+	ctx, span := tracing.StartSpan(ctx, "Database.Reconcile",
+		tracing.ATTRIBUTE_DATABASE_NAME.String(req.Name),
+		tracing.ATTRIBUTE_DATABASE_NAMESPACE.String(req.Namespace),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 	// Fetch the database resource from the namespace
 	database := &ndbv1alpha1.Database{}
-	err := r.Get(ctx, req.NamespacedName, database)
+	err = r.Get(ctx, req.NamespacedName, database)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -74,11 +91,14 @@ func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	log.Info("Database CR Status: " + util.ToString(database.Status))
+	setDatabaseSpanAttributes(ctx, database.Status)
 
-	// Fetch the NDBServer resource from the namespace
+	// Fetch the NDBServer resource from the namespace referenced by the database
 	ndbServer := &ndbv1alpha1.NDBServer{}
-	ndbNamespacedName := req.NamespacedName
-	ndbNamespacedName.Name = database.Spec.NDBRef
+	ndbNamespacedName := types.NamespacedName{
+		Namespace: database.GetNDBServerNamespace(),
+		Name:      database.Spec.NDBRef,
+	}
 	err = r.Get(ctx, ndbNamespacedName, ndbServer)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -92,16 +112,19 @@ func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return requeueOnErr(err)
 	}
 
-	NDBInfo := ndbServer.Spec
-	username, password, caCert, err := getNDBCredentialsFromSecret(ctx, r.Client, NDBInfo.CredentialSecret, req.Namespace)
+	// The NDBServer must allow databases from this namespace to use its credentials
+	if !ndbServer.IsNamespaceAllowed(database.Namespace) {
+		log.Info("NDBServer does not allow databases from this namespace", "NDBServer", ndbNamespacedName, "Namespace", database.Namespace)
+		r.recorder.Eventf(database, "Warning", EVENT_NDB_SERVER_ACCESS_DENIED, "NDBServer %s does not allow databases from namespace %s", ndbNamespacedName, database.Namespace)
+		return requeueWithTimeout(common.DATABASE_RECONCILE_INTERVAL_SECONDS)
+	}
+
+	// The client shared with the NDBServer controller, the credentials are read from the namespace of the NDBServer
+	ndbClient, err := r.NDBClients.GetNDBClient(ctx, ndbServer)
 	if err != nil {
 		r.recorder.Eventf(database, "Warning", EVENT_INVALID_CREDENTIALS, "Error: %s", err.Error())
 		return requeueOnErr(err)
 	}
-	if caCert == "" {
-		log.Info("Ca-cert not found, falling back to host's HTTPs certs.")
-	}
-	ndbClient := ndb_client.NewNDBClient(username, password, NDBInfo.Server, caCert, NDBInfo.SkipCertificateVerification)
 
 	return r.handleSync(ctx, database, ndbClient, req, ndbServer)
 }
@@ -112,7 +135,9 @@ func (r *DatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = mgr.GetEventRecorderFor("database-controller")
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ndbv1alpha1.Database{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		// Annotation changes re-attempt the creation of databases in CREATION ERROR,
+		// label and annotation changes update the tags on NDB
+		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{})).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Endpoints{}).
 		Complete(r)
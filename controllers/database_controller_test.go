@@ -0,0 +1,909 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_simulator"
+)
+
+const (
+	TEST_NDB_SERVER_NAME      = "ndb"
+	TEST_NDB_SECRET_NAME      = "ndb-secret"
+	TEST_DATABASE_SECRET_NAME = "db-secret"
+	TEST_NDB_USERNAME         = "admin"
+	TEST_NDB_PASSWORD         = "password"
+	TEST_OPERATION_DURATION   = time.Minute
+	TEST_CLUSTER_ID           = "test-cluster"
+)
+
+// Clock controlling the progress of the operations of the NDB simulator
+type simulatorClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *simulatorClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *simulatorClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Runs the Database and NDBServer reconcilers against the API server of the test environment and the NDB simulator.
+// The reconciles are driven by the specs (instead of a manager) to step through the state machine deterministically,
+// and the operations on NDB complete when the clock of the simulator is advanced.
+var _ = Describe("Database controller", func() {
+	var (
+		ctx                 context.Context
+		namespace           string
+		simulator           *ndb_simulator.Simulator
+		clock               *simulatorClock
+		server              *httptest.Server
+		recorder            *record.FakeRecorder
+		databaseReconciler  *DatabaseReconciler
+		ndbServerReconciler *NDBServerReconciler
+	)
+
+	createSecret := func(name string, data map[string]string) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       make(map[string][]byte),
+		}
+		for key, value := range data {
+			secret.Data[key] = []byte(value)
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+	}
+
+	createNDBServer := func(credentialSecret string) {
+		ndbServer := &ndbv1alpha1.NDBServer{
+			ObjectMeta: metav1.ObjectMeta{Name: TEST_NDB_SERVER_NAME, Namespace: namespace},
+			Spec: ndbv1alpha1.NDBServerSpec{
+				Server:           server.URL + ndb_simulator.API_PREFIX,
+				CredentialSecret: credentialSecret,
+			},
+		}
+		Expect(k8sClient.Create(ctx, ndbServer)).To(Succeed())
+	}
+
+	// Creates a postgres Database with the defaults applied by the mutating webhook
+	createDatabase := func(name string) {
+		database := &ndbv1alpha1.Database{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: ndbv1alpha1.DatabaseSpec{
+				NDBRef: TEST_NDB_SERVER_NAME,
+				Instance: &ndbv1alpha1.Instance{
+					Name:             name,
+					ClusterId:        ndb_simulator.DEFAULT_CLUSTER_ID,
+					CredentialSecret: TEST_DATABASE_SECRET_NAME,
+					Size:             10,
+					Type:             common.DATABASE_TYPE_POSTGRES,
+				},
+			},
+		}
+		database.Default()
+		Expect(k8sClient.Create(ctx, database)).To(Succeed())
+	}
+
+	getDatabase := func(name string) *ndbv1alpha1.Database {
+		database := &ndbv1alpha1.Database{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, database)).To(Succeed())
+		return database
+	}
+
+	reconcileDatabase := func(name string) (ctrl.Result, error) {
+		return databaseReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	}
+
+	// Reconciles the NDBServer until the databases are fetched from NDB (every NDB_RECONCILE_DATABASE_COUNTER reconciles)
+	syncNDBServer := func() *ndbv1alpha1.NDBServer {
+		for i := 0; i < common.NDB_RECONCILE_DATABASE_COUNTER; i++ {
+			_, err := ndbServerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: TEST_NDB_SERVER_NAME}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		ndbServer := &ndbv1alpha1.NDBServer{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: TEST_NDB_SERVER_NAME}, ndbServer)).To(Succeed())
+		return ndbServer
+	}
+
+	// Reconciles the NDBServer until NDB has been scanned for orphans once (every NDB_RECONCILE_ORPHAN_COUNTER reconciles)
+	scanNDBServerOrphans := func() *ndbv1alpha1.NDBServer {
+		for i := 0; i < common.NDB_RECONCILE_ORPHAN_COUNTER; i++ {
+			_, err := ndbServerReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: TEST_NDB_SERVER_NAME}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		ndbServer := &ndbv1alpha1.NDBServer{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: TEST_NDB_SERVER_NAME}, ndbServer)).To(Succeed())
+		return ndbServer
+	}
+
+	// Returns true if an event with the reason was recorded, the events recorded before it are discarded
+	hasEvent := func(reason string) bool {
+		for {
+			select {
+			case event := <-recorder.Events:
+				if containsReason(event, reason) {
+					return true
+				}
+			default:
+				return false
+			}
+		}
+	}
+
+	// Provisions the database and reconciles it until it is READY with both finalizers and its Service.
+	// The NDBServer fetches the databases from NDB once the creation operation passed, the Database
+	// controller reads the IP address and database server of the database from the NDBServer status.
+	provisionDatabase := func(name string) *ndbv1alpha1.Database {
+		createDatabase(name)
+		_, err := reconcileDatabase(name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getDatabase(name).Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATING))
+
+		clock.Advance(TEST_OPERATION_DURATION)
+		_, err = reconcileDatabase(name)
+		Expect(err).NotTo(HaveOccurred())
+		syncNDBServer()
+		for i := 0; i < 3; i++ {
+			_, err = reconcileDatabase(name)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		database := getDatabase(name)
+		Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_READY))
+		Expect(database.Finalizers).To(ConsistOf(common.FINALIZER_INSTANCE, common.FINALIZER_DATABASE_SERVER))
+		return database
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clock = &simulatorClock{now: time.Now()}
+		simulator = ndb_simulator.New(ndb_simulator.Options{
+			Username:          TEST_NDB_USERNAME,
+			Password:          TEST_NDB_PASSWORD,
+			OperationDuration: TEST_OPERATION_DURATION,
+			Now:               clock.Now,
+		})
+		server = httptest.NewServer(simulator)
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "database-controller-"}}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespace = ns.Name
+
+		recorder = record.NewFakeRecorder(1000)
+		ndbClients := NewNDBClientManager(k8sClient)
+		databaseReconciler = &DatabaseReconciler{Client: k8sClient, Scheme: scheme.Scheme, NDBClients: ndbClients, ClusterId: TEST_CLUSTER_ID, APIReader: k8sClient, recorder: recorder}
+		ndbServerReconciler = &NDBServerReconciler{Client: k8sClient, Scheme: scheme.Scheme, NDBClients: ndbClients, ClusterId: TEST_CLUSTER_ID, APIReader: k8sClient, now: clock.Now}
+
+		createSecret(TEST_DATABASE_SECRET_NAME, map[string]string{
+			common.SECRET_DATA_KEY_PASSWORD:       "db-password",
+			common.SECRET_DATA_KEY_SSH_PUBLIC_KEY: "ssh-rsa AAAA",
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("with valid credentials", func() {
+		BeforeEach(func() {
+			createSecret(TEST_NDB_SECRET_NAME, map[string]string{
+				common.SECRET_DATA_KEY_USERNAME: TEST_NDB_USERNAME,
+				common.SECRET_DATA_KEY_PASSWORD: TEST_NDB_PASSWORD,
+			})
+			createNDBServer(TEST_NDB_SECRET_NAME)
+		})
+
+		It("provisions, connects and deletes a database through both finalizers", func() {
+			createDatabase("lifecycle")
+			_, err := reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			database := getDatabase("lifecycle")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATING))
+			Expect(database.Status.Id).NotTo(BeEmpty())
+			Expect(database.Status.CreationOperationId).NotTo(BeEmpty())
+			Expect(hasEvent(EVENT_CREATION_STARTED)).To(BeTrue())
+
+			By("staying CREATING while the operation is running")
+			clock.Advance(TEST_OPERATION_DURATION / 2)
+			_, err = reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDatabase("lifecycle").Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATING))
+
+			By("becoming READY once the operation passed")
+			clock.Advance(TEST_OPERATION_DURATION / 2)
+			_, err = reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDatabase("lifecycle").Status.Status).To(Equal(common.DATABASE_CR_STATUS_READY))
+			Expect(hasEvent(EVENT_CREATION_COMPLETED)).To(BeTrue())
+
+			By("syncing the IP address through the NDBServer and adding the finalizers")
+			ndbServer := syncNDBServer()
+			Expect(ndbServer.Status.Status).To(Equal(common.NDB_CR_STATUS_OK))
+			Expect(ndbServer.Status.Databases).To(HaveKey(database.Status.Id))
+			for i := 0; i < 2; i++ {
+				_, err = reconcileDatabase("lifecycle")
+				Expect(err).NotTo(HaveOccurred())
+			}
+			database = getDatabase("lifecycle")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_READY))
+			Expect(database.Status.IPAddress).NotTo(BeEmpty())
+			Expect(database.Status.DatabaseServerId).NotTo(BeEmpty())
+			Expect(database.Finalizers).To(ConsistOf(common.FINALIZER_INSTANCE, common.FINALIZER_DATABASE_SERVER))
+
+			By("setting up the Service and Endpoints of the database")
+			_, err = reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			serviceName := types.NamespacedName{Namespace: namespace, Name: "lifecycle-svc"}
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, serviceName, service)).To(Succeed())
+			Expect(metav1.IsControlledBy(service, database)).To(BeTrue())
+			endpoints := &corev1.Endpoints{}
+			Expect(k8sClient.Get(ctx, serviceName, endpoints)).To(Succeed())
+			Expect(endpoints.Subsets[0].Addresses[0].IP).To(Equal(database.Status.IPAddress))
+
+			By("deregistering the database and removing the instance finalizer")
+			Expect(k8sClient.Delete(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("lifecycle")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_DELETING))
+			Expect(database.Status.DeregistrationOperationId).NotTo(BeEmpty())
+			Expect(controllerutil.ContainsFinalizer(database, common.FINALIZER_INSTANCE)).To(BeTrue())
+
+			clock.Advance(TEST_OPERATION_DURATION)
+			_, err = reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("lifecycle")
+			Expect(controllerutil.ContainsFinalizer(database, common.FINALIZER_INSTANCE)).To(BeFalse())
+			_, found := simulator.GetDatabase(database.Status.Id)
+			Expect(found).To(BeFalse())
+
+			By("deleting the database server and removing the server finalizer")
+			_, err = reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "lifecycle"}, &ndbv1alpha1.Database{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			clock.Advance(TEST_OPERATION_DURATION)
+			Expect(simulator.HasDatabaseServer(database.Status.DatabaseServerId)).To(BeFalse())
+
+			result, err := reconcileDatabase("lifecycle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+		})
+
+		It("resumes tracking the database when the status update after its creation was lost", func() {
+			createDatabase("resumed")
+			_, err := reconcileDatabase("resumed")
+			Expect(err).NotTo(HaveOccurred())
+			database := getDatabase("resumed")
+			id, creationOperationId := database.Status.Id, database.Status.CreationOperationId
+
+			database.Status = ndbv1alpha1.DatabaseStatus{}
+			Expect(k8sClient.Status().Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("resumed")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("resumed")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATING))
+			Expect(database.Status.Id).To(Equal(id))
+			Expect(database.Status.CreationOperationId).To(Equal(creationOperationId))
+			Expect(hasEvent(EVENT_CREATION_RESUMED)).To(BeTrue())
+
+			ndbClient := ndb_client.NewNDBClient(TEST_NDB_USERNAME, TEST_NDB_PASSWORD, server.URL+ndb_simulator.API_PREFIX, "", true)
+			databases, err := ndb_api.GetAllDatabases(ctx, ndbClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(databases).To(HaveLen(1))
+		})
+
+		It("marks the database CREATION ERROR when the creation operation fails", func() {
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodPost, Path: "databases/provision", FailOperation: true})
+			createDatabase("failed")
+			_, err := reconcileDatabase("failed")
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.Advance(TEST_OPERATION_DURATION)
+			result, err := reconcileDatabase("failed")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+			database := getDatabase("failed")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATION_ERROR))
+			Expect(database.Finalizers).To(ConsistOf(common.FINALIZER_INSTANCE, common.FINALIZER_DATABASE_SERVER))
+			Expect(hasEvent(EVENT_CREATION_FAILED)).To(BeTrue())
+		})
+
+		It("deregisters the database once its creation completes when the Database is deleted while CREATING", func() {
+			createDatabase("inflight")
+			_, err := reconcileDatabase("inflight")
+			Expect(err).NotTo(HaveOccurred())
+			database := getDatabase("inflight")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATING))
+			Expect(database.Finalizers).To(ConsistOf(common.FINALIZER_INSTANCE, common.FINALIZER_DATABASE_SERVER))
+			id := database.Status.Id
+
+			By("waiting for the creation operation")
+			Expect(k8sClient.Delete(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("inflight")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("inflight")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_DELETING))
+			Expect(database.Status.DeregistrationOperationId).To(BeEmpty())
+			Expect(database.Status.PendingCleanup).To(ContainSubstring(database.Status.CreationOperationId))
+			Expect(hasEvent(EVENT_DELETION_WAITING_FOR_OPERATION)).To(BeTrue())
+
+			By("deregistering the database once the operation passed")
+			clock.Advance(TEST_OPERATION_DURATION)
+			_, err = reconcileDatabase("inflight")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("inflight")
+			Expect(database.Status.DeregistrationOperationId).NotTo(BeEmpty())
+			Expect(database.Status.DatabaseServerId).NotTo(BeEmpty())
+			Expect(database.Status.PendingCleanup).To(ContainSubstring("Deregistering"))
+
+			clock.Advance(TEST_OPERATION_DURATION)
+			for i := 0; i < 2; i++ {
+				_, err = reconcileDatabase("inflight")
+				Expect(err).NotTo(HaveOccurred())
+			}
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "inflight"}, &ndbv1alpha1.Database{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			_, found := simulator.GetDatabase(id)
+			Expect(found).To(BeFalse())
+			clock.Advance(TEST_OPERATION_DURATION)
+			Expect(simulator.HasDatabaseServer(database.Status.DatabaseServerId)).To(BeFalse())
+		})
+
+		It("removes the finalizers when the Database is deleted before its creation was submitted", func() {
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodPost, Path: "databases/provision", StatusCode: http.StatusServiceUnavailable})
+			createDatabase("unsubmitted")
+			_, err := reconcileDatabase("unsubmitted")
+			Expect(err).To(HaveOccurred())
+			database := getDatabase("unsubmitted")
+			Expect(database.Finalizers).To(ConsistOf(common.FINALIZER_INSTANCE, common.FINALIZER_DATABASE_SERVER))
+
+			Expect(k8sClient.Delete(ctx, database)).To(Succeed())
+			for i := 0; i < 2; i++ {
+				_, err = reconcileDatabase("unsubmitted")
+				Expect(err).NotTo(HaveOccurred())
+			}
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "unsubmitted"}, &ndbv1alpha1.Database{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("marks the database CREATION ERROR when NDB rejects the creation request", func() {
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodPost, Path: "databases/provision", StatusCode: http.StatusBadRequest})
+			createDatabase("rejected")
+			result, err := reconcileDatabase("rejected")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+			database := getDatabase("rejected")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATION_ERROR))
+			Expect(database.Status.Id).To(BeEmpty())
+			Expect(hasEvent(EVENT_CREATION_REJECTED)).To(BeTrue())
+		})
+
+		It("re-attempts the creation after deleting the failed database when the retry annotation is set", func() {
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodPost, Path: "databases/provision", FailOperation: true, Count: 1})
+			createDatabase("annotated")
+			_, err := reconcileDatabase("annotated")
+			Expect(err).NotTo(HaveOccurred())
+			clock.Advance(TEST_OPERATION_DURATION)
+			_, err = reconcileDatabase("annotated")
+			Expect(err).NotTo(HaveOccurred())
+			database := getDatabase("annotated")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATION_ERROR))
+			Expect(database.Status.LastCreationFailure).NotTo(BeEmpty())
+			Expect(database.Status.LastCreationFailureTime).NotTo(BeNil())
+			failed, found := simulator.GetDatabase(database.Status.Id)
+			Expect(found).To(BeTrue())
+			failedServerId := failed.DatabaseNodes[0].DatabaseServerId
+
+			By("deleting the failed database")
+			database.Annotations = map[string]string{common.ANNOTATION_RETRY: "true"}
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("annotated")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDatabase("annotated").Status.CleanupOperationId).NotTo(BeEmpty())
+			Expect(hasEvent(EVENT_CREATION_CLEANUP_STARTED)).To(BeTrue())
+
+			By("deleting its database server")
+			clock.Advance(TEST_OPERATION_DURATION)
+			_, err = reconcileDatabase("annotated")
+			Expect(err).NotTo(HaveOccurred())
+			_, found = simulator.GetDatabase(failed.Id)
+			Expect(found).To(BeFalse())
+			Expect(getDatabase("annotated").Status.DatabaseServerId).To(BeEmpty())
+
+			By("re-attempting the creation and removing the annotation")
+			clock.Advance(TEST_OPERATION_DURATION)
+			_, err = reconcileDatabase("annotated")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(simulator.HasDatabaseServer(failedServerId)).To(BeFalse())
+			database = getDatabase("annotated")
+			Expect(database.Status.Status).To(BeEmpty())
+			Expect(database.Status.CreationRetries).To(Equal(1))
+			Expect(database.Status.LastCreationFailure).NotTo(BeEmpty())
+			Expect(database.Annotations).NotTo(HaveKey(common.ANNOTATION_RETRY))
+			Expect(hasEvent(EVENT_CREATION_RETRIED)).To(BeTrue())
+
+			_, err = reconcileDatabase("annotated")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("annotated")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATING))
+			Expect(database.Status.Id).NotTo(Equal(failed.Id))
+		})
+
+		It("re-attempts the creation as per the retry policy", func() {
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodPost, Path: "databases/provision", StatusCode: http.StatusBadRequest, Count: 2})
+			createDatabase("policy")
+			database := getDatabase("policy")
+			database.Spec.RetryPolicy = &ndbv1alpha1.RetryPolicy{MaxAttempts: 1, BackoffSeconds: 30}
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+
+			By("backing off after the failure")
+			result, err := reconcileDatabase("policy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Second, time.Second))
+			database = getDatabase("policy")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATION_ERROR))
+
+			By("re-attempting the creation once the backoff has elapsed")
+			database.Status.LastCreationFailureTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+			Expect(k8sClient.Status().Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("policy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDatabase("policy").Status.CreationRetries).To(Equal(1))
+
+			By("not re-attempting the creation once the attempts are used up")
+			result, err = reconcileDatabase("policy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+			database = getDatabase("policy")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATION_ERROR))
+			Expect(database.Status.CreationRetries).To(Equal(1))
+		})
+
+		It("retries the creation request when NDB fails with a 5xx", func() {
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodPost, Path: "databases/provision", StatusCode: http.StatusInternalServerError, Count: 1})
+			createDatabase("retried")
+			_, err := reconcileDatabase("retried")
+			Expect(err).To(HaveOccurred())
+			Expect(getDatabase("retried").Status.Status).To(BeEmpty())
+
+			_, err = reconcileDatabase("retried")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDatabase("retried").Status.Status).To(Equal(common.DATABASE_CR_STATUS_CREATING))
+		})
+
+		It("reports the drift from the spec of changes made on NDB and reapplies the spec with the Enforce policy", func() {
+			database := provisionDatabase("drifted")
+			condition := meta.FindStatusCondition(database.Status.Conditions, common.CONDITION_TYPE_DRIFTED)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(common.CONDITION_REASON_IN_SYNC))
+			ndbDatabase, _ := simulator.GetDatabase(database.Status.Id)
+			description := ndbDatabase.Description
+
+			By("reporting the changes made on NDB")
+			Expect(simulator.ModifyDatabase(database.Status.Id, func(db *ndb_api.DatabaseResponse, tm *ndb_api.TimeMachineResponse) {
+				db.Description = "changed on NDB"
+				db.Properties = []ndb_api.Property{{Name: common.PROPERTY_NAME_DATABASE_SIZE, Value: "20"}}
+				tm.Sla = ndb_api.TimeMachineSLA{Id: "brass", Name: "DEFAULT_OOB_BRASS_SLA"}
+			})).To(BeTrue())
+			// The spec change triggers the comparison
+			database.Spec.DriftPolicy = common.DRIFT_POLICY_REPORT
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err := reconcileDatabase("drifted")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("drifted")
+			Expect(database.Status.Drift).To(ConsistOf(
+				ndbv1alpha1.DriftedField{Field: DRIFT_FIELD_DESCRIPTION, Desired: description, Actual: "changed on NDB"},
+				ndbv1alpha1.DriftedField{Field: DRIFT_FIELD_SIZE, Desired: strconv.Itoa(database.Spec.Instance.Size), Actual: "20"},
+				ndbv1alpha1.DriftedField{Field: DRIFT_FIELD_TIME_MACHINE + ".sla", Desired: common.SLA_NAME_NONE, Actual: "DEFAULT_OOB_BRASS_SLA"},
+			))
+			condition = meta.FindStatusCondition(database.Status.Conditions, common.CONDITION_TYPE_DRIFTED)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(common.CONDITION_REASON_DRIFT_DETECTED))
+			Expect(hasEvent(EVENT_DRIFT_DETECTED)).To(BeTrue())
+
+			By("reapplying the description and SLA on NDB")
+			// The size is only reported, not reapplied, it is restored on NDB
+			Expect(simulator.ModifyDatabase(database.Status.Id, func(db *ndb_api.DatabaseResponse, _ *ndb_api.TimeMachineResponse) {
+				db.Properties = []ndb_api.Property{{Name: common.PROPERTY_NAME_DATABASE_SIZE, Value: strconv.Itoa(database.Spec.Instance.Size)}}
+			})).To(BeTrue())
+			database.Spec.DriftPolicy = common.DRIFT_POLICY_ENFORCE
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("drifted")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("drifted")
+			Expect(database.Status.Drift).To(BeEmpty())
+			condition = meta.FindStatusCondition(database.Status.Conditions, common.CONDITION_TYPE_DRIFTED)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(common.CONDITION_REASON_DRIFT_ENFORCED))
+			Expect(hasEvent(EVENT_DRIFT_ENFORCED)).To(BeTrue())
+			ndbDatabase, _ = simulator.GetDatabase(database.Status.Id)
+			Expect(ndbDatabase.Description).To(Equal(description))
+			timeMachine, _ := simulator.GetTimeMachine(ndbDatabase.TimeMachineId)
+			Expect(timeMachine.Sla.Name).To(Equal(common.SLA_NAME_NONE))
+		})
+
+		It("reports the failures to scan for orphans and to fetch the catalog in conditions without changing the status", func() {
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodGet, Path: "dbservers", StatusCode: http.StatusInternalServerError})
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodGet, Path: "clusters", StatusCode: http.StatusInternalServerError})
+			ndbServer := scanNDBServerOrphans()
+			Expect(ndbServer.Status.Status).To(Equal(common.NDB_CR_STATUS_OK))
+			orphansScanned := meta.FindStatusCondition(ndbServer.Status.Conditions, common.CONDITION_TYPE_ORPHANS_SCANNED)
+			Expect(orphansScanned).NotTo(BeNil())
+			Expect(orphansScanned.Status).To(Equal(metav1.ConditionFalse))
+			Expect(orphansScanned.Reason).To(Equal(common.CONDITION_REASON_ORPHAN_SCAN_FAILED))
+			catalogSynced := meta.FindStatusCondition(ndbServer.Status.Conditions, common.CONDITION_TYPE_CATALOG_SYNCED)
+			Expect(catalogSynced).NotTo(BeNil())
+			Expect(catalogSynced.Status).To(Equal(metav1.ConditionFalse))
+			Expect(catalogSynced.Reason).To(Equal(common.CONDITION_REASON_CATALOG_FETCH_FAILED))
+
+			By("reporting the next scan once NDB recovers")
+			simulator.ClearFaults()
+			ndbServer = scanNDBServerOrphans()
+			Expect(ndbServer.Status.Status).To(Equal(common.NDB_CR_STATUS_OK))
+			Expect(meta.IsStatusConditionTrue(ndbServer.Status.Conditions, common.CONDITION_TYPE_ORPHANS_SCANNED)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(ndbServer.Status.Conditions, common.CONDITION_TYPE_CATALOG_SYNCED)).To(BeTrue())
+		})
+
+		It("reports the databases created by the operator without a Database as orphans and deprovisions them with the orphan policy", func() {
+			database := provisionDatabase("managed")
+			orphanId := simulator.AddDatabase("orphan", common.DATABASE_ENGINE_TYPE_POSTGRES)
+			Expect(simulator.ModifyDatabase(orphanId, func(db *ndb_api.DatabaseResponse, _ *ndb_api.TimeMachineResponse) {
+				db.Tags = []ndb_api.Tag{
+					{TagName: common.NDB_TAG_NAME_CR_UID, Value: "deleted-uid"},
+					{TagName: common.NDB_TAG_NAME_CLUSTER_ID, Value: TEST_CLUSTER_ID},
+				}
+			})).To(BeTrue())
+			// Databases created by another cluster or outside of the operator are not orphans
+			otherId := simulator.AddDatabase("other", common.DATABASE_ENGINE_TYPE_POSTGRES)
+			Expect(simulator.ModifyDatabase(otherId, func(db *ndb_api.DatabaseResponse, _ *ndb_api.TimeMachineResponse) {
+				db.Tags = []ndb_api.Tag{
+					{TagName: common.NDB_TAG_NAME_CR_UID, Value: "other-uid"},
+					{TagName: common.NDB_TAG_NAME_CLUSTER_ID, Value: "other-cluster"},
+				}
+			})).To(BeTrue())
+			simulator.AddDatabase("untagged", common.DATABASE_ENGINE_TYPE_POSTGRES)
+
+			By("reporting the orphan")
+			ndbServer := scanNDBServerOrphans()
+			Expect(ndbServer.Status.Orphans).To(HaveLen(1))
+			orphan := ndbServer.Status.Orphans[0]
+			Expect(orphan.Id).To(Equal(orphanId))
+			Expect(orphan.Type).To(Equal(common.NDB_ORPHAN_TYPE_DATABASE))
+			Expect(orphan.DatabaseUID).To(Equal("deleted-uid"))
+			Expect(orphan.DBServerIds).To(HaveLen(1))
+			Expect(orphan.DeprovisionOperationId).To(BeEmpty())
+			Expect(orphan.Scans).To(Equal(1))
+
+			By("deprovisioning the orphan and its database server when the next scan finds it after the grace period")
+			gracePeriod := 300
+			ndbServer.Spec.OrphanPolicy = &ndbv1alpha1.NDBServerOrphanPolicy{Deprovision: true, GracePeriodSeconds: &gracePeriod}
+			Expect(k8sClient.Update(ctx, ndbServer)).To(Succeed())
+			clock.Advance(time.Duration(gracePeriod) * time.Second)
+			ndbServer = scanNDBServerOrphans()
+			Expect(ndbServer.Status.Orphans).To(HaveLen(1))
+			Expect(ndbServer.Status.Orphans[0].Scans).To(Equal(2))
+			Expect(ndbServer.Status.Orphans[0].DeprovisionOperationId).NotTo(BeEmpty())
+			clock.Advance(TEST_OPERATION_DURATION)
+			ndbServer = scanNDBServerOrphans()
+			Expect(ndbServer.Status.Orphans).To(BeEmpty())
+			clock.Advance(TEST_OPERATION_DURATION)
+			Expect(simulator.HasDatabaseServer(orphan.DBServerIds[0])).To(BeFalse())
+			_, found := simulator.GetDatabase(orphanId)
+			Expect(found).To(BeFalse())
+			_, found = simulator.GetDatabase(database.Status.Id)
+			Expect(found).To(BeTrue())
+			_, found = simulator.GetDatabase(otherId)
+			Expect(found).To(BeTrue())
+		})
+
+		It("reports the database servers left behind by the operator without a database as orphans and deprovisions them", func() {
+			database := provisionDatabase("managed")
+			Expect(database.Status.DatabaseServerId).NotTo(BeEmpty())
+			leftId := simulator.AddDatabase("left", common.DATABASE_ENGINE_TYPE_POSTGRES)
+			ndbDatabase, _ := simulator.GetDatabase(leftId)
+			dbServerId := ndbDatabase.DatabaseNodes[0].DatabaseServerId
+			Expect(simulator.ModifyDatabaseServer(dbServerId, func(dbServer *ndb_api.DatabaseServerResponse) {
+				dbServer.Tags = []ndb_api.Tag{
+					{TagName: common.NDB_TAG_NAME_CR_UID, Value: "deleted-uid"},
+					{TagName: common.NDB_TAG_NAME_CLUSTER_ID, Value: TEST_CLUSTER_ID},
+				}
+			})).To(BeTrue())
+			// The database server is left behind by the deletion of its database
+			Expect(simulator.RemoveDatabase(leftId)).To(BeTrue())
+
+			By("reporting the orphan, the database server of the managed database is not an orphan")
+			ndbServer := scanNDBServerOrphans()
+			Expect(ndbServer.Status.Orphans).To(HaveLen(1))
+			orphan := ndbServer.Status.Orphans[0]
+			Expect(orphan.Id).To(Equal(dbServerId))
+			Expect(orphan.Type).To(Equal(common.NDB_ORPHAN_TYPE_DATABASE_SERVER))
+			Expect(orphan.DatabaseUID).To(Equal("deleted-uid"))
+			Expect(orphan.Scans).To(Equal(1))
+
+			By("deprovisioning the orphan when the next scan finds it after the grace period")
+			gracePeriod := 300
+			ndbServer.Spec.OrphanPolicy = &ndbv1alpha1.NDBServerOrphanPolicy{Deprovision: true, GracePeriodSeconds: &gracePeriod}
+			Expect(k8sClient.Update(ctx, ndbServer)).To(Succeed())
+			clock.Advance(time.Duration(gracePeriod) * time.Second)
+			ndbServer = scanNDBServerOrphans()
+			Expect(ndbServer.Status.Orphans).To(HaveLen(1))
+			Expect(ndbServer.Status.Orphans[0].Scans).To(Equal(2))
+			Expect(ndbServer.Status.Orphans[0].DeprovisionOperationId).NotTo(BeEmpty())
+			clock.Advance(TEST_OPERATION_DURATION)
+			ndbServer = scanNDBServerOrphans()
+			Expect(ndbServer.Status.Orphans).To(BeEmpty())
+			Expect(simulator.HasDatabaseServer(dbServerId)).To(BeFalse())
+			Expect(simulator.HasDatabaseServer(database.Status.DatabaseServerId)).To(BeTrue())
+		})
+
+		It("propagates the labels, annotations and tags of the spec as NDB tags and keeps them in sync", func() {
+			for _, entityType := range []string{common.TAG_ENTITY_TYPE_DATABASE, common.TAG_ENTITY_TYPE_TIME_MACHINE, common.TAG_ENTITY_TYPE_DATABASE_SERVER} {
+				simulator.AddTag("team", entityType)
+				simulator.AddTag("owner", entityType)
+			}
+			ndbServer := &ndbv1alpha1.NDBServer{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: TEST_NDB_SERVER_NAME}, ndbServer)).To(Succeed())
+			ndbServer.Spec.TagLabels = map[string]string{"example.com/team": "team"}
+			Expect(k8sClient.Update(ctx, ndbServer)).To(Succeed())
+			database := provisionDatabase("tagged")
+			// Returns the tags (name: value) of the database, its time machine and database server on NDB
+			getNDBTags := func() []map[string]string {
+				ndbDatabase, _ := simulator.GetDatabase(database.Status.Id)
+				timeMachine, _ := simulator.GetTimeMachine(ndbDatabase.TimeMachineId)
+				dbServer, _ := simulator.GetDatabaseServer(database.Status.DatabaseServerId)
+				var tags []map[string]string
+				for _, entityTags := range [][]ndb_api.Tag{ndbDatabase.Tags, timeMachine.Tags, dbServer.Tags} {
+					values := make(map[string]string)
+					for _, tag := range entityTags {
+						values[tag.TagName] = tag.Value
+					}
+					tags = append(tags, values)
+				}
+				return tags
+			}
+			for _, tags := range getNDBTags() {
+				Expect(tags).To(HaveKeyWithValue(common.NDB_TAG_NAME_CR_NAME, "tagged"))
+				Expect(tags).To(HaveKeyWithValue(common.NDB_TAG_NAME_CR_NAMESPACE, namespace))
+			}
+
+			By("propagating the labels, annotations and tags of the spec")
+			database.Labels = map[string]string{"example.com/team": "dba"}
+			database.Annotations = map[string]string{common.TAG_ANNOTATION_PREFIX + "owner": "annotation"}
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err := reconcileDatabase("tagged")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasEvent(EVENT_TAGS_APPLIED)).To(BeTrue())
+			for _, tags := range getNDBTags() {
+				Expect(tags).To(HaveKeyWithValue("team", "dba"))
+				Expect(tags).To(HaveKeyWithValue("owner", "annotation"))
+			}
+			database = getDatabase("tagged")
+			Expect(database.Status.Tags).To(HaveKeyWithValue("team", "dba"))
+
+			By("updating and removing the tags on NDB")
+			database.Labels = nil
+			database.Spec.Tags = map[string]string{"owner": "spec"}
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("tagged")
+			Expect(err).NotTo(HaveOccurred())
+			for _, tags := range getNDBTags() {
+				Expect(tags).NotTo(HaveKey("team"))
+				Expect(tags).To(HaveKeyWithValue("owner", "spec"))
+				Expect(tags).To(HaveKeyWithValue(common.NDB_TAG_NAME_CR_NAME, "tagged"))
+			}
+
+			By("skipping the tags not defined on NDB until they are defined")
+			database = getDatabase("tagged")
+			database.Spec.Tags["cost-center"] = "42"
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("tagged")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("tagged")
+			Expect(database.Status.Tags).NotTo(HaveKey("cost-center"))
+			Expect(database.Status.UndefinedTags).To(HaveKeyWithValue(common.TAG_ENTITY_TYPE_TIME_MACHINE, []string{"cost-center"}))
+			simulator.AddTag("cost-center", common.TAG_ENTITY_TYPE_DATABASE)
+			simulator.AddTag("cost-center", common.TAG_ENTITY_TYPE_TIME_MACHINE)
+			simulator.AddTag("cost-center", common.TAG_ENTITY_TYPE_DATABASE_SERVER)
+			_, err = reconcileDatabase("tagged")
+			Expect(err).NotTo(HaveOccurred())
+			for _, tags := range getNDBTags() {
+				Expect(tags).To(HaveKeyWithValue("cost-center", "42"))
+			}
+			database = getDatabase("tagged")
+			Expect(database.Status.Tags).To(HaveKeyWithValue("cost-center", "42"))
+			Expect(database.Status.UndefinedTags).To(BeEmpty())
+		})
+
+		It("updates the SLA and schedule of the time machine in place when the spec changes", func() {
+			database := provisionDatabase("tiered")
+			Expect(database.Status.TimeMachine).NotTo(BeNil())
+			Expect(database.Status.TimeMachine.SLAName).To(Equal(common.SLA_NAME_NONE))
+			Expect(*database.Status.TimeMachine.AppliedSpec).To(Equal(*database.Spec.Instance.TMInfo))
+
+			By("moving the database from the Bronze to the Gold SLA")
+			database.Spec.Instance.TMInfo.SLAName = "DEFAULT_OOB_BRONZE_SLA"
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err := reconcileDatabase("tiered")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("tiered")
+			Expect(database.Status.TimeMachine.SLAName).To(Equal("DEFAULT_OOB_BRONZE_SLA"))
+			Expect(hasEvent(EVENT_TIME_MACHINE_UPDATED)).To(BeTrue())
+
+			database.Spec.Instance.TMInfo.SLAName = "DEFAULT_OOB_GOLD_SLA"
+			database.Spec.Instance.TMInfo.DailySnapshotTime = "02:30:00"
+			database.Spec.Instance.TMInfo.SnapshotsPerDay = 2
+			database.Spec.Instance.TMInfo.QuarterlySnapshotMonth = "Feb"
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("tiered")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("tiered")
+			Expect(database.Status.TimeMachine.SLAName).To(Equal("DEFAULT_OOB_GOLD_SLA"))
+			Expect(database.Status.TimeMachine.DailySnapshotTime).To(Equal("02:30:00"))
+			Expect(database.Status.TimeMachine.SnapshotsPerDay).To(Equal(2))
+			Expect(database.Status.TimeMachine.QuarterlySnapshotMonth).To(Equal("Feb"))
+			Expect(*database.Status.TimeMachine.AppliedSpec).To(Equal(*database.Spec.Instance.TMInfo))
+			ndbDatabase, _ := simulator.GetDatabase(database.Status.Id)
+			timeMachine, _ := simulator.GetTimeMachine(ndbDatabase.TimeMachineId)
+			Expect(timeMachine.Sla.Name).To(Equal("DEFAULT_OOB_GOLD_SLA"))
+			Expect(timeMachine.Schedule.SnapshotTimeOfDay).To(Equal(ndb_api.TimeMachineSnapshotTimeOfDay{Hours: 2, Minutes: 30}))
+
+			// The update is not reported as drift
+			Expect(database.Status.Drift).To(BeEmpty())
+			condition := meta.FindStatusCondition(database.Status.Conditions, common.CONDITION_TYPE_DRIFTED)
+			Expect(condition.Reason).To(Equal(common.CONDITION_REASON_IN_SYNC))
+
+			By("enabling the yearly snapshot and disabling the weekly snapshot")
+			enabled, disabled := true, false
+			database.Spec.Instance.TMInfo.YearlySnapshotEnabled = &enabled
+			database.Spec.Instance.TMInfo.YearlySnapshotDay = 30
+			database.Spec.Instance.TMInfo.YearlySnapshotMonth = "Jun"
+			database.Spec.Instance.TMInfo.WeeklySnapshotEnabled = &disabled
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			_, err = reconcileDatabase("tiered")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("tiered")
+			Expect(*database.Status.TimeMachine.YearlySnapshotEnabled).To(BeTrue())
+			Expect(database.Status.TimeMachine.YearlySnapshotDay).To(Equal(30))
+			Expect(database.Status.TimeMachine.YearlySnapshotMonth).To(Equal("Jun"))
+			Expect(*database.Status.TimeMachine.WeeklySnapshotEnabled).To(BeFalse())
+			Expect(*database.Status.TimeMachine.MonthlySnapshotEnabled).To(BeTrue())
+			Expect(database.Status.TimeMachine.DailyRetention).To(BeNumerically(">", 0))
+			timeMachine, _ = simulator.GetTimeMachine(ndbDatabase.TimeMachineId)
+			Expect(timeMachine.Schedule.YearlySchedule).To(Equal(ndb_api.TimeMachineYearlySchedule{Enabled: true, DayOfMonth: 30, Month: "JUNE"}))
+			Expect(database.Status.Drift).To(BeEmpty())
+		})
+
+		It("retries the time machine update after the reconcile interval when NDB reports a conflict", func() {
+			database := provisionDatabase("busy")
+			database.Spec.Instance.TMInfo.SLAName = "DEFAULT_OOB_GOLD_SLA"
+			Expect(k8sClient.Update(ctx, database)).To(Succeed())
+			simulator.AddFault(ndb_simulator.Fault{Method: http.MethodPatch, Path: "tms", StatusCode: http.StatusConflict, Count: 1})
+			result, err := reconcileDatabase("busy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(common.DATABASE_RECONCILE_INTERVAL_SECONDS * time.Second))
+			Expect(hasEvent(EVENT_NDB_REQUEST_FAILED)).To(BeFalse())
+			Expect(getDatabase("busy").Status.TimeMachine.SLAName).To(Equal(common.SLA_NAME_NONE))
+
+			By("applying the update on the next reconcile")
+			_, err = reconcileDatabase("busy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getDatabase("busy").Status.TimeMachine.SLAName).To(Equal("DEFAULT_OOB_GOLD_SLA"))
+			Expect(hasEvent(EVENT_TIME_MACHINE_UPDATED)).To(BeTrue())
+		})
+
+		It("does not update the time machine when the spec only differs from the spec last applied by its defaults", func() {
+			database := provisionDatabase("defaulted")
+			hasEvent(EVENT_TIME_MACHINE_UPDATED)
+			// Spec applied by an operator version without the yearly snapshot, defaulted by the webhook on the next update
+			appliedSpec := database.Spec.Instance.TMInfo.DeepCopy()
+			appliedSpec.YearlySnapshotDay = 0
+			appliedSpec.YearlySnapshotMonth = ""
+			database.Status.TimeMachine.AppliedSpec = appliedSpec
+			Expect(k8sClient.Status().Update(ctx, database)).To(Succeed())
+			_, err := reconcileDatabase("defaulted")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasEvent(EVENT_TIME_MACHINE_UPDATED)).To(BeFalse())
+			database = getDatabase("defaulted")
+			Expect(*database.Status.TimeMachine.AppliedSpec).To(Equal(*database.Spec.Instance.TMInfo))
+		})
+
+		It("marks the database NOT FOUND when it is deleted outside of the operator and skips its deregistration", func() {
+			database := provisionDatabase("external")
+			Expect(simulator.RemoveDatabase(database.Status.Id)).To(BeTrue())
+			syncNDBServer()
+			_, err := reconcileDatabase("external")
+			Expect(err).NotTo(HaveOccurred())
+			database = getDatabase("external")
+			Expect(database.Status.Status).To(Equal(common.DATABASE_CR_STATUS_NOT_FOUND))
+			Expect(hasEvent(EVENT_EXTERNAL_DELETE)).To(BeTrue())
+
+			Expect(k8sClient.Delete(ctx, database)).To(Succeed())
+			for i := 0; i < 3; i++ {
+				_, err = reconcileDatabase("external")
+				Expect(err).NotTo(HaveOccurred())
+			}
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "external"}, &ndbv1alpha1.Database{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("with invalid credentials", func() {
+		It("reports a credential error when the NDB secret is missing", func() {
+			createNDBServer("missing-secret")
+			Expect(syncNDBServer().Status.Status).To(Equal(common.NDB_CR_STATUS_CREDENTIAL_ERROR))
+
+			createDatabase("no-secret")
+			_, err := reconcileDatabase("no-secret")
+			Expect(err).To(HaveOccurred())
+			Expect(getDatabase("no-secret").Status.Status).To(BeEmpty())
+			Expect(hasEvent(EVENT_INVALID_CREDENTIALS)).To(BeTrue())
+		})
+
+		It("reports an authentication error when NDB rejects the credentials", func() {
+			createSecret(TEST_NDB_SECRET_NAME, map[string]string{
+				common.SECRET_DATA_KEY_USERNAME: TEST_NDB_USERNAME,
+				common.SECRET_DATA_KEY_PASSWORD: "invalid",
+			})
+			createNDBServer(TEST_NDB_SECRET_NAME)
+			Expect(syncNDBServer().Status.Status).To(Equal(common.NDB_CR_STATUS_AUTHENTICATION_ERROR))
+
+			createDatabase("unauthorized")
+			_, err := reconcileDatabase("unauthorized")
+			Expect(err).To(HaveOccurred())
+			database := getDatabase("unauthorized")
+			Expect(database.Status.Status).To(BeEmpty())
+			Expect(database.Status.Id).To(BeEmpty())
+		})
+	})
+})
+
+// Returns true if the event recorded by a FakeRecorder ("<type> <reason> <message>") has the reason
+func containsReason(event, reason string) bool {
+	return strings.HasPrefix(event, corev1.EventTypeNormal+" "+reason+" ") || strings.HasPrefix(event, corev1.EventTypeWarning+" "+reason+" ")
+}
@@ -23,9 +23,11 @@ import (
 
 	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
 	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -77,12 +79,43 @@ func (r *DatabaseReconciler) handleDelete(ctx context.Context, database *ndbv1al
 		// else proceed check for the operation completion before removing finalizer.
 		deregistrationOperationId := database.Status.DeregistrationOperationId
 		if deregistrationOperationId == "" {
+			ready, err := r.prepareDeregistration(ctx, database, ndbClient)
+			if err != nil {
+				errStatement := "Failed to check the creation of the database on NDB before deregistering it"
+				log.Error(err, errStatement)
+				r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+				return requeueOnErr(err)
+			}
+			if !ready {
+				return requeueWithTimeout(common.DATABASE_RECONCILE_INTERVAL_SECONDS)
+			}
+			if database.Status.Id == "" {
+				// The creation was never submitted to NDB
+				log.Info("Database not created on NDB, removing Finalizer " + common.FINALIZER_INSTANCE)
+				controllerutil.RemoveFinalizer(database, common.FINALIZER_INSTANCE)
+				if err := r.Update(ctx, database); err != nil {
+					return requeueOnErr(err)
+				}
+				return requeue()
+			}
 			deregistrationOp, err := instanceManager.deregister(ctx, r, ndbClient, database)
+			if ndb_api.IsNotFound(err) {
+				// The database does not exist on NDB anymore, nothing to deregister
+				log.Info("Database not found on NDB, removing Finalizer " + common.FINALIZER_INSTANCE)
+				r.recorder.Event(database, "Warning", EVENT_EXTERNAL_DELETE, "Database not found on NDB, skipping deregistration")
+				controllerutil.RemoveFinalizer(database, common.FINALIZER_INSTANCE)
+				if err := r.Update(ctx, database); err != nil {
+					return requeueOnErr(err)
+				}
+				return requeue()
+			}
 			if err != nil {
 				// Not logging here, already done in the deregister function
 				return requeueOnErr(err)
 			}
 			database.Status.DeregistrationOperationId = deregistrationOp.OperationId
+			database.Status.PendingCleanup = fmt.Sprintf("Deregistering the database %s from NDB", database.Status.Id)
+			setDatabaseSpanAttributes(ctx, database.Status)
 			if err := r.Status().Update(ctx, database); err != nil {
 				log.Error(err, "An error occurred while updating the CR.")
 				return requeueOnErr(err)
@@ -95,10 +128,12 @@ func (r *DatabaseReconciler) handleDelete(ctx context.Context, database *ndbv1al
 			} else {
 				switch ndb_api.GetOperationStatus(deregistrationOp) {
 				case ndb_api.OPERATION_STATUS_FAILED:
+					observeOperation(OPERATION_TYPE_DEREGISTER, deregistrationOp)
 					err := fmt.Errorf("deregistration operation terminated. status: %s, message: %s, operationId: %s", deregistrationOp.Status, deregistrationOp.Message, deregistrationOperationId)
 					log.Error(err, "Deregistration Failed")
 					r.recorder.Event(database, "Warning", "OPERATION FAILED", "Database creation operation failed with error: "+err.Error())
 				case ndb_api.OPERATION_STATUS_PASSED:
+					observeOperation(OPERATION_TYPE_DEREGISTER, deregistrationOp)
 					r.recorder.Eventf(database, "Normal", EVENT_DEREGISTRATION_COMPLETED, "Database deprovisioned from NDB.")
 					log.Info("Removing Finalizer " + common.FINALIZER_INSTANCE)
 					controllerutil.RemoveFinalizer(database, common.FINALIZER_INSTANCE)
@@ -143,55 +178,127 @@ func (r *DatabaseReconciler) handleSync(ctx context.Context, database *ndbv1alph
 	databaseStatus := database.Status.DeepCopy()
 
 	instanceManager := getInstanceManager(*database)
+	isUnderDeletion := !database.ObjectMeta.DeletionTimestamp.IsZero()
 
 	// Provision the database if it has not been provisioned earlier
-	if databaseStatus.Status == "" && databaseStatus.Id == "" {
-		// DB Status.Status is empty => Provision a DB
-		taskResponse, err := instanceManager.create(ctx, r, ndbClient, database, req.Namespace)
+	if databaseStatus.Id == "" && (databaseStatus.Status == "" || databaseStatus.Status == common.DATABASE_CR_STATUS_QUEUED) && !isUnderDeletion {
+		// Wait in the queue while the NDBServer's maxConcurrentProvisioning databases are being provisioned
+		admitted, position, err := getProvisioningSlot(ctx, r.APIReader, ndbServer, database)
 		if err != nil {
-			errStatement := "Failed to create database on NDB"
+			log.Error(err, "Failed to determine the provisioning queue of the NDBServer")
+			return requeueOnErr(err)
+		}
+		if !admitted {
+			return r.queueProvisioning(ctx, database, position)
+		}
+
+		// The finalizers are added before the creation is submitted, a Database deleted while it is
+		// being created on NDB is deregistered along with its database server once the creation completes
+		if err := r.addCreationFinalizers(ctx, database); err != nil {
+			log.Error(err, "Failed to add the finalizers before creating the database")
+			return requeueOnErr(err)
+		}
+
+		// Resolve the cluster to create the database on, it is recorded in the status
+		clusterId, err := resolveClusterId(ctx, database, ndbClient)
+		if err != nil {
+			errStatement := "Failed to resolve the cluster for the database"
+			log.Error(err, errStatement)
+			r.recorder.Eventf(database, "Warning", EVENT_CLUSTER_RESOLUTION_FAILED, "Error: %s. %s", errStatement, err.Error())
+			return requeueOnErr(err)
+		}
+		database.Status.ClusterId = clusterId
+		databaseStatus.ClusterId = clusterId
+
+		// The database may have been created by an earlier reconcile whose status update was lost,
+		// its creation is tracked instead of creating a second database (and database server)
+		created, creationOperationId, err := findCreatedDatabase(ctx, ndbClient, database)
+		if err != nil {
+			errStatement := "Failed to look up the database on NDB"
 			log.Error(err, errStatement)
 			r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
 			return requeueOnErr(err)
 		}
-		log.Info(fmt.Sprintf("Updating Database CR to Status: CREATING, id: %s and creationOperationId: %s", taskResponse.EntityId, taskResponse.OperationId))
+		if created != nil {
+			log.Info(fmt.Sprintf("Database found on NDB, updating Database CR to Status: CREATING, id: %s and creationOperationId: %s", created.Id, creationOperationId))
+			databaseStatus.Status = common.DATABASE_CR_STATUS_CREATING
+			databaseStatus.Id = created.Id
+			databaseStatus.CreationOperationId = creationOperationId
+			setDatabaseSpanAttributes(ctx, *databaseStatus)
+			r.recorder.Eventf(database, "Normal", EVENT_CREATION_RESUMED, "Database %s already created on NDB, resuming tracking of its creation", created.Id)
+		} else {
+			// DB Status.Status is empty => Provision a DB
+			taskResponse, err := instanceManager.create(ctx, r, ndbClient, database, ndbServer, req.Namespace)
+			if err != nil {
+				errStatement := "Failed to create database on NDB"
+				log.Error(err, errStatement)
+				if ndbError := ndb_api.AsNDBError(err); ndbError != nil && !ndb_api.IsRetryable(ndbError) && !ndb_api.IsUnauthorized(ndbError) {
+					// NDB rejected the request, sending it again fails again
+					return r.handleCreationRejected(ctx, database, ndbClient, ndbError)
+				}
+				r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+				return requeueOnErr(err)
+			}
+			log.Info(fmt.Sprintf("Updating Database CR to Status: CREATING, id: %s and creationOperationId: %s", taskResponse.EntityId, taskResponse.OperationId))
 
-		databaseStatus.Status = common.DATABASE_CR_STATUS_CREATING
-		databaseStatus.Id = taskResponse.EntityId
-		databaseStatus.CreationOperationId = taskResponse.OperationId
-		r.recorder.Event(database, "Normal", EVENT_CREATION_STARTED, "Database creation initiated on NDB")
+			databaseStatus.Status = common.DATABASE_CR_STATUS_CREATING
+			databaseStatus.Id = taskResponse.EntityId
+			databaseStatus.CreationOperationId = taskResponse.OperationId
+			setDatabaseSpanAttributes(ctx, *databaseStatus)
+			r.recorder.Event(database, "Normal", EVENT_CREATION_STARTED, "Database creation initiated on NDB")
+		}
+		// The time machine is created with the spec, later changes to it are applied in place
+		if !database.Spec.IsClone && database.Spec.Instance != nil && database.Spec.Instance.TMInfo != nil {
+			databaseStatus.TimeMachine = &ndbv1alpha1.TimeMachineStatus{AppliedSpec: database.Spec.Instance.TMInfo.DeepCopy()}
+		}
 	}
 
 	// Handle External Sync
 	dbInfo := ndbServer.Status.Databases[databaseStatus.Id]
-	isUnderDeletion := !database.ObjectMeta.DeletionTimestamp.IsZero()
 	if isUnderDeletion {
 		databaseStatus.Status = common.DATABASE_CR_STATUS_DELETING
+	} else if databaseStatus.Status == common.DATABASE_CR_STATUS_CREATING && databaseStatus.CreationOperationId == "" {
+		// The creation of a database found on NDB is tracked without its operation, which NDB did not list
+		if dbInfo != (ndbv1alpha1.NDBServerDatabaseInfo{}) {
+			syncDatabaseStatus(databaseStatus, dbInfo)
+		} else {
+			log.Info("Waiting for the NDBServer to fetch the database from NDB", "id", databaseStatus.Id)
+			r.recorder.Event(database, "Normal", EVENT_WAITING_FOR_NDB_RECONCILE, "Waiting for the NDBServer to fetch the database from NDB")
+		}
 	} else if databaseStatus.Status == common.DATABASE_CR_STATUS_CREATING {
 		creationOp, err := ndb_api.GetOperationById(ctx, ndbClient, databaseStatus.CreationOperationId)
-		if err != nil {
-			message := fmt.Sprintf("NDB API to fetch operation by id failed. OperationId: %s:, error: %s", creationOp.Id, err.Error())
+		if ndb_api.IsNotFound(err) {
+			// The creation operation does not exist on NDB anymore, sync with the database on NDB
+			log.Info("Creation operation not found on NDB", "operationId", databaseStatus.CreationOperationId)
+			if dbInfo != (ndbv1alpha1.NDBServerDatabaseInfo{}) {
+				syncDatabaseStatus(databaseStatus, dbInfo)
+			} else {
+				databaseStatus.Status = common.DATABASE_CR_STATUS_NOT_FOUND
+			}
+		} else if err != nil {
+			message := fmt.Sprintf("NDB API to fetch operation by id failed. OperationId: %s:, error: %s", databaseStatus.CreationOperationId, err.Error())
 			r.recorder.Event(database, "Warning", EVENT_NDB_REQUEST_FAILED, message)
 		} else {
 			switch ndb_api.GetOperationStatus(creationOp) {
 			case ndb_api.OPERATION_STATUS_FAILED:
-				databaseStatus.Status = common.DATABASE_CR_STATUS_CREATION_ERROR
+				observeCreationOperation(database, creationOp)
 				err = fmt.Errorf("creation operation terminated. status: %s, message: %s, operationId: %s", creationOp.Status, creationOp.Message, creationOp.Id)
+				setCreationFailure(databaseStatus, err.Error())
 				log.Error(err, "Database Creation Failed")
 				r.recorder.Event(database, "Warning", EVENT_CREATION_FAILED, "Database creation operation failed with error: "+err.Error())
 			case ndb_api.OPERATION_STATUS_PASSED:
+				observeCreationOperation(database, creationOp)
 				databaseStatus.Status = common.DATABASE_CR_STATUS_READY
 				r.recorder.Event(database, "Normal", EVENT_CREATION_COMPLETED, "Database creation operation passed")
 			default:
 				// Do nothing, we do not care about other statuses
 			}
 		}
+	} else if databaseStatus.Status == common.DATABASE_CR_STATUS_CREATION_ERROR {
+		// Kept until the creation is re-attempted, the failed database may still be listed by the NDBServer
+		log.Info("Database creation failed", "lastCreationFailure", databaseStatus.LastCreationFailure, "creationRetries", databaseStatus.CreationRetries)
 	} else if dbInfo != (ndbv1alpha1.NDBServerDatabaseInfo{}) {
-		databaseStatus.Status = dbInfo.Status
-		databaseStatus.Id = dbInfo.Id
-		databaseStatus.IPAddress = dbInfo.IPAddress
-		databaseStatus.DatabaseServerId = dbInfo.DBServerId
-		databaseStatus.Type = ndb_api.GetDatabaseTypeFromEngine(dbInfo.Type)
+		syncDatabaseStatus(databaseStatus, dbInfo)
 	} else {
 		log.Info("Database missing from NDB CR")
 		databaseStatus.Status = common.DATABASE_CR_STATUS_NOT_FOUND
@@ -210,15 +317,16 @@ func (r *DatabaseReconciler) handleSync(ctx context.Context, database *ndbv1alph
 
 	// Handle Internal Sync -
 	// [READY]
-	// Add finalizers only when the database is in ready state so that if
-	// any failure occurrs before reaching the ready state, the failure
-	// would not cause the deletion to block the terminal.
+	// Add the finalizers if they are missing, they are added before the creation
+	// is submitted except for the databases created by earlier operator versions.
 	// Also, setup and create network services.
 	// [DELETING]
 	// Delete the database instance and the VM as per the finalizers
 	// [NOT FOUND]
 	// Record an event and then do not requeue since the resource has been deleted externally
 	// or was not found on NDB
+	// [CREATION ERROR]
+	// Re-attempt the creation if requested by the annotation or the retry policy
 	switch databaseStatus.Status {
 	case common.DATABASE_CR_STATUS_READY:
 		if !isUnderDeletion {
@@ -239,12 +347,23 @@ func (r *DatabaseReconciler) handleSync(ctx context.Context, database *ndbv1alph
 			log.Info(message)
 			r.recorder.Event(database, "Warning", EVENT_WAITING_FOR_IP_ADDRESS, message)
 		}
+		if !isUnderDeletion {
+			if err := r.syncTimeMachine(ctx, database, ndbClient); err != nil {
+				return requeueOnUpdateErr(err)
+			}
+			if err := r.checkDrift(ctx, database, ndbClient); err != nil {
+				return requeueOnErr(err)
+			}
+			if err := r.syncTags(ctx, database, ndbClient, ndbServer); err != nil {
+				return requeueOnUpdateErr(err)
+			}
+		}
 	case common.DATABASE_CR_STATUS_DELETING:
 		return r.handleDelete(ctx, database, ndbClient)
 	case common.DATABASE_CR_STATUS_NOT_FOUND:
 		r.recorder.Eventf(database, "Warning", EVENT_EXTERNAL_DELETE, "Error: Resource not found on NDB")
 	case common.DATABASE_CR_STATUS_CREATION_ERROR:
-		return doNotRequeue()
+		return r.handleCreationError(ctx, database, ndbClient)
 	default:
 		// No-Op
 	}
@@ -252,6 +371,155 @@ func (r *DatabaseReconciler) handleSync(ctx context.Context, database *ndbv1alph
 	return requeueWithTimeout(common.DATABASE_RECONCILE_INTERVAL_SECONDS)
 }
 
+// Adds the instance and database server finalizers if they are missing
+func (r *DatabaseReconciler) addCreationFinalizers(ctx context.Context, database *ndbv1alpha1.Database) error {
+	if controllerutil.ContainsFinalizer(database, common.FINALIZER_INSTANCE) && controllerutil.ContainsFinalizer(database, common.FINALIZER_DATABASE_SERVER) {
+		return nil
+	}
+	ctrllog.FromContext(ctx).Info("Adding finalizers " + common.FINALIZER_INSTANCE + " and " + common.FINALIZER_DATABASE_SERVER)
+	controllerutil.AddFinalizer(database, common.FINALIZER_INSTANCE)
+	controllerutil.AddFinalizer(database, common.FINALIZER_DATABASE_SERVER)
+	return r.Update(ctx, database)
+}
+
+// Prepares the deregistration of a Database deleted before its creation on NDB completed.
+// The database is deregistered once the operation creating it (or cleaning up its failed creation) has completed,
+// NDB does not abort provisioning and cloning operations. The database created by a reconcile whose status update
+// was lost is looked up, along with the database server of the database if it is not in the status yet.
+// ready is false while an operation is running, the pending cleanup is then reported in the status.
+func (r *DatabaseReconciler) prepareDeregistration(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient) (ready bool, err error) {
+	log := ctrllog.FromContext(ctx)
+	databaseStatus := &database.Status
+	if databaseStatus.Id == "" {
+		created, creationOperationId, err := findCreatedDatabase(ctx, ndbClient, database)
+		if err != nil || created == nil {
+			return err == nil, err
+		}
+		log.Info("Database found on NDB", "id", created.Id, "creationOperationId", creationOperationId)
+		databaseStatus.Id = created.Id
+		databaseStatus.CreationOperationId = creationOperationId
+	}
+	for _, operationId := range []string{databaseStatus.CreationOperationId, databaseStatus.CleanupOperationId} {
+		if operationId == "" {
+			continue
+		}
+		operation, err := ndb_api.GetOperationById(ctx, ndbClient, operationId)
+		if ndb_api.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if ndb_api.GetOperationStatus(operation) == "" {
+			pendingCleanup := fmt.Sprintf("Waiting for the operation %s (%s%% complete) to complete before deregistering the database %s", operation.Id, operation.PercentageComplete, databaseStatus.Id)
+			log.Info(pendingCleanup)
+			if databaseStatus.PendingCleanup != pendingCleanup {
+				databaseStatus.PendingCleanup = pendingCleanup
+				r.recorder.Event(database, "Normal", EVENT_DELETION_WAITING_FOR_OPERATION, pendingCleanup)
+				err = r.Status().Update(ctx, database)
+			}
+			return false, err
+		}
+	}
+	if databaseStatus.DatabaseServerId == "" {
+		// Synced from the NDBServer only once the database is READY
+		entity, err := getNDBDatabase(ctx, ndbClient, database)
+		if err != nil && !ndb_api.IsNotFound(err) {
+			return false, err
+		}
+		if err == nil && len(entity.DatabaseNodes) > 0 {
+			databaseStatus.DatabaseServerId = entity.DatabaseNodes[0].DatabaseServerId
+		}
+	}
+	return true, nil
+}
+
+// Adds the ids of the database and of its current NDB operation to the span of the reconcile
+func setDatabaseSpanAttributes(ctx context.Context, databaseStatus ndbv1alpha1.DatabaseStatus) {
+	operationId := databaseStatus.CreationOperationId
+	if databaseStatus.DeregistrationOperationId != "" {
+		operationId = databaseStatus.DeregistrationOperationId
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		tracing.ATTRIBUTE_ENTITY_ID.String(databaseStatus.Id),
+		tracing.ATTRIBUTE_OPERATION_ID.String(operationId),
+	)
+}
+
+// Updates the database status with the database info from the NDBServer status
+func syncDatabaseStatus(databaseStatus *ndbv1alpha1.DatabaseStatus, dbInfo ndbv1alpha1.NDBServerDatabaseInfo) {
+	databaseStatus.Status = dbInfo.Status
+	databaseStatus.Id = dbInfo.Id
+	databaseStatus.IPAddress = dbInfo.IPAddress
+	databaseStatus.DatabaseServerId = dbInfo.DBServerId
+	databaseStatus.Type = ndb_api.GetDatabaseTypeFromEngine(dbInfo.Type)
+}
+
+// Marks the database as CREATION ERROR when NDB rejects the creation request with a terminal (4xx) error.
+// The request is only requeued if the retry policy re-attempts the creation, otherwise the spec has to be fixed
+// and the creation re-attempted with the ndb.nutanix.com/retry annotation.
+// The cached profiles and SLAs of the NDB server are invalidated in case the request was generated from stale entries.
+func (r *DatabaseReconciler) handleCreationRejected(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient, ndbError *ndb_api.NDBError) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+	ndb_api.InvalidateCache(ndbClient)
+	message := fmt.Sprintf("NDB rejected the database creation request with status %d", ndbError.StatusCode)
+	if ndbError.Reason != "" {
+		message += ": " + ndbError.Reason
+	} else if ndbError.Message != "" {
+		message += ": " + ndbError.Message
+	}
+	if ndbError.Remedy != "" {
+		message += ". Remedy: " + ndbError.Remedy
+	}
+	log.Info(message, "errorCode", ndbError.ErrorCode)
+	r.recorder.Event(database, "Warning", EVENT_CREATION_REJECTED, message)
+	setCreationFailure(&database.Status, message)
+	if err := r.Status().Update(ctx, database); err != nil {
+		log.Error(err, "Failed to update status of database custom resource")
+		return requeueOnErr(err)
+	}
+	return r.handleCreationError(ctx, database, ndbClient)
+}
+
+// Marks the database as QUEUED while it waits for a provisioning slot on the NDBServer and requeues the request
+func (r *DatabaseReconciler) queueProvisioning(ctx context.Context, database *ndbv1alpha1.Database, position int) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+	log.Info("Database queued for provisioning", "position", position)
+	if database.Status.Status != common.DATABASE_CR_STATUS_QUEUED {
+		database.Status.Status = common.DATABASE_CR_STATUS_QUEUED
+		if err := r.Status().Update(ctx, database); err != nil {
+			errStatement := "Failed to update status of database custom resource"
+			log.Error(err, errStatement)
+			r.recorder.Eventf(database, "Warning", EVENT_CR_STATUS_UPDATE_FAILED, "Error: %s. %s.", errStatement, err.Error())
+			return requeueOnErr(err)
+		}
+		r.recorder.Eventf(database, "Normal", EVENT_CREATION_QUEUED, "Database creation queued at position %d, the NDBServer's maxConcurrentProvisioning is reached", position+1)
+	}
+	return requeueWithTimeout(common.DATABASE_RECONCILE_INTERVAL_SECONDS)
+}
+
+// Returns the id of the cluster specified in the database spec.
+// If the cluster is specified by name, it is resolved to the id of the cluster with that name on NDB.
+func resolveClusterId(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient) (clusterId string, err error) {
+	log := ctrllog.FromContext(ctx)
+	var clusterName string
+	if database.Spec.IsClone {
+		clusterId, clusterName = database.Spec.Clone.ClusterId, database.Spec.Clone.ClusterName
+	} else {
+		clusterId, clusterName = database.Spec.Instance.ClusterId, database.Spec.Instance.ClusterName
+	}
+	if clusterName == "" {
+		return
+	}
+	cluster, err := ndb_api.GetClusterByName(ctx, ndbClient, clusterName)
+	if err != nil {
+		return
+	}
+	log.Info("Resolved cluster name", "cluster name", clusterName, "cluster id", cluster.Id)
+	clusterId = cluster.Id
+	return
+}
+
 // Sets up a kubernetes networking service (Without selectors)
 // Then sets up an endpoint with the same name as the service
 // to map to an external endpoint (NDB database instance in our scenario).
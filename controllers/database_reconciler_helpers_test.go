@@ -0,0 +1,73 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+func TestDatabaseReconciler_handleCreationRejected(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = ndbv1alpha1.AddToScheme(scheme)
+	database := &ndbv1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec:       ndbv1alpha1.DatabaseSpec{NDBRef: "ndb"},
+	}
+	recorder := record.NewFakeRecorder(1)
+	r := &DatabaseReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(database).WithStatusSubresource(database).Build(),
+		Scheme:   scheme,
+		recorder: recorder,
+	}
+	ndbError := &ndb_api.NDBError{StatusCode: http.StatusBadRequest, Reason: "Invalid software profile", Remedy: "Use a published version"}
+
+	result, err := r.handleCreationRejected(context.TODO(), database, nil, ndbError)
+	if err != nil || result.Requeue || result.RequeueAfter != 0 {
+		t.Errorf("handleCreationRejected() = (%v, %v), want no requeue", result, err)
+	}
+	updated := &ndbv1alpha1.Database{}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(database), updated); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if updated.Status.Status != common.DATABASE_CR_STATUS_CREATION_ERROR {
+		t.Errorf("status = %s, want %s", updated.Status.Status, common.DATABASE_CR_STATUS_CREATION_ERROR)
+	}
+	if !strings.Contains(updated.Status.LastCreationFailure, "Invalid software profile") || updated.Status.LastCreationFailureTime == nil {
+		t.Errorf("lastCreationFailure = %q at %v, want the rejection reason", updated.Status.LastCreationFailure, updated.Status.LastCreationFailureTime)
+	}
+	event := <-recorder.Events
+	for _, want := range []string{EVENT_CREATION_REJECTED, "Invalid software profile", "Use a published version"} {
+		if !strings.Contains(event, want) {
+			t.Errorf("event %q does not contain %q", event, want)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/controller_adapters"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// State of the database on NDB as per the spec, with the SLA and profiles resolved on NDB
+type desiredDatabaseState struct {
+	description string
+	slaId       string
+	slaName     string
+	// Size of the database in GiB
+	size int
+	// nil if the spec has no time machine details
+	schedule *ndb_api.Schedule
+	// Ids of the profiles keyed by the property of the database server reporting them
+	profileIds map[string]string
+}
+
+// State of the database, its time machine and database server on NDB
+type actualDatabaseState struct {
+	database    *ndb_api.DatabaseResponse
+	timeMachine *ndb_api.TimeMachineResponse
+	// nil if the database has no database server
+	dbServer *ndb_api.DatabaseServerResponse
+}
+
+// Returns true if the database has to be compared with NDB, once every DATABASE_DRIFT_CHECK_INTERVAL_SECONDS
+// and as soon as the spec changes
+func isDriftCheckDue(database *ndbv1alpha1.Database, now time.Time) bool {
+	condition := meta.FindStatusCondition(database.Status.Conditions, common.CONDITION_TYPE_DRIFTED)
+	if condition == nil || condition.ObservedGeneration != database.Generation || database.Status.DriftCheckTime == nil {
+		return true
+	}
+	return now.Sub(database.Status.DriftCheckTime.Time) >= common.DATABASE_DRIFT_CHECK_INTERVAL_SECONDS*time.Second
+}
+
+// Compares the spec of a READY database instance with the database, time machine and database server on NDB.
+// The differences are reported in status.drift and the Drifted condition, with the Enforce drift policy the
+// description, SLA and snapshot schedule are reapplied on NDB. Clones are not compared, the Drifted condition of a
+// clone reports it with the DriftCheckNotSupported reason.
+func (r *DatabaseReconciler) checkDrift(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient) error {
+	log := ctrllog.FromContext(ctx)
+	if database.Status.Id == "" {
+		return nil
+	}
+	if database.Spec.IsClone {
+		return r.reportDriftCheckNotSupported(ctx, database)
+	}
+	if !isDriftCheckDue(database, time.Now()) {
+		return nil
+	}
+	log.Info("Comparing the database with NDB", "id", database.Status.Id)
+
+	condition := metav1.Condition{Type: common.CONDITION_TYPE_DRIFTED, ObservedGeneration: database.Generation}
+	desired, actual, err := getDriftStates(ctx, ndbClient, database)
+	if err != nil {
+		log.Error(err, "Failed to compare the database with NDB")
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = common.CONDITION_REASON_DRIFT_CHECK_FAILED
+		condition.Message = err.Error()
+	} else {
+		if actual.timeMachine != nil {
+			database.Status.TimeMachine = getTimeMachineStatus(actual.timeMachine, getAppliedTimeMachineSpec(database))
+		}
+		drift := computeDrift(desired, actual)
+		var enforced []ndbv1alpha1.DriftedField
+		if database.Spec.DriftPolicy == common.DRIFT_POLICY_ENFORCE && len(drift) > 0 {
+			drift, enforced = r.enforceDrift(ctx, database, ndbClient, desired, actual, drift)
+		}
+		switch {
+		case len(drift) > 0:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = common.CONDITION_REASON_DRIFT_DETECTED
+			condition.Message = describeDrift(drift)
+			if !reflect.DeepEqual(drift, database.Status.Drift) {
+				r.recorder.Eventf(database, "Warning", EVENT_DRIFT_DETECTED, "The database on NDB differs from the spec: %s", condition.Message)
+			}
+		case len(enforced) > 0:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = common.CONDITION_REASON_DRIFT_ENFORCED
+			condition.Message = "Reapplied the spec on NDB: " + describeDrift(enforced)
+		default:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = common.CONDITION_REASON_IN_SYNC
+			condition.Message = "The database on NDB matches the spec"
+		}
+		database.Status.Drift = drift
+	}
+	now := metav1.Now()
+	database.Status.DriftCheckTime = &now
+	meta.SetStatusCondition(&database.Status.Conditions, condition)
+	if err = r.Status().Update(ctx, database); err != nil {
+		errStatement := "Failed to update status of database custom resource"
+		log.Error(err, errStatement)
+		r.recorder.Eventf(database, "Warning", EVENT_CR_STATUS_UPDATE_FAILED, "Error: %s. %s.", errStatement, err.Error())
+	}
+	return err
+}
+
+// Sets the Drifted condition of a clone, which is not compared with NDB, once per generation
+func (r *DatabaseReconciler) reportDriftCheckNotSupported(ctx context.Context, database *ndbv1alpha1.Database) error {
+	condition := meta.FindStatusCondition(database.Status.Conditions, common.CONDITION_TYPE_DRIFTED)
+	if condition != nil && condition.Reason == common.CONDITION_REASON_DRIFT_CHECK_NOT_SUPPORTED && condition.ObservedGeneration == database.Generation {
+		return nil
+	}
+	meta.SetStatusCondition(&database.Status.Conditions, metav1.Condition{
+		Type:               common.CONDITION_TYPE_DRIFTED,
+		Status:             metav1.ConditionUnknown,
+		Reason:             common.CONDITION_REASON_DRIFT_CHECK_NOT_SUPPORTED,
+		Message:            "Clones are not compared with NDB",
+		ObservedGeneration: database.Generation,
+	})
+	if err := r.Status().Update(ctx, database); err != nil {
+		errStatement := "Failed to update status of database custom resource"
+		ctrllog.FromContext(ctx).Error(err, errStatement)
+		r.recorder.Eventf(database, "Warning", EVENT_CR_STATUS_UPDATE_FAILED, "Error: %s. %s.", errStatement, err.Error())
+		return err
+	}
+	return nil
+}
+
+// Fetches the database, its time machine and database server from NDB and resolves the SLA and profiles of the spec
+func getDriftStates(ctx context.Context, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database) (desired desiredDatabaseState, actual actualDatabaseState, err error) {
+	databaseAdapter := &controller_adapters.Database{Database: *database}
+	desired.description = databaseAdapter.GetDescription()
+	desired.size = databaseAdapter.GetInstanceSize()
+	if database.Spec.Instance.TMInfo != nil {
+		_, _, desired.slaName = databaseAdapter.GetInstanceTMDetails()
+		var sla ndb_api.SLAResponse
+		if sla, err = ndb_api.GetSLAByName(ctx, ndbClient, desired.slaName); err != nil {
+			return
+		}
+		desired.slaId = sla.Id
+		var schedule ndb_api.Schedule
+		if schedule, err = databaseAdapter.GetTMScheduleForInstance(); err != nil {
+			return
+		}
+		desired.schedule = &schedule
+	}
+	profilesMap, err := ndb_api.ResolveProfiles(ctx, ndbClient, databaseAdapter.GetInstanceType(), databaseAdapter.GetProfileResolvers())
+	if err != nil {
+		return
+	}
+	desired.profileIds = map[string]string{
+		common.PROPERTY_NAME_COMPUTE_PROFILE_ID:  profilesMap[common.PROFILE_TYPE_COMPUTE].Id,
+		common.PROPERTY_NAME_NETWORK_PROFILE_ID:  profilesMap[common.PROFILE_TYPE_NETWORK].Id,
+		common.PROPERTY_NAME_SOFTWARE_PROFILE_ID: profilesMap[common.PROFILE_TYPE_SOFTWARE].Id,
+	}
+
+	if actual.database, err = ndb_api.GetDatabaseById(ctx, ndbClient, database.Status.Id); err != nil {
+		return
+	}
+	if actual.database.TimeMachineId != "" {
+		if actual.timeMachine, err = ndb_api.GetTimeMachineById(ctx, ndbClient, actual.database.TimeMachineId); err != nil {
+			return
+		}
+	}
+	if database.Status.DatabaseServerId != "" {
+		actual.dbServer, err = ndb_api.GetDatabaseServerById(ctx, ndbClient, database.Status.DatabaseServerId)
+	}
+	return
+}
+
+// Returns the fields of the spec that differ from NDB. The SLA and schedule are only compared if NDB reports them,
+// the size if the database reports it in its properties and the profiles if the database server reports them in its properties.
+func computeDrift(desired desiredDatabaseState, actual actualDatabaseState) (drift []ndbv1alpha1.DriftedField) {
+	addDrift := func(field, desiredValue, actualValue string) {
+		if desiredValue != actualValue {
+			drift = append(drift, ndbv1alpha1.DriftedField{Field: field, Desired: desiredValue, Actual: actualValue})
+		}
+	}
+	addDrift(DRIFT_FIELD_DESCRIPTION, desired.description, actual.database.Description)
+	for _, property := range actual.database.Properties {
+		if property.Name == common.PROPERTY_NAME_DATABASE_SIZE && desired.size > 0 {
+			addDrift(DRIFT_FIELD_SIZE, strconv.Itoa(desired.size), property.Value)
+		}
+	}
+
+	if tm := actual.timeMachine; tm != nil && desired.schedule != nil {
+		if tm.Sla.Id != "" && tm.Sla.Id != desired.slaId {
+			actualSla := tm.Sla.Name
+			if actualSla == "" {
+				actualSla = tm.Sla.Id
+			}
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".sla", desired.slaName, actualSla)
+		}
+		if tm.Schedule.Id != "" {
+			desiredSchedule, actualSchedule := desired.schedule, tm.Schedule
+			formatTime := func(hours, minutes, seconds int) string {
+				return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+			}
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".dailySnapshotTime",
+				formatTime(desiredSchedule.SnapshotTimeOfDay.Hours, desiredSchedule.SnapshotTimeOfDay.Minutes, desiredSchedule.SnapshotTimeOfDay.Seconds),
+				formatTime(actualSchedule.SnapshotTimeOfDay.Hours, actualSchedule.SnapshotTimeOfDay.Minutes, actualSchedule.SnapshotTimeOfDay.Seconds))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".snapshotsPerDay",
+				strconv.Itoa(desiredSchedule.ContinuousSchedule.SnapshotsPerDay), strconv.Itoa(actualSchedule.ContinuousSchedule.SnapshotsPerDay))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".logCatchUpFrequency",
+				strconv.Itoa(desiredSchedule.ContinuousSchedule.LogBackupInterval), strconv.Itoa(actualSchedule.ContinuousSchedule.LogBackupInterval))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".weeklySnapshotDay",
+				desiredSchedule.WeeklySchedule.DayOfWeek, actualSchedule.WeeklySchedule.DayOfWeek)
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".monthlySnapshotDay",
+				strconv.Itoa(desiredSchedule.MonthlySchedule.DayOfMonth), strconv.Itoa(actualSchedule.MonthlySchedule.DayOfMonth))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".quarterlySnapshotMonth",
+				desiredSchedule.QuarterlySchedule.StartMonth, actualSchedule.QuarterlySchedule.StartMonth)
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".yearlySnapshotDay",
+				strconv.Itoa(desiredSchedule.YearlySchedule.DayOfMonth), strconv.Itoa(actualSchedule.YearlySchedule.DayOfMonth))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".yearlySnapshotMonth",
+				desiredSchedule.YearlySchedule.Month, actualSchedule.YearlySchedule.Month)
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".logCatchUpEnabled",
+				strconv.FormatBool(desiredSchedule.ContinuousSchedule.Enabled), strconv.FormatBool(actualSchedule.ContinuousSchedule.Enabled))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".weeklySnapshotEnabled",
+				strconv.FormatBool(desiredSchedule.WeeklySchedule.Enabled), strconv.FormatBool(actualSchedule.WeeklySchedule.Enabled))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".monthlySnapshotEnabled",
+				strconv.FormatBool(desiredSchedule.MonthlySchedule.Enabled), strconv.FormatBool(actualSchedule.MonthlySchedule.Enabled))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".quarterlySnapshotEnabled",
+				strconv.FormatBool(desiredSchedule.QuarterlySchedule.Enabled), strconv.FormatBool(actualSchedule.QuarterlySchedule.Enabled))
+			addDrift(DRIFT_FIELD_TIME_MACHINE+".yearlySnapshotEnabled",
+				strconv.FormatBool(desiredSchedule.YearlySchedule.Enabled), strconv.FormatBool(actualSchedule.YearlySchedule.Enabled))
+		}
+	}
+
+	if actual.dbServer != nil {
+		profileFields := []struct{ property, field string }{
+			{common.PROPERTY_NAME_COMPUTE_PROFILE_ID, DRIFT_FIELD_PROFILES + ".compute"},
+			{common.PROPERTY_NAME_NETWORK_PROFILE_ID, DRIFT_FIELD_PROFILES + ".network"},
+			{common.PROPERTY_NAME_SOFTWARE_PROFILE_ID, DRIFT_FIELD_PROFILES + ".software"},
+		}
+		for _, profileField := range profileFields {
+			for _, property := range actual.dbServer.Properties {
+				if property.Name == profileField.property && desired.profileIds[profileField.property] != "" {
+					addDrift(profileField.field, desired.profileIds[profileField.property], property.Value)
+				}
+			}
+		}
+	}
+	return
+}
+
+// Reapplies the description and the time machine's SLA and schedule on NDB.
+// Returns the drift that remains (the fields that are not supported or failed to be reapplied) and the enforced drift.
+func (r *DatabaseReconciler) enforceDrift(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient,
+	desired desiredDatabaseState, actual actualDatabaseState, drift []ndbv1alpha1.DriftedField) (remaining, enforced []ndbv1alpha1.DriftedField) {
+	log := ctrllog.FromContext(ctx)
+	var databaseDrift, timeMachineDrift []ndbv1alpha1.DriftedField
+	for _, field := range drift {
+		switch {
+		case field.Field == DRIFT_FIELD_DESCRIPTION:
+			databaseDrift = append(databaseDrift, field)
+		case strings.HasPrefix(field.Field, DRIFT_FIELD_TIME_MACHINE+"."):
+			timeMachineDrift = append(timeMachineDrift, field)
+		default:
+			remaining = append(remaining, field)
+		}
+	}
+
+	if len(databaseDrift) > 0 {
+		req := &ndb_api.DatabaseUpdateRequest{
+			Name:             actual.database.Name,
+			Description:      desired.description,
+			ResetDescription: true,
+		}
+		if _, err := ndb_api.UpdateDatabase(ctx, ndbClient, actual.database.Id, req); err != nil {
+			errStatement := "Failed to reapply the description of the database on NDB"
+			log.Error(err, errStatement)
+			r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+			remaining = append(remaining, databaseDrift...)
+		} else {
+			enforced = append(enforced, databaseDrift...)
+		}
+	}
+
+	if len(timeMachineDrift) > 0 {
+		req := &ndb_api.TimeMachineUpdateRequest{
+			Name:          actual.timeMachine.Name,
+			Description:   actual.timeMachine.Description,
+			SlaId:         desired.slaId,
+			Schedule:      *desired.schedule,
+			ResetSlaId:    true,
+			ResetSchedule: true,
+		}
+		if timeMachine, err := ndb_api.UpdateTimeMachine(ctx, ndbClient, actual.timeMachine.Id, req); err != nil {
+			errStatement := "Failed to reapply the SLA and schedule of the time machine on NDB"
+			log.Error(err, errStatement)
+			r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+			remaining = append(remaining, timeMachineDrift...)
+		} else {
+			database.Status.TimeMachine = getTimeMachineStatus(timeMachine, getAppliedTimeMachineSpec(database))
+			enforced = append(enforced, timeMachineDrift...)
+		}
+	}
+
+	if len(enforced) > 0 {
+		log.Info("Reapplied the spec on NDB", "fields", len(enforced))
+		r.recorder.Eventf(database, "Normal", EVENT_DRIFT_ENFORCED, "Reapplied the spec on NDB: %s", describeDrift(enforced))
+	}
+	return
+}
+
+// Returns a description of the drifted fields for the Drifted condition and events
+func describeDrift(drift []ndbv1alpha1.DriftedField) string {
+	descriptions := make([]string, len(drift))
+	for i, field := range drift {
+		descriptions[i] = fmt.Sprintf("%s is %q on NDB instead of %q", field.Field, field.Actual, field.Desired)
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+// Tests the isDriftCheckDue function, tests the following cases:
+// 1. The database has never been compared with NDB
+// 2. The spec changed since the last comparison
+// 3. The last comparison is recent
+// 4. The check interval has elapsed since the last comparison
+func TestIsDriftCheckDue(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	newDatabase := func(observedGeneration int64, checkTime time.Time) *ndbv1alpha1.Database {
+		driftCheckTime := metav1.NewTime(checkTime)
+		return &ndbv1alpha1.Database{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Status: ndbv1alpha1.DatabaseStatus{
+				Conditions:     []metav1.Condition{{Type: common.CONDITION_TYPE_DRIFTED, ObservedGeneration: observedGeneration}},
+				DriftCheckTime: &driftCheckTime,
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		database *ndbv1alpha1.Database
+		want     bool
+	}{
+		{
+			name:     "never compared",
+			database: &ndbv1alpha1.Database{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			want:     true,
+		},
+		{
+			name:     "spec changed",
+			database: newDatabase(1, now.Add(-time.Minute)),
+			want:     true,
+		},
+		{
+			name:     "recent comparison",
+			database: newDatabase(2, now.Add(-time.Minute)),
+			want:     false,
+		},
+		{
+			name:     "interval elapsed",
+			database: newDatabase(2, now.Add(-common.DATABASE_DRIFT_CHECK_INTERVAL_SECONDS*time.Second)),
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDriftCheckDue(tt.database, now); got != tt.want {
+				t.Errorf("isDriftCheckDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Tests the computeDrift function, tests the following cases:
+// 1. NDB matches the spec
+// 2. The description, SLA and schedule differ
+// 3. The profiles reported by the database server differ
+// 4. The size reported by the database differs
+// 5. The size, SLA, schedule and profiles are not compared when NDB does not report them
+func TestComputeDrift(t *testing.T) {
+	schedule := ndb_api.Schedule{
+		SnapshotTimeOfDay:  ndb_api.SnapshotTimeOfDay{Hours: 4},
+		ContinuousSchedule: ndb_api.ContinuousSchedule{Enabled: true, LogBackupInterval: 30, SnapshotsPerDay: 1},
+		WeeklySchedule:     ndb_api.WeeklySchedule{Enabled: true, DayOfWeek: "WEDNESDAY"},
+		MonthlySchedule:    ndb_api.MonthlySchedule{Enabled: true, DayOfMonth: 24},
+		QuarterlySchedule:  ndb_api.QuarterlySchedule{Enabled: true, StartMonth: "JANUARY", DayOfMonth: 24},
+		YearlySchedule:     ndb_api.YearlySchedule{Enabled: false, DayOfMonth: 31, Month: "DECEMBER"},
+	}
+	desired := desiredDatabaseState{
+		description: "description",
+		size:        10,
+		slaId:       "sla-none",
+		slaName:     common.SLA_NAME_NONE,
+		schedule:    &schedule,
+		profileIds: map[string]string{
+			common.PROPERTY_NAME_COMPUTE_PROFILE_ID:  "compute",
+			common.PROPERTY_NAME_NETWORK_PROFILE_ID:  "network",
+			common.PROPERTY_NAME_SOFTWARE_PROFILE_ID: "software",
+		},
+	}
+	matchingTimeMachine := &ndb_api.TimeMachineResponse{
+		Sla: ndb_api.TimeMachineSLA{Id: "sla-none", Name: common.SLA_NAME_NONE},
+		Schedule: ndb_api.TimeMachineSchedule{
+			Id:                 "schedule",
+			SnapshotTimeOfDay:  ndb_api.TimeMachineSnapshotTimeOfDay{Hours: 4},
+			ContinuousSchedule: ndb_api.TimeMachineContinuousSchedule{Enabled: true, LogBackupInterval: 30, SnapshotsPerDay: 1},
+			WeeklySchedule:     ndb_api.TimeMachineWeeklySchedule{Enabled: true, DayOfWeek: "WEDNESDAY"},
+			MonthlySchedule:    ndb_api.TimeMachineMonthlySchedule{Enabled: true, DayOfMonth: 24},
+			QuarterlySchedule:  ndb_api.TimeMachineQuarterlySchedule{Enabled: true, StartMonth: "JANUARY", DayOfMonth: 24},
+			YearlySchedule:     ndb_api.TimeMachineYearlySchedule{Enabled: false, DayOfMonth: 31, Month: "DECEMBER"},
+		},
+	}
+	driftedTimeMachine := *matchingTimeMachine
+	driftedTimeMachine.Sla = ndb_api.TimeMachineSLA{Id: "sla-gold", Name: "DEFAULT_OOB_GOLD_SLA"}
+	driftedTimeMachine.Schedule.SnapshotTimeOfDay.Hours = 6
+	driftedTimeMachine.Schedule.ContinuousSchedule.SnapshotsPerDay = 2
+	driftedTimeMachine.Schedule.YearlySchedule.Enabled = true
+
+	tests := []struct {
+		name   string
+		actual actualDatabaseState
+		want   []ndbv1alpha1.DriftedField
+	}{
+		{
+			name: "in sync",
+			actual: actualDatabaseState{
+				database: &ndb_api.DatabaseResponse{Description: "description", Properties: []ndb_api.Property{
+					{Name: common.PROPERTY_NAME_DATABASE_SIZE, Value: "10"},
+				}},
+				timeMachine: matchingTimeMachine,
+				dbServer: &ndb_api.DatabaseServerResponse{Properties: []ndb_api.Property{
+					{Name: common.PROPERTY_NAME_COMPUTE_PROFILE_ID, Value: "compute"},
+				}},
+			},
+			want: nil,
+		},
+		{
+			name: "description, SLA and schedule drifted",
+			actual: actualDatabaseState{
+				database:    &ndb_api.DatabaseResponse{Description: "changed"},
+				timeMachine: &driftedTimeMachine,
+			},
+			want: []ndbv1alpha1.DriftedField{
+				{Field: DRIFT_FIELD_DESCRIPTION, Desired: "description", Actual: "changed"},
+				{Field: DRIFT_FIELD_TIME_MACHINE + ".sla", Desired: common.SLA_NAME_NONE, Actual: "DEFAULT_OOB_GOLD_SLA"},
+				{Field: DRIFT_FIELD_TIME_MACHINE + ".dailySnapshotTime", Desired: "04:00:00", Actual: "06:00:00"},
+				{Field: DRIFT_FIELD_TIME_MACHINE + ".snapshotsPerDay", Desired: "1", Actual: "2"},
+				{Field: DRIFT_FIELD_TIME_MACHINE + ".yearlySnapshotEnabled", Desired: "false", Actual: "true"},
+			},
+		},
+		{
+			name: "profiles drifted",
+			actual: actualDatabaseState{
+				database: &ndb_api.DatabaseResponse{Description: "description"},
+				dbServer: &ndb_api.DatabaseServerResponse{Properties: []ndb_api.Property{
+					{Name: common.PROPERTY_NAME_COMPUTE_PROFILE_ID, Value: "large-compute"},
+					{Name: common.PROPERTY_NAME_SOFTWARE_PROFILE_ID, Value: "software"},
+				}},
+			},
+			want: []ndbv1alpha1.DriftedField{
+				{Field: DRIFT_FIELD_PROFILES + ".compute", Desired: "compute", Actual: "large-compute"},
+			},
+		},
+		{
+			name: "size drifted",
+			actual: actualDatabaseState{
+				database: &ndb_api.DatabaseResponse{Description: "description", Properties: []ndb_api.Property{
+					{Name: common.PROPERTY_NAME_DATABASE_SIZE, Value: "20"},
+				}},
+			},
+			want: []ndbv1alpha1.DriftedField{
+				{Field: DRIFT_FIELD_SIZE, Desired: "10", Actual: "20"},
+			},
+		},
+		{
+			name: "not reported by NDB",
+			actual: actualDatabaseState{
+				database:    &ndb_api.DatabaseResponse{Description: "description"},
+				timeMachine: &ndb_api.TimeMachineResponse{},
+				dbServer:    &ndb_api.DatabaseServerResponse{},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeDrift(desired, tt.actual); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Tests the checkDrift function for clones, tests the following cases:
+// 1. The Drifted condition of a clone reports that it is not compared with NDB
+// 2. The condition is not updated again for the same generation
+func TestCheckDrift_clone(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = ndbv1alpha1.AddToScheme(scheme)
+	database := &ndbv1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{Name: "clone", Namespace: "default", Generation: 1},
+		Spec:       ndbv1alpha1.DatabaseSpec{IsClone: true, Clone: &ndbv1alpha1.Clone{Name: "clone"}},
+		Status:     ndbv1alpha1.DatabaseStatus{Id: "clone-id"},
+	}
+	r := &DatabaseReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(database).WithStatusSubresource(database).Build(),
+		Scheme:   scheme,
+		recorder: record.NewFakeRecorder(1),
+	}
+
+	// 1. The Drifted condition of a clone reports that it is not compared with NDB
+	if err := r.checkDrift(context.TODO(), database, nil); err != nil {
+		t.Fatalf("checkDrift() error = %v", err)
+	}
+	updated := &ndbv1alpha1.Database{}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(database), updated); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	condition := meta.FindStatusCondition(updated.Status.Conditions, common.CONDITION_TYPE_DRIFTED)
+	if condition == nil || condition.Status != metav1.ConditionUnknown || condition.Reason != common.CONDITION_REASON_DRIFT_CHECK_NOT_SUPPORTED {
+		t.Fatalf("Drifted condition = %v, want %s", condition, common.CONDITION_REASON_DRIFT_CHECK_NOT_SUPPORTED)
+	}
+
+	// 2. The condition is not updated again for the same generation
+	if err := r.checkDrift(context.TODO(), updated, nil); err != nil {
+		t.Fatalf("checkDrift() error = %v", err)
+	}
+	again := &ndbv1alpha1.Database{}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(database), again); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if again.ResourceVersion != updated.ResourceVersion {
+		t.Errorf("resourceVersion = %s, want the status not to be updated again (%s)", again.ResourceVersion, updated.ResourceVersion)
+	}
+}
@@ -22,7 +22,7 @@ func getInstanceManager(database ndbv1alpha1.Database) (instanceManager Instance
 }
 
 type InstanceManager interface {
-	create(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database, namespace string) (task *ndb_api.TaskInfoSummaryResponse, err error)
+	create(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database, ndbServer *ndbv1alpha1.NDBServer, namespace string) (task *ndb_api.TaskInfoSummaryResponse, err error)
 	deregister(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database) (task *ndb_api.TaskInfoSummaryResponse, err error)
 	deleteDatabaseServer(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database) (task *ndb_api.TaskInfoSummaryResponse, err error)
 }
@@ -31,7 +31,7 @@ type DatabaseManager struct{}
 
 type CloneManager struct{}
 
-func (dm *DatabaseManager) create(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database, namespace string) (taskResponse *ndb_api.TaskInfoSummaryResponse, err error) {
+func (dm *DatabaseManager) create(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database, ndbServer *ndbv1alpha1.NDBServer, namespace string) (taskResponse *ndb_api.TaskInfoSummaryResponse, err error) {
 	log := ctrllog.FromContext(ctx)
 	log.Info("Provisioning a database on NDB")
 	dbPassword, sshPublicKey, err := r.getDatabaseCredentials(ctx, database.Spec.Instance.CredentialSecret, namespace)
@@ -48,9 +48,13 @@ func (dm *DatabaseManager) create(ctx context.Context, r *DatabaseReconciler, nd
 		return
 	}
 
+	tags, timeMachineTags, dbServerTags := r.getCreationTags(ctx, ndbClient, database, ndbServer)
 	reqData := map[string]interface{}{
-		common.NDB_PARAM_PASSWORD:       dbPassword,
-		common.NDB_PARAM_SSH_PUBLIC_KEY: sshPublicKey,
+		common.NDB_PARAM_PASSWORD:             dbPassword,
+		common.NDB_PARAM_SSH_PUBLIC_KEY:       sshPublicKey,
+		common.NDB_PARAM_TAGS:                 tags,
+		common.NDB_PARAM_TIME_MACHINE_TAGS:    timeMachineTags,
+		common.NDB_PARAM_DATABASE_SERVER_TAGS: dbServerTags,
 	}
 
 	databaseAdapter := &controller_adapters.Database{Database: *database}
@@ -91,7 +95,7 @@ func (dm *DatabaseManager) deleteDatabaseServer(ctx context.Context, r *Database
 	return deleteDatabaseServer(ctx, r, ndbClient, database)
 }
 
-func (cm *CloneManager) create(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database, namespace string) (taskResponse *ndb_api.TaskInfoSummaryResponse, err error) {
+func (cm *CloneManager) create(ctx context.Context, r *DatabaseReconciler, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database, ndbServer *ndbv1alpha1.NDBServer, namespace string) (taskResponse *ndb_api.TaskInfoSummaryResponse, err error) {
 	log := ctrllog.FromContext(ctx)
 	log.Info("Cloning a database on NDB")
 	databaseAdapter := &controller_adapters.Database{Database: *database}
@@ -109,9 +113,13 @@ func (cm *CloneManager) create(ctx context.Context, r *DatabaseReconciler, ndbCl
 		return
 	}
 
+	tags, timeMachineTags, dbServerTags := r.getCreationTags(ctx, ndbClient, database, ndbServer)
 	reqData := map[string]interface{}{
-		common.NDB_PARAM_PASSWORD:       dbPassword,
-		common.NDB_PARAM_SSH_PUBLIC_KEY: sshPublicKey,
+		common.NDB_PARAM_PASSWORD:             dbPassword,
+		common.NDB_PARAM_SSH_PUBLIC_KEY:       sshPublicKey,
+		common.NDB_PARAM_TAGS:                 tags,
+		common.NDB_PARAM_TIME_MACHINE_TAGS:    timeMachineTags,
+		common.NDB_PARAM_DATABASE_SERVER_TAGS: dbServerTags,
 	}
 
 	generatedReq, err := ndb_api.GenerateCloningRequest(ctx, ndbClient, databaseAdapter, reqData)
@@ -0,0 +1,175 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	OPERATION_TYPE_CLONE      = "clone"
+	OPERATION_TYPE_DEREGISTER = "deregister"
+	OPERATION_TYPE_PROVISION  = "provision"
+
+	// Time allowed for listing the custom resources when the metrics are scraped
+	METRICS_COLLECTION_TIMEOUT = 10 * time.Second
+)
+
+var (
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ndb_operation_duration_seconds",
+		Help:    "Duration of the completed NDB operations started by the operator by type (provision, clone, deregister) and outcome (passed, failed)",
+		Buckets: []float64{60, 120, 300, 600, 900, 1200, 1800, 2700, 3600, 5400, 7200},
+	}, []string{"type", "outcome"})
+	creatingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ndb_database_creating_seconds",
+		Help:    "Time the databases spent in the CREATING status by engine and outcome (passed, failed)",
+		Buckets: []float64{60, 120, 300, 600, 900, 1200, 1800, 2700, 3600, 5400, 7200},
+	}, []string{"engine", "outcome"})
+
+	databasesDesc = prometheus.NewDesc(
+		"ndb_databases",
+		"Number of Database custom resources by status and engine",
+		[]string{"status", "engine"}, nil,
+	)
+	ndbServerReachableDesc = prometheus.NewDesc(
+		"ndb_server_reachable",
+		"1 if the last request of the operator to the NDB server of the NDBServer custom resource reached NDB and was authenticated, 0 otherwise",
+		[]string{"namespace", "name"}, nil,
+	)
+	ndbServerOrphansDesc = prometheus.NewDesc(
+		"ndb_orphaned_entities",
+		"Number of databases, clones and database servers created on NDB by the operator without a Database custom resource, as of the last orphan scan of the NDBServer custom resource",
+		[]string{"namespace", "name"}, nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(operationDuration, creatingDuration)
+}
+
+// Returns the engine label of a database, the type of the instance or the clone
+func getDatabaseEngine(database *ndbv1alpha1.Database) string {
+	if database.Spec.IsClone {
+		if database.Spec.Clone != nil {
+			return database.Spec.Clone.Type
+		}
+	} else if database.Spec.Instance != nil {
+		return database.Spec.Instance.Type
+	}
+	return ""
+}
+
+// Returns the outcome label of a completed operation
+func getOperationOutcome(operation *ndb_api.OperationResponse) string {
+	if ndb_api.GetOperationStatus(operation) == ndb_api.OPERATION_STATUS_PASSED {
+		return "passed"
+	}
+	return "failed"
+}
+
+// Records the duration of a completed NDB operation, skipped if NDB did not return its start and end times
+func observeOperation(operationType string, operation *ndb_api.OperationResponse) {
+	if duration, err := ndb_api.GetOperationDuration(operation); err == nil {
+		operationDuration.WithLabelValues(operationType, getOperationOutcome(operation)).Observe(duration.Seconds())
+	}
+}
+
+// Records the duration of the completed creation operation of a database and the time it spent in CREATING
+func observeCreationOperation(database *ndbv1alpha1.Database, operation *ndb_api.OperationResponse) {
+	operationType := OPERATION_TYPE_PROVISION
+	if database.Spec.IsClone {
+		operationType = OPERATION_TYPE_CLONE
+	}
+	engine := getDatabaseEngine(database)
+	observeOperation(operationType, operation)
+	// The database is CREATING from the start of the creation operation until the operator observes its completion
+	if startTime, err := ndb_api.GetOperationStartTime(operation); err == nil {
+		creatingDuration.WithLabelValues(engine, getOperationOutcome(operation)).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// Collects the gauges derived from the Database and NDBServer custom resources when the metrics are scraped
+type resourceCollector struct {
+	reader     client.Reader
+	ndbClients *NDBClientManager
+}
+
+// Returns a collector of the gauges of the Database and NDBServer custom resources, to be registered
+// in the controller-runtime metrics registry. The reader should be backed by the manager's cache,
+// the reachability of the NDB servers is read from the outcome of the last request of their clients.
+func NewResourceCollector(reader client.Reader, ndbClients *NDBClientManager) prometheus.Collector {
+	return &resourceCollector{reader: reader, ndbClients: ndbClients}
+}
+
+func (c *resourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- databasesDesc
+	ch <- ndbServerReachableDesc
+	ch <- ndbServerOrphansDesc
+}
+
+func (c *resourceCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), METRICS_COLLECTION_TIMEOUT)
+	defer cancel()
+
+	databases := &ndbv1alpha1.DatabaseList{}
+	if err := c.reader.List(ctx, databases); err != nil {
+		ch <- prometheus.NewInvalidMetric(databasesDesc, err)
+	} else {
+		type key struct{ status, engine string }
+		counts := make(map[key]int)
+		for _, database := range databases.Items {
+			counts[key{status: database.Status.Status, engine: getDatabaseEngine(&database)}]++
+		}
+		for k, count := range counts {
+			ch <- prometheus.MustNewConstMetric(databasesDesc, prometheus.GaugeValue, float64(count), k.status, k.engine)
+		}
+	}
+
+	ndbServers := &ndbv1alpha1.NDBServerList{}
+	if err := c.reader.List(ctx, ndbServers); err != nil {
+		ch <- prometheus.NewInvalidMetric(ndbServerReachableDesc, err)
+		ch <- prometheus.NewInvalidMetric(ndbServerOrphansDesc, err)
+	} else {
+		for _, ndbServer := range ndbServers.Items {
+			reachable := 0.0
+			if c.isReachable(&ndbServer) {
+				reachable = 1
+			}
+			ch <- prometheus.MustNewConstMetric(ndbServerReachableDesc, prometheus.GaugeValue, reachable, ndbServer.Namespace, ndbServer.Name)
+			ch <- prometheus.MustNewConstMetric(ndbServerOrphansDesc, prometheus.GaugeValue, float64(len(ndbServer.Status.Orphans)), ndbServer.Namespace, ndbServer.Name)
+		}
+	}
+}
+
+// Returns true if the last request to the NDB server of the NDBServer succeeded.
+// Falls back to the status of the NDBServer until its client has sent a request.
+func (c *resourceCollector) isReachable(ndbServer *ndbv1alpha1.NDBServer) bool {
+	if reachable, ok := c.ndbClients.IsReachable(types.NamespacedName{Namespace: ndbServer.Namespace, Name: ndbServer.Name}); ok {
+		return reachable
+	}
+	return ndbServer.Status.Status == common.NDB_CR_STATUS_OK
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// Tests the resourceCollector, tests the following cases:
+//  1. Databases are counted by status and engine (the engine of clones is the clone type)
+//  2. NDBServers are reachable if the last request of their client succeeded,
+//     regardless of their status (ndb-error with an Ok client, ndb-failing with a failed request)
+//  3. NDBServers without a client are reachable only with the Ok status
+//  4. Orphans are counted by NDBServer
+func TestResourceCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = ndbv1alpha1.AddToScheme(scheme)
+	getDatabase := func(name, status string, spec ndbv1alpha1.DatabaseSpec) *ndbv1alpha1.Database {
+		return &ndbv1alpha1.Database{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       spec,
+			Status:     ndbv1alpha1.DatabaseStatus{Status: status},
+		}
+	}
+	instance := ndbv1alpha1.DatabaseSpec{Instance: &ndbv1alpha1.Instance{Type: common.DATABASE_TYPE_POSTGRES}}
+	clone := ndbv1alpha1.DatabaseSpec{IsClone: true, Clone: &ndbv1alpha1.Clone{Type: common.DATABASE_TYPE_MYSQL}}
+	objects := []client.Object{
+		getDatabase("db-1", common.DATABASE_CR_STATUS_READY, instance),
+		getDatabase("db-2", common.DATABASE_CR_STATUS_READY, instance),
+		getDatabase("db-3", common.DATABASE_CR_STATUS_CREATING, instance),
+		getDatabase("clone-1", common.DATABASE_CR_STATUS_READY, clone),
+		&ndbv1alpha1.NDBServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "ndb-ok", Namespace: "default"},
+			Status: ndbv1alpha1.NDBServerStatus{
+				Status:  common.NDB_CR_STATUS_OK,
+				Orphans: []ndbv1alpha1.NDBServerOrphan{{Id: "orphan-1"}, {Id: "orphan-2"}},
+			},
+		},
+		&ndbv1alpha1.NDBServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "ndb-error", Namespace: "default"},
+			Status:     ndbv1alpha1.NDBServerStatus{Status: common.NDB_CR_STATUS_ERROR},
+		},
+		&ndbv1alpha1.NDBServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "ndb-failing", Namespace: "default"},
+			Status:     ndbv1alpha1.NDBServerStatus{Status: common.NDB_CR_STATUS_OK},
+		},
+		&ndbv1alpha1.NDBServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "ndb-unused", Namespace: "default"},
+			Status:     ndbv1alpha1.NDBServerStatus{Status: common.NDB_CR_STATUS_ERROR},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+	// ndb-error reaches NDB (the failure is elsewhere, e.g. the orphan scan), ndb-failing does not
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/failing") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	ndbClients := NewNDBClientManager(c)
+	for name, url := range map[string]string{"ndb-error": server.URL, "ndb-failing": server.URL + "/failing"} {
+		options := ndb_client.DefaultClientOptions()
+		options.MaxRetries = 0
+		ndbClient, _ := ndbClients.pool.Get("default/"+name, "", func() (*ndb_client.NDBClient, error) {
+			return ndb_client.NewNDBClientWithAuthMethod(common.AUTH_METHOD_BASIC, "username", "password", url, "", true, options), nil
+		})
+		ndb_api.GetAllClusters(context.TODO(), ndbClient)
+	}
+
+	expected := `
+# HELP ndb_databases Number of Database custom resources by status and engine
+# TYPE ndb_databases gauge
+ndb_databases{engine="mysql",status="READY"} 1
+ndb_databases{engine="postgres",status="CREATING"} 1
+ndb_databases{engine="postgres",status="READY"} 2
+# HELP ndb_orphaned_entities Number of databases, clones and database servers created on NDB by the operator without a Database custom resource, as of the last orphan scan of the NDBServer custom resource
+# TYPE ndb_orphaned_entities gauge
+ndb_orphaned_entities{name="ndb-error",namespace="default"} 0
+ndb_orphaned_entities{name="ndb-failing",namespace="default"} 0
+ndb_orphaned_entities{name="ndb-ok",namespace="default"} 2
+ndb_orphaned_entities{name="ndb-unused",namespace="default"} 0
+# HELP ndb_server_reachable 1 if the last request of the operator to the NDB server of the NDBServer custom resource reached NDB and was authenticated, 0 otherwise
+# TYPE ndb_server_reachable gauge
+ndb_server_reachable{name="ndb-error",namespace="default"} 1
+ndb_server_reachable{name="ndb-failing",namespace="default"} 0
+ndb_server_reachable{name="ndb-ok",namespace="default"} 1
+ndb_server_reachable{name="ndb-unused",namespace="default"} 0
+`
+	if err := testutil.CollectAndCompare(NewResourceCollector(c, ndbClients), strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
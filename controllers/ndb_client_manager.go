@@ -0,0 +1,123 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// NDBClientManager keeps one NDBClient per NDBServer, shared by the controllers.
+// A client is recreated when the NDBServer spec or its credential secret changes.
+type NDBClientManager struct {
+	client client.Client
+	pool   *ndb_client.ClientPool
+}
+
+func NewNDBClientManager(k8sClient client.Client) *NDBClientManager {
+	return &NDBClientManager{
+		client: k8sClient,
+		pool:   ndb_client.NewClientPool(),
+	}
+}
+
+// Returns the NDBClient for the NDBServer.
+// Returns an error if reading the secret containing the credentials fails.
+func (m *NDBClientManager) GetNDBClient(ctx context.Context, ndbServer *ndbv1alpha1.NDBServer) (*ndb_client.NDBClient, error) {
+	log := ctrllog.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	secretName := types.NamespacedName{Namespace: ndbServer.Namespace, Name: ndbServer.Spec.CredentialSecret}
+	if err := m.client.Get(ctx, secretName, secret); err != nil {
+		log.Error(err, "Error occured while fetching NDB secret", "Secret Name", secretName.Name, "Namespace", secretName.Namespace)
+		return nil, err
+	}
+
+	key := types.NamespacedName{Namespace: ndbServer.Namespace, Name: ndbServer.Name}.String()
+	fingerprint := strings.Join([]string{
+		string(ndbServer.UID),
+		ndbServer.Spec.Server,
+		ndbServer.Spec.AuthMethod,
+		strconv.FormatBool(ndbServer.Spec.SkipCertificateVerification),
+		secret.Name,
+		secret.ResourceVersion,
+		fmt.Sprintf("%+v", getNDBClientOptions(ndbServer)),
+	}, "|")
+	return m.pool.Get(key, fingerprint, func() (*ndb_client.NDBClient, error) {
+		log.Info("Creating NDB client", "NDBServer", key)
+		username, password, caCert, err := getNDBCredentialsFromSecret(ctx, m.client, secretName.Name, secretName.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if caCert == "" {
+			log.Info("Ca-cert not found, falling back to host's HTTPs certs.")
+		}
+		spec := ndbServer.Spec
+		return ndb_client.NewNDBClientWithAuthMethod(spec.AuthMethod, username, password, spec.Server, caCert, spec.SkipCertificateVerification, getNDBClientOptions(ndbServer)), nil
+	})
+}
+
+// Returns true if the last request sent to NDB by the client of the NDBServer succeeded (see NDBClient.LastRequestSucceeded).
+// ok is false if the NDBServer has no client or its client has not sent a request yet.
+func (m *NDBClientManager) IsReachable(ndbServerName types.NamespacedName) (reachable, ok bool) {
+	ndbClient, ok := m.pool.Lookup(ndbServerName.String())
+	if !ok {
+		return false, false
+	}
+	return ndbClient.LastRequestSucceeded()
+}
+
+// Discards the NDBClient of a deleted NDBServer
+func (m *NDBClientManager) RemoveNDBClient(ndbServerName types.NamespacedName) {
+	m.pool.Remove(ndbServerName.String())
+}
+
+// Returns the options of the NDB client for the NDBServer, unset fields take the defaults
+func getNDBClientOptions(ndbServer *ndbv1alpha1.NDBServer) ndb_client.ClientOptions {
+	options := ndb_client.DefaultClientOptions()
+	clientOptions := ndbServer.Spec.ClientOptions
+	if clientOptions == nil {
+		return options
+	}
+	if clientOptions.TimeoutSeconds > 0 {
+		options.RequestTimeout = time.Duration(clientOptions.TimeoutSeconds) * time.Second
+	}
+	if clientOptions.MaxRetries != nil {
+		options.MaxRetries = *clientOptions.MaxRetries
+	}
+	if clientOptions.RequestsPerSecond > 0 {
+		options.RequestsPerSecond = float64(clientOptions.RequestsPerSecond)
+	}
+	if clientOptions.Burst > 0 {
+		options.Burst = clientOptions.Burst
+	}
+	if clientOptions.MaxInFlight > 0 {
+		options.MaxInFlight = clientOptions.MaxInFlight
+	}
+	return options
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+func TestNDBClientManager_GetNDBClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = ndbv1alpha1.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ndb-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			common.SECRET_DATA_KEY_USERNAME: []byte("username"),
+			common.SECRET_DATA_KEY_PASSWORD: []byte("password"),
+		},
+	}
+	ndbServer := &ndbv1alpha1.NDBServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "ndb", Namespace: "default"},
+		Spec: ndbv1alpha1.NDBServerSpec{
+			Server:           "https://10.10.10.10:8443/era/v0.9",
+			CredentialSecret: "ndb-secret",
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	manager := NewNDBClientManager(k8sClient)
+	ctx := context.TODO()
+
+	first, err := manager.GetNDBClient(ctx, ndbServer)
+	if err != nil {
+		t.Fatalf("GetNDBClient() error = %v", err)
+	}
+	second, _ := manager.GetNDBClient(ctx, ndbServer)
+	if first != second {
+		t.Errorf("expected the client to be reused while the NDBServer and secret are unchanged")
+	}
+
+	// Updating the secret invalidates the client
+	secret.Data[common.SECRET_DATA_KEY_PASSWORD] = []byte("new-password")
+	if err = k8sClient.Update(ctx, secret); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	third, _ := manager.GetNDBClient(ctx, ndbServer)
+	if third == second {
+		t.Errorf("expected a new client after the secret was updated")
+	}
+
+	// Updating the spec invalidates the client
+	ndbServer.Spec.SkipCertificateVerification = true
+	fourth, _ := manager.GetNDBClient(ctx, ndbServer)
+	if fourth == third {
+		t.Errorf("expected a new client after the spec was updated")
+	}
+
+	manager.RemoveNDBClient(types.NamespacedName{Namespace: "default", Name: "ndb"})
+	if manager.pool.Len() != 0 {
+		t.Errorf("expected the client to be removed")
+	}
+
+	// Missing secret
+	ndbServer.Spec.CredentialSecret = "does-not-exist"
+	if _, err = manager.GetNDBClient(ctx, ndbServer); err == nil {
+		t.Errorf("expected an error for a missing secret")
+	}
+}
+
+func TestGetNDBClientOptions(t *testing.T) {
+	zero := 0
+	ndbServer := &ndbv1alpha1.NDBServer{}
+	if got := getNDBClientOptions(ndbServer); got != ndb_client.DefaultClientOptions() {
+		t.Errorf("getNDBClientOptions() = %+v, want the defaults %+v", got, ndb_client.DefaultClientOptions())
+	}
+
+	ndbServer.Spec.ClientOptions = &ndbv1alpha1.NDBServerClientOptions{TimeoutSeconds: 10, MaxRetries: &zero}
+	got := getNDBClientOptions(ndbServer)
+	if got.RequestTimeout != 10*time.Second || got.MaxRetries != 0 {
+		t.Errorf("getNDBClientOptions() = %+v, want a 10s timeout and no retries", got)
+	}
+	if got.RetryBackoff != ndb_client.DEFAULT_RETRY_BACKOFF {
+		t.Errorf("getNDBClientOptions() RetryBackoff = %v, want the default %v", got.RetryBackoff, ndb_client.DEFAULT_RETRY_BACKOFF)
+	}
+}
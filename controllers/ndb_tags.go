@@ -0,0 +1,251 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Returns the NDB tags (name: value) of the database or clone, its time machine and database server. In order of
+// precedence: the tags identifying the Database custom resource, the tags of the spec, the annotations prefixed with
+// tags.ndb.nutanix.com/ and the labels listed in the tagLabels of the NDBServer.
+func getDesiredTags(database *ndbv1alpha1.Database, ndbServer *ndbv1alpha1.NDBServer, clusterId string) map[string]string {
+	tags := make(map[string]string)
+	for label, name := range ndbServer.Spec.TagLabels {
+		if value, found := database.Labels[label]; found && name != "" {
+			tags[name] = value
+		}
+	}
+	for key, value := range database.Annotations {
+		if name, found := strings.CutPrefix(key, common.TAG_ANNOTATION_PREFIX); found && name != "" {
+			tags[name] = value
+		}
+	}
+	for name, value := range database.Spec.Tags {
+		tags[name] = value
+	}
+	tags[common.NDB_TAG_NAME_CR_NAME] = database.Name
+	tags[common.NDB_TAG_NAME_CR_NAMESPACE] = database.Namespace
+	tags[common.NDB_TAG_NAME_CR_UID] = string(database.UID)
+	if clusterId != "" {
+		tags[common.NDB_TAG_NAME_CLUSTER_ID] = clusterId
+	}
+	return tags
+}
+
+// Resolves the ids of the tags defined on NDB for the entity type. The tags that are not defined (or not enabled)
+// on NDB are skipped, NDB rejects the requests with undefined tags.
+func resolveTags(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, entityType string, values map[string]string) ([]ndb_api.Tag, error) {
+	definedTags, err := ndb_api.GetTagsForEntityType(ctx, ndbClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+	tags, undefined := ndb_api.ResolveTags(definedTags, values)
+	if len(undefined) > 0 {
+		ctrllog.FromContext(ctx).Info("Tags not defined on NDB are skipped", "entityType", entityType, "tags", undefined)
+	}
+	tags, untracked := withoutUntrackedClusterTag(tags)
+	if untracked {
+		ctrllog.FromContext(ctx).Info("The cluster id tag is skipped without the UID tag", "entityType", entityType)
+	}
+	return tags, nil
+}
+
+// Removes the cluster id tag from the tags without the UID tag. The orphan scan looks up the Database of the entities
+// tagged with the cluster id by their UID tag, the cluster id tag is only applied along with the UID tag.
+// Returns true if the cluster id tag was removed.
+func withoutUntrackedClusterTag(tags []ndb_api.Tag) (tracked []ndb_api.Tag, untracked bool) {
+	if _, found := ndb_api.GetTagValue(tags, common.NDB_TAG_NAME_CR_UID); found {
+		return tags, false
+	}
+	for _, tag := range tags {
+		if tag.TagName == common.NDB_TAG_NAME_CLUSTER_ID {
+			untracked = true
+			continue
+		}
+		tracked = append(tracked, tag)
+	}
+	return
+}
+
+// Returns the tags of an entity on NDB with the desired tags applied: the desired tags are set, the tags previously
+// applied by the operator that are no longer desired are removed and the other tags of the entity are kept.
+// changed is false if the tags of the entity are already as desired.
+func mergeTags(actual, desired []ndb_api.Tag, previous map[string]string) (merged []ndb_api.Tag, changed bool) {
+	desiredValues := make(map[string]string, len(desired))
+	for _, tag := range desired {
+		desiredValues[tag.TagName] = tag.Value
+	}
+	actualValues := make(map[string]string, len(actual))
+	for _, tag := range actual {
+		actualValues[tag.TagName] = tag.Value
+		if _, isDesired := desiredValues[tag.TagName]; isDesired {
+			continue
+		}
+		if _, wasApplied := previous[tag.TagName]; wasApplied {
+			changed = true
+			continue
+		}
+		merged = append(merged, tag)
+	}
+	for _, tag := range desired {
+		if value, found := actualValues[tag.TagName]; !found || value != tag.Value {
+			changed = true
+		}
+		merged = append(merged, tag)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].TagName < merged[j].TagName })
+	return
+}
+
+// Resolves the desired tags for the database or clone, its time machine and database server (by entity type).
+// The tags not defined on NDB for an entity type are skipped and returned in undefined, along with the cluster id
+// tag of the entity types without the UID tag. applied holds the tags (name: value) resolved for at least one of
+// the entity types.
+func resolveEntityTags(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, database *ndbv1alpha1.Database, desired map[string]string) (tags map[string][]ndb_api.Tag, applied map[string]string, undefined map[string][]string, err error) {
+	_, databaseEntityType := getNDBEntity(database)
+	tags = make(map[string][]ndb_api.Tag)
+	for _, entityType := range []string{databaseEntityType, common.TAG_ENTITY_TYPE_TIME_MACHINE, common.TAG_ENTITY_TYPE_DATABASE_SERVER} {
+		var definedTags []ndb_api.TagResponse
+		if definedTags, err = ndb_api.GetTagsForEntityType(ctx, ndbClient, entityType); err != nil {
+			return nil, nil, nil, err
+		}
+		resolved, undefinedNames := ndb_api.ResolveTags(definedTags, desired)
+		resolved, untracked := withoutUntrackedClusterTag(resolved)
+		if untracked {
+			undefinedNames = append(undefinedNames, common.NDB_TAG_NAME_CLUSTER_ID)
+			sort.Strings(undefinedNames)
+		}
+		tags[entityType] = resolved
+		for _, tag := range resolved {
+			if applied == nil {
+				applied = make(map[string]string)
+			}
+			applied[tag.TagName] = tag.Value
+		}
+		if len(undefinedNames) > 0 {
+			if undefined == nil {
+				undefined = make(map[string][]string)
+			}
+			undefined[entityType] = undefinedNames
+		}
+	}
+	return
+}
+
+// Keeps the tags of the database or clone, its time machine and database server on NDB in sync with the labels,
+// annotations and spec of the Database. The tags are applied on NDB, once the database server is known, when the
+// tags resolved on NDB differ from the tags last applied (status.tags). The tags not defined on NDB are recorded in
+// status.undefinedTags, they are resolved again on each check and applied once they are defined. The other tags of
+// the entities on NDB are kept.
+func (r *DatabaseReconciler) syncTags(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient, ndbServer *ndbv1alpha1.NDBServer) error {
+	log := ctrllog.FromContext(ctx)
+	if database.Status.Id == "" || database.Status.DatabaseServerId == "" {
+		return nil
+	}
+	desired := getDesiredTags(database, ndbServer, r.ClusterId)
+	tags, applied, undefined, err := resolveEntityTags(ctx, ndbClient, database, desired)
+	if err == nil {
+		if reflect.DeepEqual(applied, database.Status.Tags) && reflect.DeepEqual(undefined, database.Status.UndefinedTags) {
+			return nil
+		}
+		log.Info("Applying the tags on NDB", "id", database.Status.Id)
+		if len(undefined) > 0 {
+			log.Info("Tags not defined on NDB are skipped", "tags", undefined)
+		}
+		err = applyTags(ctx, ndbClient, database, tags)
+	}
+	if ndb_api.IsConflict(err) {
+		// The tags are merged with the tags read from NDB again on the retry
+		log.Info("The tagged entities are being changed on NDB, the tags are applied again", "id", database.Status.Id, "error", err.Error())
+		return err
+	}
+	if err != nil {
+		errStatement := "Failed to apply the tags on NDB"
+		log.Error(err, errStatement)
+		r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+		return err
+	}
+	r.recorder.Event(database, "Normal", EVENT_TAGS_APPLIED, "Applied the tags on NDB")
+	database.Status.Tags = applied
+	database.Status.UndefinedTags = undefined
+	if err := r.Status().Update(ctx, database); err != nil {
+		errStatement := "Failed to update status of database custom resource"
+		log.Error(err, errStatement)
+		r.recorder.Eventf(database, "Warning", EVENT_CR_STATUS_UPDATE_FAILED, "Error: %s. %s.", errStatement, err.Error())
+		return err
+	}
+	return nil
+}
+
+// Applies the resolved tags (by entity type) to the database or clone, its time machine and database server on NDB.
+// The entities are only updated if their tags change.
+func applyTags(ctx context.Context, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database, tags map[string][]ndb_api.Tag) error {
+	previous := database.Status.Tags
+	entity, err := getNDBDatabase(ctx, ndbClient, database)
+	if err != nil {
+		return err
+	}
+	_, entityType := getNDBEntity(database)
+	if merged, changed := mergeTags(entity.Tags, tags[entityType], previous); changed {
+		req := &ndb_api.DatabaseUpdateRequest{Name: entity.Name, Description: entity.Description, Tags: merged, ResetTags: true}
+		if database.Spec.IsClone {
+			_, err = ndb_api.UpdateClone(ctx, ndbClient, entity.Id, req)
+		} else {
+			_, err = ndb_api.UpdateDatabase(ctx, ndbClient, entity.Id, req)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if entity.TimeMachineId != "" {
+		timeMachine, err := ndb_api.GetTimeMachineById(ctx, ndbClient, entity.TimeMachineId)
+		if err != nil {
+			return err
+		}
+		if merged, changed := mergeTags(timeMachine.Tags, tags[common.TAG_ENTITY_TYPE_TIME_MACHINE], previous); changed {
+			req := &ndb_api.TimeMachineUpdateRequest{Name: timeMachine.Name, Description: timeMachine.Description, Tags: merged, ResetTags: true}
+			if _, err = ndb_api.UpdateTimeMachine(ctx, ndbClient, timeMachine.Id, req); err != nil {
+				return err
+			}
+		}
+	}
+
+	if database.Status.DatabaseServerId != "" {
+		dbServer, err := ndb_api.GetDatabaseServerById(ctx, ndbClient, database.Status.DatabaseServerId)
+		if err != nil {
+			return err
+		}
+		if merged, changed := mergeTags(dbServer.Tags, tags[common.TAG_ENTITY_TYPE_DATABASE_SERVER], previous); changed {
+			req := &ndb_api.DatabaseServerUpdateRequest{Name: dbServer.Name, Tags: merged, ResetTags: true}
+			if _, err = ndb_api.UpdateDatabaseServer(ctx, ndbClient, dbServer.Id, req); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
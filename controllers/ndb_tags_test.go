@@ -0,0 +1,141 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+// Tests the getDesiredTags function, tests the following cases:
+// 1. Only the labels listed in the tagLabels of the NDBServer are propagated, under the tag name
+// 2. The annotations prefixed with tags.ndb.nutanix.com/ are propagated
+// 3. The tags of the spec take precedence over the labels and annotations
+// 4. The tags identifying the Database take precedence over all the others
+func TestGetDesiredTags(t *testing.T) {
+	database := &ndbv1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: "ns",
+			UID:       "uid",
+			Labels:    map[string]string{"team": "dba", "example.com/cost-center": "42", "app": "unlisted"},
+			Annotations: map[string]string{
+				common.TAG_ANNOTATION_PREFIX + "owner":                    "annotation",
+				common.TAG_ANNOTATION_PREFIX + "team":                     "annotation",
+				common.TAG_ANNOTATION_PREFIX + common.NDB_TAG_NAME_CR_UID: "overridden",
+				"unrelated": "value",
+			},
+		},
+		Spec: ndbv1alpha1.DatabaseSpec{Tags: map[string]string{"owner": "spec"}},
+	}
+	ndbServer := &ndbv1alpha1.NDBServer{Spec: ndbv1alpha1.NDBServerSpec{
+		TagLabels: map[string]string{"team": "team", "example.com/cost-center": "cost-center"},
+	}}
+
+	tags := getDesiredTags(database, ndbServer, "cluster")
+
+	expected := map[string]string{
+		"team":                           "annotation",
+		"cost-center":                    "42",
+		"owner":                          "spec",
+		common.NDB_TAG_NAME_CR_NAME:      "db",
+		common.NDB_TAG_NAME_CR_NAMESPACE: "ns",
+		common.NDB_TAG_NAME_CR_UID:       "uid",
+		common.NDB_TAG_NAME_CLUSTER_ID:   "cluster",
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("getDesiredTags() = %v, want %v", tags, expected)
+	}
+}
+
+// Tests the mergeTags function, tests the following cases:
+// 1. The tags of the entity are already as desired
+// 2. A desired tag is added and another one changes value
+// 3. A tag previously applied and no longer desired is removed, the tags not applied by the operator are kept
+func TestMergeTags(t *testing.T) {
+	team := ndb_api.Tag{TagId: "1", TagName: "team", Value: "dba"}
+	owner := ndb_api.Tag{TagId: "2", TagName: "owner", Value: "someone"}
+	manual := ndb_api.Tag{TagId: "3", TagName: "manual", Value: "ui"}
+	tests := []struct {
+		name        string
+		actual      []ndb_api.Tag
+		desired     []ndb_api.Tag
+		previous    map[string]string
+		wantMerged  []ndb_api.Tag
+		wantChanged bool
+	}{
+		{
+			name:        "1. unchanged",
+			actual:      []ndb_api.Tag{manual, team},
+			desired:     []ndb_api.Tag{team},
+			previous:    map[string]string{"team": "dba"},
+			wantMerged:  []ndb_api.Tag{manual, team},
+			wantChanged: false,
+		},
+		{
+			name:        "2. added and changed",
+			actual:      []ndb_api.Tag{{TagId: "1", TagName: "team", Value: "old"}},
+			desired:     []ndb_api.Tag{owner, team},
+			previous:    map[string]string{"team": "old"},
+			wantMerged:  []ndb_api.Tag{owner, team},
+			wantChanged: true,
+		},
+		{
+			name:        "3. removed",
+			actual:      []ndb_api.Tag{manual, owner, team},
+			desired:     []ndb_api.Tag{team},
+			previous:    map[string]string{"team": "dba", "owner": "someone"},
+			wantMerged:  []ndb_api.Tag{manual, team},
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, changed := mergeTags(tt.actual, tt.desired, tt.previous)
+			if !reflect.DeepEqual(merged, tt.wantMerged) || changed != tt.wantChanged {
+				t.Errorf("mergeTags() = %v, %v, want %v, %v", merged, changed, tt.wantMerged, tt.wantChanged)
+			}
+		})
+	}
+}
+
+// Tests the withoutUntrackedClusterTag function, tests the following cases:
+// 1. The cluster id tag is kept along with the UID tag
+// 2. The cluster id tag is removed without the UID tag, the other tags are kept
+func TestWithoutUntrackedClusterTag(t *testing.T) {
+	uid := ndb_api.Tag{TagId: "1", TagName: common.NDB_TAG_NAME_CR_UID, Value: "uid"}
+	cluster := ndb_api.Tag{TagId: "2", TagName: common.NDB_TAG_NAME_CLUSTER_ID, Value: "cluster"}
+	team := ndb_api.Tag{TagId: "3", TagName: "team", Value: "dba"}
+
+	// 1. The cluster id tag is kept along with the UID tag
+	tracked, untracked := withoutUntrackedClusterTag([]ndb_api.Tag{cluster, team, uid})
+	if !reflect.DeepEqual(tracked, []ndb_api.Tag{cluster, team, uid}) || untracked {
+		t.Errorf("withoutUntrackedClusterTag() = %v, %v, want all the tags", tracked, untracked)
+	}
+
+	// 2. The cluster id tag is removed without the UID tag, the other tags are kept
+	tracked, untracked = withoutUntrackedClusterTag([]ndb_api.Tag{cluster, team})
+	if !reflect.DeepEqual(tracked, []ndb_api.Tag{team}) || !untracked {
+		t.Errorf("withoutUntrackedClusterTag() = %v, %v, want the cluster id tag removed", tracked, untracked)
+	}
+}
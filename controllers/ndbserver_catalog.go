@@ -0,0 +1,137 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// Fetches the clusters, profiles and SLAs from NDB and converts them to
+// the NDBServerCatalog (to be published in the status of the NDBServer CR)
+func getNDBServerCatalog(ctx context.Context, ndbClient *ndb_client.NDBClient) (catalog *ndbv1alpha1.NDBServerCatalog, err error) {
+	log := log.FromContext(ctx)
+	log.Info("Entered ndbserver_catalog.getNDBServerCatalog")
+
+	clusters, err := ndb_api.GetAllClusters(ctx, ndbClient)
+	if err != nil {
+		log.Error(err, "NDB API error while fetching clusters")
+		return
+	}
+	profiles, err := ndb_api.GetAllProfiles(ctx, ndbClient)
+	if err != nil {
+		log.Error(err, "NDB API error while fetching profiles")
+		return
+	}
+	slas, err := ndb_api.GetAllSLAs(ctx, ndbClient)
+	if err != nil {
+		log.Error(err, "NDB API error while fetching SLAs")
+		return
+	}
+
+	catalog = &ndbv1alpha1.NDBServerCatalog{
+		Clusters: make([]ndbv1alpha1.NDBServerCatalogCluster, len(clusters)),
+		Profiles: make(map[string][]ndbv1alpha1.NDBServerCatalogProfile),
+		SLAs:     make([]ndbv1alpha1.NDBServerCatalogSLA, len(slas)),
+	}
+	for i, cluster := range clusters {
+		catalog.Clusters[i] = ndbv1alpha1.NDBServerCatalogCluster{
+			Id:             cluster.Id,
+			Name:           cluster.Name,
+			Status:         cluster.Status,
+			Version:        cluster.Version,
+			HypervisorType: cluster.HypervisorType,
+		}
+	}
+	sort.Slice(catalog.Clusters, func(i, j int) bool { return catalog.Clusters[i].Name < catalog.Clusters[j].Name })
+
+	for _, profile := range profiles {
+		catalogProfile := ndbv1alpha1.NDBServerCatalogProfile{
+			Id:       profile.Id,
+			Name:     profile.Name,
+			Type:     profile.Type,
+			Topology: profile.Topology,
+			Status:   profile.Status,
+		}
+		if version := getLatestProfileVersion(profile); version != nil {
+			catalogProfile.LatestVersion = &ndbv1alpha1.NDBServerCatalogProfileVersion{
+				Id:      version.Id,
+				Name:    version.Name,
+				Version: version.Version,
+				Status:  version.Status,
+			}
+		}
+		databaseType := getCatalogDatabaseType(profile.EngineType)
+		catalog.Profiles[databaseType] = append(catalog.Profiles[databaseType], catalogProfile)
+	}
+	for _, databaseTypeProfiles := range catalog.Profiles {
+		sort.Slice(databaseTypeProfiles, func(i, j int) bool {
+			if databaseTypeProfiles[i].Type != databaseTypeProfiles[j].Type {
+				return databaseTypeProfiles[i].Type < databaseTypeProfiles[j].Type
+			}
+			return databaseTypeProfiles[i].Name < databaseTypeProfiles[j].Name
+		})
+	}
+
+	for i, sla := range slas {
+		catalog.SLAs[i] = ndbv1alpha1.NDBServerCatalogSLA{
+			Id:                  sla.Id,
+			Name:                sla.Name,
+			ContinuousRetention: sla.ContinuousRetention,
+			DailyRetention:      sla.DailyRetention,
+			WeeklyRetention:     sla.WeeklyRetention,
+			MonthlyRetention:    sla.MonthlyRetention,
+			QuarterlyRetention:  sla.QuarterlyRetention,
+			YearlyRetention:     sla.YearlyRetention,
+		}
+	}
+	sort.Slice(catalog.SLAs, func(i, j int) bool { return catalog.SLAs[i].Name < catalog.SLAs[j].Name })
+
+	log.Info("Returning from ndbserver_catalog.getNDBServerCatalog")
+	return
+}
+
+// Returns the latest version of the profile, falls back to the last
+// listed version if NDB does not report the latest version id.
+// Returns nil for the profiles without versions.
+func getLatestProfileVersion(profile ndb_api.ProfileResponse) *ndb_api.ProfileVersionResponse {
+	if len(profile.Versions) == 0 {
+		return nil
+	}
+	for i := range profile.Versions {
+		if profile.Versions[i].Id == profile.LatestVersionId {
+			return &profile.Versions[i]
+		}
+	}
+	return &profile.Versions[len(profile.Versions)-1]
+}
+
+// Returns the database type (as used in the Database spec) for the NDB engine type.
+// Engines without a supported database type (such as the engine agnostic compute profiles) are lowercased.
+func getCatalogDatabaseType(engineType string) string {
+	if databaseType := ndb_api.GetDatabaseTypeFromEngine(engineType); databaseType != "" {
+		return databaseType
+	}
+	return strings.ToLower(engineType)
+}
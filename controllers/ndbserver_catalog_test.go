@@ -0,0 +1,73 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+func TestGetNDBServerCatalog(t *testing.T) {
+	responses := map[string]interface{}{
+		"/clusters": []ndb_api.ClusterResponse{{Id: "cluster-2", Name: "prod"}, {Id: "cluster-1", Name: "dev"}},
+		"/slas":     []ndb_api.SLAResponse{{Id: "sla-1", Name: "GOLD", DailyRetention: 7}},
+		"/profiles": []ndb_api.ProfileResponse{
+			{Id: "compute", Name: "small", Type: common.PROFILE_TYPE_COMPUTE, EngineType: common.DATABASE_ENGINE_TYPE_GENERIC},
+			{Id: "software", Name: "pg-14", Type: common.PROFILE_TYPE_SOFTWARE, EngineType: common.DATABASE_ENGINE_TYPE_POSTGRES, LatestVersionId: "v2",
+				Versions: []ndb_api.ProfileVersionResponse{{Id: "v1", Name: "PostgreSQL 14.2", Version: "1.0"}, {Id: "v2", Name: "PostgreSQL 14.5", Version: "2.0"}}},
+			{Id: "network", Name: "pg-network", Type: common.PROFILE_TYPE_NETWORK, EngineType: common.DATABASE_ENGINE_TYPE_POSTGRES},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resp, _ := json.Marshal(response)
+		w.Write(resp)
+	}))
+	defer server.Close()
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+
+	catalog, err := getNDBServerCatalog(context.TODO(), ndbClient)
+	if err != nil {
+		t.Fatalf("getNDBServerCatalog() error = %v", err)
+	}
+	if len(catalog.Clusters) != 2 || catalog.Clusters[0].Name != "dev" {
+		t.Errorf("getNDBServerCatalog() clusters = %v, want 2 clusters sorted by name", catalog.Clusters)
+	}
+	if len(catalog.SLAs) != 1 || catalog.SLAs[0].DailyRetention != 7 {
+		t.Errorf("getNDBServerCatalog() slas = %v, want GOLD with its retention", catalog.SLAs)
+	}
+	if len(catalog.Profiles[common.DATABASE_TYPE_GENERIC]) != 1 {
+		t.Errorf("getNDBServerCatalog() generic profiles = %v, want the compute profile", catalog.Profiles[common.DATABASE_TYPE_GENERIC])
+	}
+	postgresProfiles := catalog.Profiles[common.DATABASE_TYPE_POSTGRES]
+	if len(postgresProfiles) != 2 || postgresProfiles[0].Type != common.PROFILE_TYPE_NETWORK || postgresProfiles[0].LatestVersion != nil {
+		t.Errorf("getNDBServerCatalog() postgres profiles = %v, want network and software profiles sorted by type", postgresProfiles)
+	} else if postgresProfiles[1].LatestVersion == nil || postgresProfiles[1].LatestVersion.Id != "v2" {
+		t.Errorf("getNDBServerCatalog() software profile version = %v, want the latest version v2", postgresProfiles[1].LatestVersion)
+	}
+}
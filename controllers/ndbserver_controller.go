@@ -20,6 +20,7 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,40 +31,58 @@ import (
 
 	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
 	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
-	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
 )
 
 // NDBServerReconciler reconciles a NDBServer object
 type NDBServerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Shared with the Database controller
+	NDBClients *NDBClientManager
+	// Id of the Kubernetes cluster, the orphan scan only considers the entities on NDB tagged with it
+	ClusterId string
+	// Reads the Databases from the API server rather than the cache for the orphan scan
+	APIReader client.Reader
+	// Used to stub the current time in the tests, time.Now if nil
+	now func() time.Time
 }
 
 //+kubebuilder:rbac:groups=ndb.nutanix.com,resources=ndbservers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ndb.nutanix.com,resources=ndbservers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ndb.nutanix.com,resources=ndbservers/finalizers,verbs=update
+//+kubebuilder:rbac:groups="core",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 /*
 Reconciles the NDBServer custom resources by
 1. Checks for deletion
 2. Verify credentials and connectivity
-3. Take actions based on current status.status, fetch data
+3. Take actions based on current status.status, fetch data and scan for orphans
 4. Update the status if any changes are observed (excluding counter)
 */
-func (r *NDBServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *NDBServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	log := log.FromContext(ctx)
 	log.Info("NDBServer reconcile started")
+	ctx, span := tracing.StartSpan(ctx, "NDBServer.Reconcile",
+		tracing.ATTRIBUTE_NDB_SERVER_NAME.String(req.Name),
+		attribute.String("k8s.namespace.name", req.Namespace),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 	// 1. Checks for deletion
 	// Fetch the NDBServer resource from the namespace
 	ndbServer := &ndbv1alpha1.NDBServer{}
-	err := r.Get(ctx, req.NamespacedName, ndbServer)
+	err = r.Get(ctx, req.NamespacedName, ndbServer)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Return and don't requeue
 			log.Info("NDBServer resource not found. Ignoring since object must be deleted")
+			r.NDBClients.RemoveNDBClient(req.NamespacedName)
 			return doNotRequeue()
 		}
 		// Error reading the object - requeue the request.
@@ -82,13 +101,11 @@ func (r *NDBServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	// 2. Verify credentials and connectivity
 	// Fetch credentials and check Authentication
-	var ndbClient *ndb_client.NDBClient
-	username, password, caCert, err := getNDBCredentialsFromSecret(ctx, r.Client, ndbServer.Spec.CredentialSecret, req.Namespace)
+	ndbClient, err := r.NDBClients.GetNDBClient(ctx, ndbServer)
 	if err != nil {
 		log.Error(err, "Credential Error: error while fetching credentials from CredentialSecret", "secret name", ndbServer.Spec.CredentialSecret)
 		status.Status = common.NDB_CR_STATUS_CREDENTIAL_ERROR
 	} else {
-		ndbClient = ndb_client.NewNDBClient(username, password, ndbServer.Spec.Server, caCert, ndbServer.Spec.SkipCertificateVerification)
 		authResponse, err := ndb_api.AuthValidate(ctx, ndbClient)
 		if err != nil || authResponse.Status != common.AUTH_RESPONSE_STATUS_SUCCESS {
 			log.Error(err, "Authentication Error: Could not verify connectivity / auth credentials for NDB")
@@ -103,8 +120,31 @@ func (r *NDBServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	case common.NDB_CR_STATUS_CREDENTIAL_ERROR, common.NDB_CR_STATUS_AUTHENTICATION_ERROR:
 		// no-op
 	case common.NDB_CR_STATUS_OK:
+		referencingDatabases, err := getReferencingDatabases(ctx, r.Client, ndbServer)
+		if err != nil {
+			log.Error(err, "Error occurred while listing the databases referencing the NDBServer")
+			status.Status = common.NDB_CR_STATUS_ERROR
+			break
+		}
+		// Report the Databases waiting for a provisioning slot
+		_, queued := getProvisioningQueue(referencingDatabases, ndbServer.Spec.MaxConcurrentProvisioning)
+		status.ProvisioningQueue = getProvisioningQueueNames(queued)
 		// Get Status (check and perform data fetching, update counters)
-		status = getNDBServerStatus(ctx, status, ndbClient)
+		var databases []ndbv1alpha1.NDBServerDatabaseInfo
+		status, databases = getNDBServerStatus(ctx, status, ndbServer.Generation, ndbClient, getManagedDatabaseIds(referencingDatabases))
+		// Publish the inventory whenever the databases have been fetched
+		if databases != nil {
+			status.InventoryConfigMaps, err = r.syncInventory(ctx, ndbServer, databases)
+			if err != nil {
+				log.Error(err, "Error occurred while publishing the inventory of databases")
+				status.Status = common.NDB_CR_STATUS_ERROR
+			}
+		}
+		// Report (and deprovision per the orphan policy) the databases created by the operator without a Database,
+		// failures are reported in the OrphansScanned condition
+		if err = r.scanOrphans(ctx, ndbServer, status, ndbClient); err != nil {
+			log.Error(err, "Error occurred while scanning NDB for orphans")
+		}
 	default:
 		// no-op
 		return doNotRequeue()
@@ -125,6 +165,18 @@ func (r *NDBServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	return requeueWithTimeout(common.NDB_RECONCILE_INTERVAL_SECONDS)
 }
 
+// Returns the ids of the NDB databases of the Database custom resources
+// referencing this NDBServer from all the allowed namespaces.
+func getManagedDatabaseIds(databases []ndbv1alpha1.Database) (ids map[string]bool) {
+	ids = make(map[string]bool)
+	for _, database := range databases {
+		if database.Status.Id != "" {
+			ids[database.Status.Id] = true
+		}
+	}
+	return
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *NDBServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
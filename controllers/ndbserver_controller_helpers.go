@@ -8,20 +8,24 @@ import (
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// Fetches all databases from the NDB API and converts them to
+// Fetches all databases from the NDB API (in pages) and converts them to
 // NDBServerDatabaseInfo type object (to be consumed by the NDBServer CR)
-func getNDBServerDatabasesInfo(ctx context.Context, ndbClient *ndb_client.NDBClient) (databases []ndbv1alpha1.NDBServerDatabaseInfo, err error) {
+func getNDBServerDatabasesInfo(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface) (databases []ndbv1alpha1.NDBServerDatabaseInfo, err error) {
 	log := log.FromContext(ctx)
 	log.Info("Fetching and converting databases from NDB")
-	databasesResponse, err := ndb_api.GetAllDatabases(ctx, ndbClient)
+	// The detailed response is required for the database nodes and their IP addresses
+	listOptions := ndb_api.ListOptions{Detailed: true, PageSize: common.NDB_LIST_PAGE_SIZE}
+	databasesResponse, err := ndb_api.ListDatabases(ctx, ndbClient, listOptions)
 	if err != nil {
 		log.Error(err, "NDB API error while fetching databases")
 		return
 	}
-	clonesResponse, err := ndb_api.GetAllClones(ctx, ndbClient)
+	clonesResponse, err := ndb_api.ListClones(ctx, ndbClient, listOptions)
 	if err != nil {
 		log.Error(err, "NDB API error while fetching clones")
 		return
@@ -51,31 +55,28 @@ func getNDBServerDatabasesInfo(ctx context.Context, ndbClient *ndb_client.NDBCli
 
 // Returns the NDBServerStatus after performing the following steps:
 // 1. Checks and fetch data if dbcounter is zero (we fetch data only when counter hits 0).
-// 2. TODO: Filter and set the required list of databases (we only want to store the databases managed by the operator).
-// 3. Update the counter value.
-func getNDBServerStatus(ctx context.Context, status *ndbv1alpha1.NDBServerStatus, ndbClient *ndb_client.NDBClient) *ndbv1alpha1.NDBServerStatus {
+// 2. Filter and set the required list of databases (we only want to store the databases managed by the operator through this NDBServer).
+// 3. Checks and fetch the catalog if the catalog counter is zero, failures are reported in the CatalogSynced condition.
+// 4. Update the counter values.
+// Also returns the unfiltered list of databases when it has been fetched in this call (nil otherwise).
+func getNDBServerStatus(ctx context.Context, status *ndbv1alpha1.NDBServerStatus, generation int64, ndbClient *ndb_client.NDBClient, managedDatabaseIds map[string]bool) (*ndbv1alpha1.NDBServerStatus, []ndbv1alpha1.NDBServerDatabaseInfo) {
 	log := log.FromContext(ctx)
 	log.Info("Entered ndbserver_controller_helpers.getNDBServerStatus")
 
+	var databases []ndbv1alpha1.NDBServerDatabaseInfo
 	dbCounter := status.ReconcileCounter.Database
 	// 1. Fetch dbs only if dbcounter is 0
 	if dbCounter == 0 {
 		log.Info("DbCounter 0, fetching databases (NDBServerDatabaseInfo)")
-		databases, err := getNDBServerDatabasesInfo(ctx, ndbClient)
+		var err error
+		databases, err = getNDBServerDatabasesInfo(ctx, ndbClient)
 		if err != nil {
 			log.Error(err, "Error occurred while fetching databases (NDBServerDatabaseInfo)")
 			status.Status = common.NDB_CR_STATUS_ERROR
 		} else {
-			/* 2. TODO: Perform filtration on the databases associated with this NDB CR
-			databaseList := &ndbv1alpha1.DatabaseList{}
-			err = r.List(ctx, databaseList) // Also, we'll need to filter the dbs which are solely managed by THIS NDB CR. => Manual filter OR List Opts
-			if err != nil {
-				status.Status = common.NDB_CR_STATUS_ERROR
-			}
-			log.Info(util.ToString(databaseList))
-			filteredDBs := util.Filter(databaseList.Items, FILTER_FUNC )
-			*/
-			status.Databases, err = util.CreateMapForKey(databases, "Id")
+			// 2. Filter the databases managed through this NDBServer
+			managedDatabases := util.Filter(databases, func(db ndbv1alpha1.NDBServerDatabaseInfo) bool { return managedDatabaseIds[db.Id] })
+			status.Databases, err = util.CreateMapForKey(managedDatabases, "Id")
 			if err != nil {
 				log.Error(err, "Error occurred while creating dbId-db map")
 				status.Status = common.NDB_CR_STATUS_ERROR
@@ -83,10 +84,41 @@ func getNDBServerStatus(ctx context.Context, status *ndbv1alpha1.NDBServerStatus
 		}
 	}
 
-	// 3. Update counters
-	status.ReconcileCounter = ndbv1alpha1.ReconcileCounter{
-		Database: (dbCounter + 1) % common.NDB_RECONCILE_DATABASE_COUNTER,
+	// 3. Fetch the catalog only if the catalog counter is 0
+	catalogCounter := status.ReconcileCounter.Catalog
+	if catalogCounter == 0 {
+		log.Info("CatalogCounter 0, fetching catalog (NDBServerCatalog)")
+		// Refresh the cached catalog shared with the webhooks and the database controller
+		ndb_api.InvalidateCache(ndbClient)
+		catalog, err := getNDBServerCatalog(ctx, ndbClient)
+		if err != nil {
+			log.Error(err, "Error occurred while fetching catalog (NDBServerCatalog)")
+		} else {
+			status.Catalog = catalog
+		}
+		setNDBServerCondition(status, generation, common.CONDITION_TYPE_CATALOG_SYNCED, common.CONDITION_REASON_CATALOG_FETCHED, common.CONDITION_REASON_CATALOG_FETCH_FAILED, err)
 	}
+
+	// 4. Update counters
+	status.ReconcileCounter.Database = (dbCounter + 1) % common.NDB_RECONCILE_DATABASE_COUNTER
+	status.ReconcileCounter.Catalog = (catalogCounter + 1) % common.NDB_RECONCILE_CATALOG_COUNTER
 	log.Info("Returning from ndbserver_controller_helpers.getNDBServerStatus")
-	return status
+	return status, databases
+}
+
+// Sets the condition reporting the outcome of a periodic task of the NDBServer (catalog refresh, orphan scan).
+// The failures of these tasks are transient and do not change status.status, which reports the connectivity to NDB.
+func setNDBServerCondition(status *ndbv1alpha1.NDBServerStatus, generation int64, conditionType, successReason, failureReason string, err error) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             successReason,
+		ObservedGeneration: generation,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = failureReason
+		condition.Message = err.Error()
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
 }
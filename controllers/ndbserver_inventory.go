@@ -0,0 +1,121 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+)
+
+// Publishes all the databases on NDB in pages of ConfigMaps owned by the NDBServer if the inventory is enabled.
+// ConfigMaps of pages that are no longer required (or of a disabled inventory) are deleted.
+// Returns the names of the inventory ConfigMaps in page order.
+func (r *NDBServerReconciler) syncInventory(ctx context.Context, ndbServer *ndbv1alpha1.NDBServer, databases []ndbv1alpha1.NDBServerDatabaseInfo) (names []string, err error) {
+	log := log.FromContext(ctx)
+	log.Info("Entered ndbserver_inventory.syncInventory")
+
+	inventory := ndbServer.Spec.Inventory
+	if inventory != nil && inventory.Enabled {
+		pageSize := inventory.PageSize
+		if pageSize <= 0 {
+			pageSize = common.NDB_INVENTORY_DEFAULT_PAGE_SIZE
+		}
+		for i, page := range getInventoryPages(databases, pageSize) {
+			name := fmt.Sprintf("%s-inventory-%d", ndbServer.Name, i)
+			if err = r.applyInventoryPage(ctx, ndbServer, name, page); err != nil {
+				log.Error(err, "Error occurred while publishing the inventory page", "ConfigMap", name)
+				return
+			}
+			names = append(names, name)
+		}
+	}
+
+	// Delete the inventory ConfigMaps of pages that are not required anymore
+	configMaps := &corev1.ConfigMapList{}
+	err = r.List(ctx, configMaps, client.InNamespace(ndbServer.Namespace), client.MatchingLabels{common.NDB_INVENTORY_LABEL_NDBSERVER: ndbServer.Name})
+	if err != nil {
+		return
+	}
+	required := make(map[string]bool, len(names))
+	for _, name := range names {
+		required[name] = true
+	}
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if required[configMap.Name] {
+			continue
+		}
+		log.Info("Deleting stale inventory ConfigMap", "ConfigMap", configMap.Name)
+		if err = r.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+			return
+		}
+	}
+	err = nil
+
+	log.Info("Returning from ndbserver_inventory.syncInventory", "pages", len(names))
+	return
+}
+
+// Creates or updates the inventory ConfigMap holding a single page of databases
+func (r *NDBServerReconciler) applyInventoryPage(ctx context.Context, ndbServer *ndbv1alpha1.NDBServer, name string, page []ndbv1alpha1.NDBServerDatabaseInfo) error {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ndbServer.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Labels == nil {
+			configMap.Labels = make(map[string]string)
+		}
+		configMap.Labels[common.NDB_INVENTORY_LABEL_NDBSERVER] = ndbServer.Name
+		configMap.Data = map[string]string{common.NDB_INVENTORY_CONFIGMAP_DATA_KEY: string(data)}
+		return controllerutil.SetControllerReference(ndbServer, configMap, r.Scheme)
+	})
+	return err
+}
+
+// Splits the databases, sorted by id for stable pages, into pages of at most pageSize databases
+func getInventoryPages(databases []ndbv1alpha1.NDBServerDatabaseInfo, pageSize int) (pages [][]ndbv1alpha1.NDBServerDatabaseInfo) {
+	sorted := make([]ndbv1alpha1.NDBServerDatabaseInfo, len(databases))
+	copy(sorted, databases)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	for start := 0; start < len(sorted); start += pageSize {
+		end := start + pageSize
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		pages = append(pages, sorted[start:end])
+	}
+	return
+}
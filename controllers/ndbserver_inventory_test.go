@@ -0,0 +1,87 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+)
+
+func TestNDBServerReconciler_syncInventory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = ndbv1alpha1.AddToScheme(scheme)
+	ndbServer := &ndbv1alpha1.NDBServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "ndb", Namespace: "default", UID: "ndb-uid"},
+		Spec: ndbv1alpha1.NDBServerSpec{
+			Inventory: &ndbv1alpha1.NDBServerInventory{Enabled: true, PageSize: 2},
+		},
+	}
+	r := &NDBServerReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(ndbServer).Build(),
+		Scheme: scheme,
+	}
+	databases := make([]ndbv1alpha1.NDBServerDatabaseInfo, 5)
+	for i := range databases {
+		databases[i] = ndbv1alpha1.NDBServerDatabaseInfo{Id: fmt.Sprintf("db-%d", i), Name: fmt.Sprintf("db-%d", i)}
+	}
+
+	countConfigMaps := func() int {
+		configMaps := &corev1.ConfigMapList{}
+		if err := r.List(context.TODO(), configMaps); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		return len(configMaps.Items)
+	}
+
+	// 5 databases with a page size of 2 are published in 3 pages
+	names, err := r.syncInventory(context.TODO(), ndbServer, databases)
+	if err != nil {
+		t.Fatalf("syncInventory() error = %v", err)
+	}
+	if len(names) != 3 || countConfigMaps() != 3 {
+		t.Errorf("syncInventory() names = %v, ConfigMaps = %d, want 3 pages", names, countConfigMaps())
+	}
+
+	// Pages that are not required anymore are deleted
+	names, err = r.syncInventory(context.TODO(), ndbServer, databases[:2])
+	if err != nil {
+		t.Fatalf("syncInventory() error = %v", err)
+	}
+	if len(names) != 1 || countConfigMaps() != 1 {
+		t.Errorf("syncInventory() names = %v, ConfigMaps = %d, want 1 page", names, countConfigMaps())
+	}
+
+	// Disabling the inventory deletes all the pages
+	ndbServer.Spec.Inventory.Enabled = false
+	names, err = r.syncInventory(context.TODO(), ndbServer, databases)
+	if err != nil {
+		t.Fatalf("syncInventory() error = %v", err)
+	}
+	if len(names) != 0 || countConfigMaps() != 0 {
+		t.Errorf("syncInventory() names = %v, ConfigMaps = %d, want no pages", names, countConfigMaps())
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// Scans NDB for the orphans, the databases, clones and database servers tagged with the cluster id of the operator
+// whose Database custom resource no longer exists, and updates them in the status. The scan runs when the orphan
+// counter is 0. The Databases are read from the API server, a stale cache would report live databases as orphans.
+// With the deprovision orphan policy, the orphans found by two consecutive scans are deprovisioned once the grace
+// period has elapsed, followed by the database servers of the databases and clones once the deprovisioning operation
+// has passed.
+func (r *NDBServerReconciler) scanOrphans(ctx context.Context, ndbServer *ndbv1alpha1.NDBServer, status *ndbv1alpha1.NDBServerStatus, ndbClient *ndb_client.NDBClient) (err error) {
+	log := ctrllog.FromContext(ctx)
+	orphanCounter := status.ReconcileCounter.Orphans
+	status.ReconcileCounter.Orphans = (orphanCounter + 1) % common.NDB_RECONCILE_ORPHAN_COUNTER
+	if orphanCounter != 0 || r.ClusterId == "" {
+		return
+	}
+	log.Info("OrphanCounter 0, scanning NDB for orphans")
+	defer func() {
+		setNDBServerCondition(status, ndbServer.Generation, common.CONDITION_TYPE_ORPHANS_SCANNED, common.CONDITION_REASON_ORPHANS_SCANNED, common.CONDITION_REASON_ORPHAN_SCAN_FAILED, err)
+	}()
+
+	// 1. Follow up on the orphans being deprovisioned
+	deprovisioned := make(map[string]bool)
+	// Database servers of a database or clone, deprovisioned with it, they are not orphans themselves
+	ownedDBServers := make(map[string]bool)
+	for i := range status.Orphans {
+		orphan := &status.Orphans[i]
+		if orphan.DeprovisionOperationId == "" {
+			continue
+		}
+		operation, err := ndb_api.GetOperationById(ctx, ndbClient, orphan.DeprovisionOperationId)
+		if err != nil {
+			log.Error(err, "Error occurred while fetching the deprovisioning operation of the orphan", "id", orphan.Id, "operation id", orphan.DeprovisionOperationId)
+			continue
+		}
+		switch ndb_api.GetOperationStatus(operation) {
+		case ndb_api.OPERATION_STATUS_FAILED:
+			log.Info("Deprovisioning of the orphan failed, it is retried", "id", orphan.Id, "operation id", orphan.DeprovisionOperationId)
+			orphan.DeprovisionOperationId = ""
+		case ndb_api.OPERATION_STATUS_PASSED:
+			log.Info("Orphan deprovisioned, deprovisioning its database servers", "id", orphan.Id, "name", orphan.Name)
+			deprovisioned[orphan.Id] = true
+			for _, dbServerId := range orphan.DBServerIds {
+				ownedDBServers[dbServerId] = true
+				if _, err := ndb_api.DeprovisionDatabaseServer(ctx, ndbClient, dbServerId, ndb_api.GenerateDeprovisionDatabaseServerRequest()); err != nil {
+					log.Error(err, "Error occurred while deprovisioning the database server of the orphan", "id", orphan.Id, "dbserver id", dbServerId)
+				}
+			}
+		}
+	}
+
+	// 2. Detect the orphans among the databases, clones and database servers on NDB
+	databaseList := &ndbv1alpha1.DatabaseList{}
+	if err = r.APIReader.List(ctx, databaseList); err != nil {
+		log.Error(err, "Error occurred while listing the databases")
+		return
+	}
+	uids := make(map[string]bool)
+	for _, database := range databaseList.Items {
+		uids[string(database.UID)] = true
+	}
+	listOptions := ndb_api.ListOptions{Detailed: true, PageSize: common.NDB_LIST_PAGE_SIZE}
+	databases, err := ndb_api.ListDatabases(ctx, ndbClient, listOptions)
+	if err != nil {
+		log.Error(err, "NDB API error while fetching databases")
+		return
+	}
+	clones, err := ndb_api.ListClones(ctx, ndbClient, listOptions)
+	if err != nil {
+		log.Error(err, "NDB API error while fetching clones")
+		return
+	}
+	dbServers, err := ndb_api.ListDatabaseServers(ctx, ndbClient, ndb_api.ListOptions{})
+	if err != nil {
+		log.Error(err, "NDB API error while fetching database servers")
+		return
+	}
+	previous := make([]ndbv1alpha1.NDBServerOrphan, 0, len(status.Orphans))
+	for _, orphan := range status.Orphans {
+		if !deprovisioned[orphan.Id] {
+			previous = append(previous, orphan)
+		}
+	}
+	now := metav1.NewTime(time.Now())
+	if r.now != nil {
+		now = metav1.NewTime(r.now())
+	}
+	status.Orphans = append(
+		findOrphans(databases, common.NDB_ORPHAN_TYPE_DATABASE, r.ClusterId, uids, previous, now),
+		findOrphans(clones, common.NDB_ORPHAN_TYPE_CLONE, r.ClusterId, uids, previous, now)...,
+	)
+	for _, entity := range append(databases, clones...) {
+		for _, node := range entity.DatabaseNodes {
+			ownedDBServers[node.DatabaseServerId] = true
+		}
+	}
+	status.Orphans = append(status.Orphans, findDatabaseServerOrphans(dbServers, ownedDBServers, r.ClusterId, uids, previous, now)...)
+	if len(status.Orphans) > 0 {
+		log.Info("Found orphans on NDB", "count", len(status.Orphans))
+	}
+
+	// 3. Deprovision the orphans past the grace period
+	policy := ndbServer.Spec.OrphanPolicy
+	if policy == nil || !policy.Deprovision {
+		return
+	}
+	gracePeriod := time.Duration(common.NDB_ORPHAN_DEFAULT_GRACE_PERIOD_SECONDS) * time.Second
+	if policy.GracePeriodSeconds != nil {
+		gracePeriod = time.Duration(*policy.GracePeriodSeconds) * time.Second
+	}
+	for i := range status.Orphans {
+		orphan := &status.Orphans[i]
+		if orphan.DeprovisionOperationId != "" || orphan.Scans < common.NDB_ORPHAN_MIN_SCANS || now.Sub(orphan.DetectedAt.Time) < gracePeriod {
+			continue
+		}
+		var task *ndb_api.TaskInfoSummaryResponse
+		var err error
+		switch orphan.Type {
+		case common.NDB_ORPHAN_TYPE_CLONE:
+			task, err = ndb_api.DeprovisionClone(ctx, ndbClient, orphan.Id, ndb_api.GenerateDeprovisionCloneRequest())
+		case common.NDB_ORPHAN_TYPE_DATABASE_SERVER:
+			task, err = ndb_api.DeprovisionDatabaseServer(ctx, ndbClient, orphan.Id, ndb_api.GenerateDeprovisionDatabaseServerRequest())
+		default:
+			task, err = ndb_api.DeprovisionDatabase(ctx, ndbClient, orphan.Id, ndb_api.GenerateDeprovisionDatabaseRequest())
+		}
+		if err != nil {
+			log.Error(err, "Error occurred while deprovisioning the orphan", "id", orphan.Id, "name", orphan.Name)
+			continue
+		}
+		log.Info("Deprovisioning the orphan", "id", orphan.Id, "name", orphan.Name, "operation id", task.OperationId)
+		orphan.DeprovisionOperationId = task.OperationId
+	}
+	return nil
+}
+
+// Returns the orphans among the databases or clones on NDB: the entities tagged with the cluster id whose UID tag
+// does not match any Database custom resource. The entities without the UID tag cannot be matched and are never
+// orphans. The detection time and the deprovisioning operation of the orphans found by the previous scan are kept.
+func findOrphans(entities []ndb_api.DatabaseResponse, orphanType, clusterId string, uids map[string]bool, previous []ndbv1alpha1.NDBServerOrphan, now metav1.Time) (orphans []ndbv1alpha1.NDBServerOrphan) {
+	for _, entity := range entities {
+		if entityClusterId, found := ndb_api.GetTagValue(entity.Tags, common.NDB_TAG_NAME_CLUSTER_ID); !found || entityClusterId != clusterId {
+			continue
+		}
+		uid, found := ndb_api.GetTagValue(entity.Tags, common.NDB_TAG_NAME_CR_UID)
+		if !found || uid == "" || uids[uid] {
+			continue
+		}
+		orphan := ndbv1alpha1.NDBServerOrphan{
+			Id:          entity.Id,
+			Name:        entity.Name,
+			Type:        orphanType,
+			DatabaseUID: uid,
+			DetectedAt:  now,
+			Scans:       1,
+		}
+		for _, node := range entity.DatabaseNodes {
+			if node.DatabaseServerId != "" {
+				orphan.DBServerIds = append(orphan.DBServerIds, node.DatabaseServerId)
+			}
+		}
+		keepPreviousOrphan(&orphan, previous)
+		orphans = append(orphans, orphan)
+	}
+	return
+}
+
+// Returns the orphans among the database servers on NDB: the database servers tagged with the cluster id whose
+// UID tag does not match any Database custom resource, left behind by a creation that failed or an interrupted
+// deletion. The database servers without the UID tag are never orphans, the database servers of a database or
+// clone (owned) are deprovisioned with it and are skipped.
+func findDatabaseServerOrphans(dbServers []ndb_api.DatabaseServerResponse, owned map[string]bool, clusterId string, uids map[string]bool, previous []ndbv1alpha1.NDBServerOrphan, now metav1.Time) (orphans []ndbv1alpha1.NDBServerOrphan) {
+	for _, dbServer := range dbServers {
+		if dbServerClusterId, found := ndb_api.GetTagValue(dbServer.Tags, common.NDB_TAG_NAME_CLUSTER_ID); !found || dbServerClusterId != clusterId || owned[dbServer.Id] {
+			continue
+		}
+		uid, found := ndb_api.GetTagValue(dbServer.Tags, common.NDB_TAG_NAME_CR_UID)
+		if !found || uid == "" || uids[uid] {
+			continue
+		}
+		orphan := ndbv1alpha1.NDBServerOrphan{
+			Id:          dbServer.Id,
+			Name:        dbServer.Name,
+			Type:        common.NDB_ORPHAN_TYPE_DATABASE_SERVER,
+			DatabaseUID: uid,
+			DetectedAt:  now,
+			Scans:       1,
+		}
+		keepPreviousOrphan(&orphan, previous)
+		orphans = append(orphans, orphan)
+	}
+	return
+}
+
+// Keeps the detection time and the deprovisioning operation of the orphan if it was found by the previous scan,
+// and counts the scan
+func keepPreviousOrphan(orphan *ndbv1alpha1.NDBServerOrphan, previous []ndbv1alpha1.NDBServerOrphan) {
+	for _, previousOrphan := range previous {
+		if previousOrphan.Id == orphan.Id {
+			orphan.DetectedAt = previousOrphan.DetectedAt
+			orphan.DeprovisionOperationId = previousOrphan.DeprovisionOperationId
+			orphan.Scans = previousOrphan.Scans + 1
+			return
+		}
+	}
+}
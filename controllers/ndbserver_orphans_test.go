@@ -0,0 +1,111 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+// Tests the findOrphans function, tests the following cases:
+// 1. Entities tagged with another cluster id, untagged or without the UID tag are skipped
+// 2. Entities tagged with the UID of an existing Database are skipped
+// 3. A new orphan is detected now with its database servers
+// 4. An orphan found by the previous scan keeps its detection time and deprovisioning operation, the scan is counted
+func TestFindOrphans(t *testing.T) {
+	now := metav1.NewTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	detected := metav1.NewTime(now.Add(-time.Hour))
+	newEntity := func(id, clusterId, uid string) ndb_api.DatabaseResponse {
+		entity := ndb_api.DatabaseResponse{Id: id, Name: "db-" + id}
+		if clusterId != "" {
+			entity.Tags = []ndb_api.Tag{{TagName: common.NDB_TAG_NAME_CLUSTER_ID, Value: clusterId}}
+		}
+		if uid != "" {
+			entity.Tags = append(entity.Tags, ndb_api.Tag{TagName: common.NDB_TAG_NAME_CR_UID, Value: uid})
+		}
+		entity.DatabaseNodes = []ndb_api.DatabaseNode{{DatabaseServerId: "dbserver-" + id}}
+		return entity
+	}
+	entities := []ndb_api.DatabaseResponse{
+		newEntity("1", "other-cluster", "uid-1"),
+		newEntity("2", "", ""),
+		newEntity("3", "cluster", "existing-uid"),
+		newEntity("4", "cluster", "uid-4"),
+		newEntity("5", "cluster", "uid-5"),
+		newEntity("6", "cluster", ""),
+	}
+	previous := []ndbv1alpha1.NDBServerOrphan{{Id: "5", DetectedAt: detected, DeprovisionOperationId: "op-5", Scans: 1}}
+
+	orphans := findOrphans(entities, common.NDB_ORPHAN_TYPE_CLONE, "cluster", map[string]bool{"existing-uid": true}, previous, now)
+
+	expected := []ndbv1alpha1.NDBServerOrphan{
+		{Id: "4", Name: "db-4", Type: common.NDB_ORPHAN_TYPE_CLONE, DatabaseUID: "uid-4", DBServerIds: []string{"dbserver-4"}, DetectedAt: now, Scans: 1},
+		{Id: "5", Name: "db-5", Type: common.NDB_ORPHAN_TYPE_CLONE, DatabaseUID: "uid-5", DBServerIds: []string{"dbserver-5"}, DetectedAt: detected, DeprovisionOperationId: "op-5", Scans: 2},
+	}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("findOrphans() = %v, want %v", orphans, expected)
+	}
+}
+
+// Tests the findDatabaseServerOrphans function, tests the following cases:
+// 1. Database servers tagged with another cluster id, untagged or without the UID tag are skipped
+// 2. Database servers tagged with the UID of an existing Database are skipped
+// 3. Database servers of a database or clone are skipped
+// 4. A new orphan is detected now
+// 5. An orphan found by the previous scan keeps its detection time and deprovisioning operation, the scan is counted
+func TestFindDatabaseServerOrphans(t *testing.T) {
+	now := metav1.NewTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	detected := metav1.NewTime(now.Add(-time.Hour))
+	newDBServer := func(id, clusterId, uid string) ndb_api.DatabaseServerResponse {
+		dbServer := ndb_api.DatabaseServerResponse{Id: id, Name: "dbserver-" + id}
+		if clusterId != "" {
+			dbServer.Tags = []ndb_api.Tag{{TagName: common.NDB_TAG_NAME_CLUSTER_ID, Value: clusterId}}
+		}
+		if uid != "" {
+			dbServer.Tags = append(dbServer.Tags, ndb_api.Tag{TagName: common.NDB_TAG_NAME_CR_UID, Value: uid})
+		}
+		return dbServer
+	}
+	dbServers := []ndb_api.DatabaseServerResponse{
+		newDBServer("1", "other-cluster", "uid-1"),
+		newDBServer("2", "", ""),
+		newDBServer("3", "cluster", "existing-uid"),
+		newDBServer("4", "cluster", "uid-4"),
+		newDBServer("5", "cluster", "uid-5"),
+		newDBServer("6", "cluster", "uid-6"),
+		newDBServer("7", "cluster", ""),
+	}
+	owned := map[string]bool{"4": true}
+	previous := []ndbv1alpha1.NDBServerOrphan{{Id: "6", DetectedAt: detected, DeprovisionOperationId: "op-6", Scans: 1}}
+
+	orphans := findDatabaseServerOrphans(dbServers, owned, "cluster", map[string]bool{"existing-uid": true}, previous, now)
+
+	expected := []ndbv1alpha1.NDBServerOrphan{
+		{Id: "5", Name: "dbserver-5", Type: common.NDB_ORPHAN_TYPE_DATABASE_SERVER, DatabaseUID: "uid-5", DetectedAt: now, Scans: 1},
+		{Id: "6", Name: "dbserver-6", Type: common.NDB_ORPHAN_TYPE_DATABASE_SERVER, DatabaseUID: "uid-6", DetectedAt: detected, DeprovisionOperationId: "op-6", Scans: 2},
+	}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("findDatabaseServerOrphans() = %v, want %v", orphans, expected)
+	}
+}
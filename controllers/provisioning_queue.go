@@ -0,0 +1,114 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+)
+
+// Returns the Databases referencing the NDBServer from all allowed namespaces
+func getReferencingDatabases(ctx context.Context, c client.Reader, ndbServer *ndbv1alpha1.NDBServer) (databases []ndbv1alpha1.Database, err error) {
+	databaseList := &ndbv1alpha1.DatabaseList{}
+	if err = c.List(ctx, databaseList); err != nil {
+		return
+	}
+	for _, database := range databaseList.Items {
+		if ndbServer.IsReferencedBy(&database) {
+			databases = append(databases, database)
+		}
+	}
+	return
+}
+
+// Splits the Databases waiting to be submitted to NDB (without an id, with no status yet or QUEUED),
+// in order of creation, into the ones
+// that may be submitted now and the ones that stay queued because maxConcurrentProvisioning
+// Databases are already being provisioned. All of them are admitted if there is no limit.
+func getProvisioningQueue(databases []ndbv1alpha1.Database, maxConcurrentProvisioning int) (admitted, queued []ndbv1alpha1.Database) {
+	inProgress := 0
+	var waiting []ndbv1alpha1.Database
+	for _, database := range databases {
+		if database.Status.Id == "" {
+			// Databases rejected by NDB (CREATION ERROR) do not hold a slot
+			if isWaitingForProvisioning(database) {
+				waiting = append(waiting, database)
+			}
+		} else if database.Status.Status == common.DATABASE_CR_STATUS_CREATING {
+			inProgress++
+		}
+	}
+	sort.SliceStable(waiting, func(i, j int) bool {
+		ti, tj := waiting[i].CreationTimestamp, waiting[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return waiting[i].Namespace+"/"+waiting[i].Name < waiting[j].Namespace+"/"+waiting[j].Name
+	})
+	if maxConcurrentProvisioning <= 0 {
+		return waiting, nil
+	}
+	free := maxConcurrentProvisioning - inProgress
+	if free < 0 {
+		free = 0
+	}
+	if free > len(waiting) {
+		free = len(waiting)
+	}
+	return waiting[:free], waiting[free:]
+}
+
+// Returns true if the Database has not been submitted to NDB yet and is not being deleted
+func isWaitingForProvisioning(database ndbv1alpha1.Database) bool {
+	status := database.Status.Status
+	return database.Status.Id == "" && database.DeletionTimestamp.IsZero() &&
+		(status == "" || status == common.DATABASE_CR_STATUS_QUEUED)
+}
+
+// Returns true if the Database may be submitted to NDB now, otherwise its (zero based) position in the provisioning queue.
+// The Databases must be read from the API server (not the cache): the slots are then counted from a consistent list,
+// an admitted Database keeps its slot at the head of the queue until its submission is recorded with the CREATING status,
+// so the reconciles of two Databases cannot both be admitted for the last slot.
+func getProvisioningSlot(ctx context.Context, c client.Reader, ndbServer *ndbv1alpha1.NDBServer, database *ndbv1alpha1.Database) (admitted bool, position int, err error) {
+	if ndbServer.Spec.MaxConcurrentProvisioning <= 0 {
+		return true, 0, nil
+	}
+	databases, err := getReferencingDatabases(ctx, c, ndbServer)
+	if err != nil {
+		return
+	}
+	_, queued := getProvisioningQueue(databases, ndbServer.Spec.MaxConcurrentProvisioning)
+	for i := range queued {
+		if queued[i].UID == database.UID {
+			return false, i, nil
+		}
+	}
+	return true, 0, nil
+}
+
+// Returns the namespaced names of the queued Databases in queue order
+func getProvisioningQueueNames(queued []ndbv1alpha1.Database) (names []string) {
+	for _, database := range queued {
+		names = append(names, database.Namespace+"/"+database.Name)
+	}
+	return
+}
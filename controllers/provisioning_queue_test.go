@@ -0,0 +1,137 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+)
+
+// Returns a Database referencing the "ndb" NDBServer created at the given offset from a fixed time
+func getProvisioningQueueTestDatabase(name string, createdAfter time.Duration, status, id string) *ndbv1alpha1.Database {
+	return &ndbv1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID(name + "-uid"),
+			CreationTimestamp: metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(createdAfter)),
+		},
+		Spec:   ndbv1alpha1.DatabaseSpec{NDBRef: "ndb"},
+		Status: ndbv1alpha1.DatabaseStatus{Status: status, Id: id},
+	}
+}
+
+// Tests the getProvisioningQueue function, tests the following cases:
+//  1. All waiting databases are admitted when there is no limit
+//  2. Databases are admitted in order of creation up to the free slots
+//  3. No database is admitted while the limit is reached by databases being provisioned
+//  4. Databases rejected by NDB without an id do not hold a slot
+func TestGetProvisioningQueue(t *testing.T) {
+	creating := *getProvisioningQueueTestDatabase("creating", 0, common.DATABASE_CR_STATUS_CREATING, "id-1")
+	ready := *getProvisioningQueueTestDatabase("ready", 0, common.DATABASE_CR_STATUS_READY, "id-2")
+	second := *getProvisioningQueueTestDatabase("second", 2*time.Minute, common.DATABASE_CR_STATUS_QUEUED, "")
+	first := *getProvisioningQueueTestDatabase("first", time.Minute, "", "")
+	third := *getProvisioningQueueTestDatabase("third", 3*time.Minute, "", "")
+	rejected := *getProvisioningQueueTestDatabase("rejected", 0, common.DATABASE_CR_STATUS_CREATION_ERROR, "")
+	databases := []ndbv1alpha1.Database{creating, ready, third, second, first, rejected}
+
+	tests := []struct {
+		name         string
+		limit        int
+		wantAdmitted []string
+		wantQueued   []string
+	}{
+		{
+			name:         "Test 1: All waiting databases are admitted when there is no limit",
+			limit:        0,
+			wantAdmitted: []string{"default/first", "default/second", "default/third"},
+		},
+		{
+			name:         "Test 2: Databases are admitted in order of creation up to the free slots",
+			limit:        3,
+			wantAdmitted: []string{"default/first", "default/second"},
+			wantQueued:   []string{"default/third"},
+		},
+		{
+			name:       "Test 3: No database is admitted while the limit is reached by databases being provisioned",
+			limit:      1,
+			wantQueued: []string{"default/first", "default/second", "default/third"},
+		},
+		{
+			name:         "Test 4: Databases rejected by NDB without an id do not hold a slot",
+			limit:        2,
+			wantAdmitted: []string{"default/first"},
+			wantQueued:   []string{"default/second", "default/third"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admitted, queued := getProvisioningQueue(databases, tt.limit)
+			if got := getProvisioningQueueNames(admitted); !reflect.DeepEqual(got, tt.wantAdmitted) {
+				t.Errorf("getProvisioningQueue() admitted = %v, want %v", got, tt.wantAdmitted)
+			}
+			if got := getProvisioningQueueNames(queued); !reflect.DeepEqual(got, tt.wantQueued) {
+				t.Errorf("getProvisioningQueue() queued = %v, want %v", got, tt.wantQueued)
+			}
+		})
+	}
+}
+
+func TestGetProvisioningSlot(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = ndbv1alpha1.AddToScheme(scheme)
+	ndbServer := &ndbv1alpha1.NDBServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "ndb", Namespace: "default"},
+		Spec:       ndbv1alpha1.NDBServerSpec{MaxConcurrentProvisioning: 2},
+	}
+	creating := getProvisioningQueueTestDatabase("creating", 0, common.DATABASE_CR_STATUS_CREATING, "id-1")
+	first := getProvisioningQueueTestDatabase("first", time.Minute, "", "")
+	second := getProvisioningQueueTestDatabase("second", 2*time.Minute, "", "")
+	third := getProvisioningQueueTestDatabase("third", 3*time.Minute, "", "")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects([]client.Object{ndbServer, creating, first, second, third}...).Build()
+
+	for i, tt := range []struct {
+		database     *ndbv1alpha1.Database
+		wantAdmitted bool
+		wantPosition int
+	}{
+		{database: first, wantAdmitted: true},
+		{database: second, wantAdmitted: false, wantPosition: 0},
+		{database: third, wantAdmitted: false, wantPosition: 1},
+	} {
+		admitted, position, err := getProvisioningSlot(context.TODO(), c, ndbServer, tt.database)
+		if err != nil {
+			t.Fatalf("%d: getProvisioningSlot() error = %v", i, err)
+		}
+		if admitted != tt.wantAdmitted || position != tt.wantPosition {
+			t.Errorf("%d: getProvisioningSlot(%s) = (%v, %d), want (%v, %d)", i, tt.database.Name, admitted, position, tt.wantAdmitted, tt.wantPosition)
+		}
+	}
+}
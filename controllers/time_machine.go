@@ -0,0 +1,191 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/controller_adapters"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Applies the changes made to spec.databaseInstance.timeMachine of a READY database instance on NDB. The time machine
+// is updated in place with the SLA and the snapshot schedule computed from the spec, and the SLA and schedule reported
+// by NDB are recorded in status.timeMachine. The spec and the spec last applied are compared with their defaults, so
+// that the defaults set by the webhook on an update do not count as a change. Clones are not updated.
+func (r *DatabaseReconciler) syncTimeMachine(ctx context.Context, database *ndbv1alpha1.Database, ndbClient *ndb_client.NDBClient) error {
+	log := ctrllog.FromContext(ctx)
+	if database.Spec.IsClone || database.Spec.Instance == nil || database.Spec.Instance.TMInfo == nil || database.Status.Id == "" {
+		return nil
+	}
+	tmInfo := database.Spec.Instance.TMInfo
+	status := database.Status.TimeMachine
+	if status != nil && status.AppliedSpec != nil && reflect.DeepEqual(*tmInfo, *status.AppliedSpec) {
+		return nil
+	}
+
+	if status == nil || status.AppliedSpec == nil || reflect.DeepEqual(getDefaultedTimeMachineSpec(tmInfo), getDefaultedTimeMachineSpec(status.AppliedSpec)) {
+		// Databases created by earlier operator versions, the spec was applied when the database was created.
+		// Otherwise only the defaults differ, the spec applied on NDB is unchanged.
+		if status == nil {
+			status = &ndbv1alpha1.TimeMachineStatus{}
+		}
+		status.AppliedSpec = tmInfo.DeepCopy()
+		database.Status.TimeMachine = status
+	} else {
+		log.Info("Updating the time machine of the database on NDB", "id", database.Status.Id, "sla", tmInfo.SLAName)
+		timeMachine, err := updateTimeMachine(ctx, ndbClient, database)
+		if ndb_api.IsConflict(err) {
+			log.Info("The time machine is being changed on NDB, its update is retried", "id", database.Status.Id, "error", err.Error())
+			return err
+		}
+		if err != nil {
+			errStatement := "Failed to update the SLA and schedule of the time machine on NDB"
+			log.Error(err, errStatement)
+			r.recorder.Eventf(database, "Warning", EVENT_NDB_REQUEST_FAILED, "Error: %s. %s", errStatement, err.Error())
+			return err
+		}
+		database.Status.TimeMachine = getTimeMachineStatus(timeMachine, tmInfo.DeepCopy())
+		r.recorder.Eventf(database, "Normal", EVENT_TIME_MACHINE_UPDATED, "Time machine %s updated on NDB with the SLA %s", timeMachine.Id, database.Status.TimeMachine.SLAName)
+	}
+
+	if err := r.Status().Update(ctx, database); err != nil {
+		errStatement := "Failed to update status of database custom resource"
+		log.Error(err, errStatement)
+		r.recorder.Eventf(database, "Warning", EVENT_CR_STATUS_UPDATE_FAILED, "Error: %s. %s.", errStatement, err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns a copy of the time machine details with the defaults of the webhook and of the schedules enabled by default
+func getDefaultedTimeMachineSpec(tmInfo *ndbv1alpha1.DBTimeMachineInfo) *ndbv1alpha1.DBTimeMachineInfo {
+	defaulted := tmInfo.DeepCopy()
+	defaulted.SetDefaults()
+	for _, enabled := range []struct {
+		value        **bool
+		defaultValue bool
+	}{
+		{&defaulted.LogCatchUpEnabled, true},
+		{&defaulted.WeeklySnapshotEnabled, true},
+		{&defaulted.MonthlySnapshotEnabled, true},
+		{&defaulted.QuarterlySnapshotEnabled, true},
+		{&defaulted.YearlySnapshotEnabled, false},
+	} {
+		if *enabled.value == nil {
+			defaultValue := enabled.defaultValue
+			*enabled.value = &defaultValue
+		}
+	}
+	return defaulted
+}
+
+// Updates the name, description, SLA and schedule of the time machine of the database instance on NDB
+// as per the spec, returns the time machine fetched from NDB after the update
+func updateTimeMachine(ctx context.Context, ndbClient *ndb_client.NDBClient, database *ndbv1alpha1.Database) (timeMachine *ndb_api.TimeMachineResponse, err error) {
+	databaseAdapter := &controller_adapters.Database{Database: *database}
+	tmName, tmDescription, slaName := databaseAdapter.GetInstanceTMDetails()
+	sla, err := ndb_api.GetSLAByName(ctx, ndbClient, slaName)
+	if err != nil {
+		return
+	}
+	schedule, err := databaseAdapter.GetTMScheduleForInstance()
+	if err != nil {
+		return
+	}
+	ndbDatabase, err := ndb_api.GetDatabaseById(ctx, ndbClient, database.Status.Id)
+	if err != nil {
+		return
+	}
+	if ndbDatabase.TimeMachineId == "" {
+		err = fmt.Errorf("database %s has no time machine on NDB", database.Status.Id)
+		return
+	}
+
+	req := &ndb_api.TimeMachineUpdateRequest{
+		Name:             tmName,
+		Description:      tmDescription,
+		SlaId:            sla.Id,
+		Schedule:         schedule,
+		ResetName:        true,
+		ResetDescription: true,
+		ResetSlaId:       true,
+		ResetSchedule:    true,
+	}
+	if _, err = ndb_api.UpdateTimeMachine(ctx, ndbClient, ndbDatabase.TimeMachineId, req); err != nil {
+		return
+	}
+	return ndb_api.GetTimeMachineById(ctx, ndbClient, ndbDatabase.TimeMachineId)
+}
+
+// Returns the SLA with its retention and the snapshot schedule of the time machine on NDB in the format of the spec.
+// The schedule is only reported if NDB returns it.
+func getTimeMachineStatus(timeMachine *ndb_api.TimeMachineResponse, appliedSpec *ndbv1alpha1.DBTimeMachineInfo) *ndbv1alpha1.TimeMachineStatus {
+	sla := timeMachine.Sla
+	status := &ndbv1alpha1.TimeMachineStatus{
+		Id:                  timeMachine.Id,
+		SLAId:               sla.Id,
+		SLAName:             sla.Name,
+		ContinuousRetention: sla.ContinuousRetention,
+		DailyRetention:      sla.DailyRetention,
+		WeeklyRetention:     sla.WeeklyRetention,
+		MonthlyRetention:    sla.MonthlyRetention,
+		QuarterlyRetention:  sla.QuarterlyRetention,
+		YearlyRetention:     sla.YearlyRetention,
+		AppliedSpec:         appliedSpec,
+	}
+	if schedule := timeMachine.Schedule; schedule.Id != "" {
+		enabled := func(enabled bool) *bool { return &enabled }
+		status.DailySnapshotTime = fmt.Sprintf("%02d:%02d:%02d", schedule.SnapshotTimeOfDay.Hours, schedule.SnapshotTimeOfDay.Minutes, schedule.SnapshotTimeOfDay.Seconds)
+		status.SnapshotsPerDay = schedule.ContinuousSchedule.SnapshotsPerDay
+		status.LogCatchUpFrequency = schedule.ContinuousSchedule.LogBackupInterval
+		status.LogCatchUpEnabled = enabled(schedule.ContinuousSchedule.Enabled)
+		status.WeeklySnapshotDay = schedule.WeeklySchedule.DayOfWeek
+		status.WeeklySnapshotEnabled = enabled(schedule.WeeklySchedule.Enabled)
+		status.MonthlySnapshotDay = schedule.MonthlySchedule.DayOfMonth
+		status.MonthlySnapshotEnabled = enabled(schedule.MonthlySchedule.Enabled)
+		status.QuarterlySnapshotMonth = getSnapshotMonth(schedule.QuarterlySchedule.StartMonth)
+		status.QuarterlySnapshotEnabled = enabled(schedule.QuarterlySchedule.Enabled)
+		status.YearlySnapshotDay = schedule.YearlySchedule.DayOfMonth
+		status.YearlySnapshotMonth = getSnapshotMonth(schedule.YearlySchedule.Month)
+		status.YearlySnapshotEnabled = enabled(schedule.YearlySchedule.Enabled)
+	}
+	return status
+}
+
+// Returns the month of the spec (Jan to Dec) for a month of the schedule on NDB
+func getSnapshotMonth(ndbMonth string) string {
+	for month, monthOnNDB := range controller_adapters.MONTH_MAP {
+		if month != "" && monthOnNDB == ndbMonth {
+			return month
+		}
+	}
+	return ndbMonth
+}
+
+// Returns the time machine details of the spec last applied on NDB, nil if they are not known
+func getAppliedTimeMachineSpec(database *ndbv1alpha1.Database) *ndbv1alpha1.DBTimeMachineInfo {
+	if database.Status.TimeMachine == nil {
+		return nil
+	}
+	return database.Status.TimeMachine.AppliedSpec
+}
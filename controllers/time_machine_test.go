@@ -0,0 +1,167 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+// Tests the getTimeMachineStatus function, tests the following cases:
+// 1. NDB reports the SLA with its retention and the schedule of the time machine
+// 2. NDB does not report the schedule of the time machine
+func TestGetTimeMachineStatus(t *testing.T) {
+	enabled, disabled := true, false
+	appliedSpec := &ndbv1alpha1.DBTimeMachineInfo{SLAName: "DEFAULT_OOB_GOLD_SLA"}
+	timeMachine := &ndb_api.TimeMachineResponse{
+		Id: "tm-1",
+		Sla: ndb_api.TimeMachineSLA{
+			Id:                  "sla-gold",
+			Name:                "DEFAULT_OOB_GOLD_SLA",
+			ContinuousRetention: 30,
+			DailyRetention:      90,
+			WeeklyRetention:     16,
+			MonthlyRetention:    12,
+			QuarterlyRetention:  75,
+			YearlyRetention:     5,
+		},
+		Schedule: ndb_api.TimeMachineSchedule{
+			Id:                 "schedule-1",
+			SnapshotTimeOfDay:  ndb_api.TimeMachineSnapshotTimeOfDay{Hours: 2, Minutes: 30},
+			ContinuousSchedule: ndb_api.TimeMachineContinuousSchedule{Enabled: true, LogBackupInterval: 30, SnapshotsPerDay: 2},
+			WeeklySchedule:     ndb_api.TimeMachineWeeklySchedule{Enabled: true, DayOfWeek: "FRIDAY"},
+			MonthlySchedule:    ndb_api.TimeMachineMonthlySchedule{Enabled: true, DayOfMonth: 15},
+			QuarterlySchedule:  ndb_api.TimeMachineQuarterlySchedule{Enabled: false, StartMonth: "FEBRUARY", DayOfMonth: 15},
+			YearlySchedule:     ndb_api.TimeMachineYearlySchedule{Enabled: true, DayOfMonth: 30, Month: "JUNE"},
+		},
+	}
+	withoutSchedule := *timeMachine
+	withoutSchedule.Sla = ndb_api.TimeMachineSLA{Id: "sla-none", Name: "NONE"}
+	withoutSchedule.Schedule = ndb_api.TimeMachineSchedule{}
+
+	tests := []struct {
+		name        string
+		timeMachine *ndb_api.TimeMachineResponse
+		want        *ndbv1alpha1.TimeMachineStatus
+	}{
+		{
+			name:        "SLA and schedule",
+			timeMachine: timeMachine,
+			want: &ndbv1alpha1.TimeMachineStatus{
+				Id:                       "tm-1",
+				SLAId:                    "sla-gold",
+				SLAName:                  "DEFAULT_OOB_GOLD_SLA",
+				DailySnapshotTime:        "02:30:00",
+				SnapshotsPerDay:          2,
+				LogCatchUpFrequency:      30,
+				WeeklySnapshotDay:        "FRIDAY",
+				MonthlySnapshotDay:       15,
+				QuarterlySnapshotMonth:   "Feb",
+				YearlySnapshotDay:        30,
+				YearlySnapshotMonth:      "Jun",
+				LogCatchUpEnabled:        &enabled,
+				WeeklySnapshotEnabled:    &enabled,
+				MonthlySnapshotEnabled:   &enabled,
+				QuarterlySnapshotEnabled: &disabled,
+				YearlySnapshotEnabled:    &enabled,
+				ContinuousRetention:      30,
+				DailyRetention:           90,
+				WeeklyRetention:          16,
+				MonthlyRetention:         12,
+				QuarterlyRetention:       75,
+				YearlyRetention:          5,
+				AppliedSpec:              appliedSpec,
+			},
+		},
+		{
+			name:        "no schedule",
+			timeMachine: &withoutSchedule,
+			want: &ndbv1alpha1.TimeMachineStatus{
+				Id:          "tm-1",
+				SLAId:       "sla-none",
+				SLAName:     "NONE",
+				AppliedSpec: appliedSpec,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getTimeMachineStatus(tt.timeMachine, appliedSpec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getTimeMachineStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Tests the getDefaultedTimeMachineSpec function, tests the following cases:
+// 1. The defaults of the webhook and of the schedules enabled by default are set
+// 2. The details set in the spec are kept
+func TestGetDefaultedTimeMachineSpec(t *testing.T) {
+	enabled, disabled := true, false
+	defaulted := &ndbv1alpha1.DBTimeMachineInfo{
+		SLAName:                  "NONE",
+		DailySnapshotTime:        "04:00:00",
+		SnapshotsPerDay:          1,
+		LogCatchUpFrequency:      30,
+		WeeklySnapshotDay:        "FRIDAY",
+		MonthlySnapshotDay:       15,
+		QuarterlySnapshotMonth:   "Jan",
+		YearlySnapshotDay:        31,
+		YearlySnapshotMonth:      "Dec",
+		LogCatchUpEnabled:        &enabled,
+		WeeklySnapshotEnabled:    &enabled,
+		MonthlySnapshotEnabled:   &enabled,
+		QuarterlySnapshotEnabled: &enabled,
+		YearlySnapshotEnabled:    &disabled,
+	}
+	custom := &ndbv1alpha1.DBTimeMachineInfo{
+		Name:                     "tm",
+		SLAName:                  "DEFAULT_OOB_GOLD_SLA",
+		DailySnapshotTime:        "02:30:00",
+		SnapshotsPerDay:          2,
+		LogCatchUpFrequency:      60,
+		WeeklySnapshotDay:        "MONDAY",
+		MonthlySnapshotDay:       1,
+		QuarterlySnapshotMonth:   "Feb",
+		YearlySnapshotDay:        30,
+		YearlySnapshotMonth:      "Jun",
+		LogCatchUpEnabled:        &disabled,
+		WeeklySnapshotEnabled:    &disabled,
+		MonthlySnapshotEnabled:   &disabled,
+		QuarterlySnapshotEnabled: &disabled,
+		YearlySnapshotEnabled:    &enabled,
+	}
+
+	tests := []struct {
+		name   string
+		tmInfo *ndbv1alpha1.DBTimeMachineInfo
+		want   *ndbv1alpha1.DBTimeMachineInfo
+	}{
+		{name: "defaults", tmInfo: &ndbv1alpha1.DBTimeMachineInfo{}, want: defaulted},
+		{name: "spec", tmInfo: custom, want: custom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getDefaultedTimeMachineSpec(tt.tmInfo); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getDefaultedTimeMachineSpec() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -22,24 +22,32 @@ Changes added
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	"go.uber.org/zap/zapcore"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	ndbv1alpha1 "github.com/nutanix-cloud-native/ndb-operator/api/v1alpha1"
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
 	"github.com/nutanix-cloud-native/ndb-operator/controllers"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -59,11 +67,29 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableNDBValidation bool
+	var ndbCacheTTL time.Duration
+	var tracingOptions tracing.Options
+	var clusterId string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableNDBValidation, "enable-ndb-validation", false,
+		"Enable validation of the NDB references (cluster, profiles, SLA, source database) in Database specs against NDB in the webhook. "+
+			"Admission is allowed with warnings if NDB is unreachable.")
+	flag.DurationVar(&ndbCacheTTL, "ndb-cache-ttl", ndb_api.DEFAULT_CACHE_TTL,
+		"The duration for which the profiles, SLAs and clusters fetched from NDB are cached and shared by the webhooks and controllers. "+
+			"0 disables the cache.")
+	flag.StringVar(&tracingOptions.Endpoint, "tracing-endpoint", "",
+		"The host:port of the OTLP gRPC endpoint (e.g. an OpenTelemetry collector) the traces of the reconciles and NDB requests are exported to. "+
+			"Tracing is disabled if empty.")
+	flag.BoolVar(&tracingOptions.Insecure, "tracing-insecure", false, "Disable TLS for the connection to the tracing endpoint.")
+	flag.Float64Var(&tracingOptions.SamplingRatio, "tracing-sampling-ratio", 1, "The fraction of the traces that are sampled, between 0 and 1.")
+	flag.StringVar(&clusterId, "cluster-id", "",
+		"The id of the Kubernetes cluster tagged on the databases and clones created on NDB, used to detect the orphaned ones. "+
+			"Defaults to the UID of the kube-system namespace.")
 	opts := zap.Options{
 		Development: true,
 		TimeEncoder: zapcore.RFC3339TimeEncoder,
@@ -73,6 +99,19 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	if tracingOptions.Endpoint != "" {
+		setupLog.Info("Tracing is enabled", "endpoint", tracingOptions.Endpoint, "sampling ratio", tracingOptions.SamplingRatio)
+	}
+
+	if ndbCacheTTL > 0 {
+		ndb_api.SetCache(ndb_api.NewCache(ndbCacheTTL))
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -97,9 +136,29 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The UID of the kube-system namespace identifies the cluster unless an id is specified
+	if clusterId == "" {
+		kubeSystem := &corev1.Namespace{}
+		if err = mgr.GetAPIReader().Get(context.Background(), types.NamespacedName{Name: metav1.NamespaceSystem}, kubeSystem); err != nil {
+			setupLog.Error(err, "unable to read the kube-system namespace, the databases created on NDB are not tagged with the cluster id")
+		} else {
+			clusterId = string(kubeSystem.UID)
+		}
+	}
+	setupLog.Info("Cluster id", "cluster id", clusterId)
+
+	// NDB clients are shared by the controllers to reuse the connections to NDB
+	ndbClients := controllers.NewNDBClientManager(mgr.GetClient())
+
+	// Gauges of the Database and NDBServer custom resources, read from the manager's cache when scraped
+	metrics.Registry.MustRegister(controllers.NewResourceCollector(mgr.GetClient(), ndbClients))
+
 	if err = (&controllers.DatabaseReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		NDBClients: ndbClients,
+		ClusterId:  clusterId,
+		APIReader:  mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Database")
 		os.Exit(1)
@@ -112,15 +171,26 @@ func main() {
 
 	if util.IsFeatureEnabled("ENABLE_WEBHOOKS") {
 		setupLog.Info("ENABLE_WEBHOOKS is set to True. Attempting to register the Webhook...")
+		if enableNDBValidation {
+			setupLog.Info("NDB validation is enabled for the Database webhook", "cache ttl", ndbCacheTTL)
+			ndbv1alpha1.EnableNDBReferenceValidation(mgr.GetClient(), ndbClients)
+		}
 		if err = (&ndbv1alpha1.Database{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Database")
 			os.Exit(1)
 		}
+		if err = (&ndbv1alpha1.NDBServer{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NDBServer")
+			os.Exit(1)
+		}
 	}
 
 	if err = (&controllers.NDBServerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		NDBClients: ndbClients,
+		ClusterId:  clusterId,
+		APIReader:  mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NDBServer")
 		os.Exit(1)
@@ -137,7 +207,14 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+	// Export the remaining spans
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if shutdownErr := shutdownTracing(shutdownCtx); shutdownErr != nil {
+		setupLog.Error(shutdownErr, "problem shutting down tracing")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -0,0 +1,155 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// Default time for which the profiles, SLAs and clusters fetched from NDB are reused
+const DEFAULT_CACHE_TTL = 30 * time.Second
+
+// Cache of the responses of the NDB catalog endpoints (profiles, SLAs and clusters),
+// keyed by the NDB server and the endpoint (including the query). Concurrent lookups of
+// the same key wait for a single request to NDB. The cached slices must not be modified.
+// Expired entries are evicted at most once per TTL, when an entry is looked up.
+type Cache struct {
+	ttl       time.Duration
+	mutex     sync.Mutex
+	entries   map[string]*cacheEntry
+	evictedAt time.Time
+	now       func() time.Time
+}
+
+type cacheEntry struct {
+	mutex     sync.Mutex
+	value     interface{}
+	fetchedAt time.Time
+	valid     bool
+}
+
+// The cache shared by the webhooks and the controllers, nil disables caching
+var sharedCache atomic.Pointer[Cache]
+
+// Returns a cache whose entries expire after ttl
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Sets the cache used for the profiles, SLAs and clusters fetched from NDB, nil disables caching
+func SetCache(cache *Cache) {
+	sharedCache.Store(cache)
+}
+
+// Invalidates the cached responses of the NDB server the client sends its requests to
+func InvalidateCache(ndbClient ndb_client.NDBClientHTTPInterface) {
+	cache := sharedCache.Load()
+	server, ok := getServerURL(ndbClient)
+	if cache != nil && ok {
+		cache.Invalidate(server)
+	}
+}
+
+// Invalidates the cached responses of the NDB server
+func (c *Cache) Invalidate(server string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, server+"/") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Invalidates all the cached responses
+func (c *Cache) InvalidateAll() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[string]*cacheEntry)
+}
+
+func (c *Cache) getEntry(key string) *cacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if now := c.now(); now.Sub(c.evictedAt) >= c.ttl {
+		c.evictExpired(now)
+		c.evictedAt = now
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// Removes the expired entries and the entries of failed fetches.
+// Entries locked by a lookup in progress are kept. Must be called with c.mutex held.
+func (c *Cache) evictExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if !entry.mutex.TryLock() {
+			continue
+		}
+		if !entry.valid || now.Sub(entry.fetchedAt) >= c.ttl {
+			delete(c.entries, key)
+		}
+		entry.mutex.Unlock()
+	}
+}
+
+// Returns the server url of clients exposing it, the responses of other clients are not cached
+func getServerURL(ndbClient ndb_client.NDBClientHTTPInterface) (string, bool) {
+	withURL, ok := ndbClient.(interface{ GetServerURL() string })
+	if !ok {
+		return "", false
+	}
+	server := withURL.GetServerURL()
+	return server, server != ""
+}
+
+// Returns the cached response of the endpoint for the NDB server of the client,
+// calls fetch and caches its result if there is none or if it has expired
+func getCached[T any](ndbClient ndb_client.NDBClientHTTPInterface, kind, endpoint string, fetch func() (T, error)) (value T, err error) {
+	cache := sharedCache.Load()
+	server, ok := getServerURL(ndbClient)
+	if cache == nil || !ok {
+		return fetch()
+	}
+
+	entry := cache.getEntry(server + "/" + endpoint)
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	if entry.valid && cache.now().Sub(entry.fetchedAt) < cache.ttl {
+		cacheRequests.WithLabelValues(kind, "hit").Inc()
+		return entry.value.(T), nil
+	}
+	cacheRequests.WithLabelValues(kind, "miss").Inc()
+	if value, err = fetch(); err != nil {
+		return
+	}
+	entry.value, entry.fetchedAt, entry.valid = value, cache.now(), true
+	return
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a mock NDB server listing one profile and SLA, the number of requests is tracked in requests
+func getCacheTestServer(requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Write([]byte(`[{"id": "1", "name": "entity-1"}]`))
+	}))
+}
+
+// Sets a new shared cache for the test, returns it with a function restoring the previous cache
+func setTestCache(ttl time.Duration) (*Cache, func()) {
+	previous := sharedCache.Load()
+	cache := NewCache(ttl)
+	SetCache(cache)
+	return cache, func() { SetCache(previous) }
+}
+
+// Tests the shared cache, tests the following cases:
+//  1. Repeated lookups are served from the cache
+//  2. Different endpoints (filters) are cached separately
+//  3. Entries expire after the TTL
+//  4. Invalidated entries are fetched again
+func TestCache(t *testing.T) {
+	var requests int32
+	server := getCacheTestServer(&requests)
+	defer server.Close()
+	cache, restore := setTestCache(time.Minute)
+	defer restore()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+	ctx := context.Background()
+	hits := func() float64 { return testutil.ToFloat64(cacheRequests.WithLabelValues("profiles", "hit")) }
+	initialHits := hits()
+
+	// 1. Repeated lookups are served from the cache
+	for i := 0; i < 3; i++ {
+		profiles, err := GetAllProfiles(ctx, ndbClient)
+		assert.NoError(t, err)
+		assert.Len(t, profiles, 1)
+	}
+	assert.Equal(t, int32(1), requests)
+	assert.Equal(t, float64(2), hits()-initialHits)
+
+	// 2. Different endpoints are cached separately
+	_, err := ListProfiles(ctx, ndbClient, ListOptions{Filters: map[string]string{"type": "Compute"}})
+	assert.NoError(t, err)
+	_, err = GetAllSLAs(ctx, ndbClient)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), requests)
+
+	// 3. Entries expire after the TTL
+	now = now.Add(time.Minute)
+	_, err = GetAllProfiles(ctx, ndbClient)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), requests)
+
+	// 4. Invalidated entries are fetched again
+	InvalidateCache(ndbClient)
+	_, err = GetAllSLAs(ctx, ndbClient)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), requests)
+}
+
+func TestCache_concurrentLookupsSendOneRequest(t *testing.T) {
+	var requests int32
+	server := getCacheTestServer(&requests)
+	defer server.Close()
+	_, restore := setTestCache(time.Minute)
+	defer restore()
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := GetAllClusters(context.Background(), ndbClient)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), requests)
+}
+
+func TestCache_errorsAreNotCached(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	_, restore := setTestCache(time.Minute)
+	defer restore()
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+
+	for i := 0; i < 2; i++ {
+		_, err := GetAllSLAs(context.Background(), ndbClient)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), requests)
+}
+
+func TestCache_expiredEntriesAreEvicted(t *testing.T) {
+	var requests int32
+	server := getCacheTestServer(&requests)
+	defer server.Close()
+	cache, restore := setTestCache(time.Minute)
+	defer restore()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+
+	_, err := GetAllProfiles(context.Background(), ndbClient)
+	assert.NoError(t, err)
+	_, err = GetAllSLAs(context.Background(), ndbClient)
+	assert.NoError(t, err)
+	assert.Len(t, cache.entries, 2)
+
+	// The expired profiles and SLAs are evicted with the next lookup
+	now = now.Add(time.Minute)
+	_, err = GetAllClusters(context.Background(), ndbClient)
+	assert.NoError(t, err)
+	assert.Len(t, cache.entries, 1)
+}
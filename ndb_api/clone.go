@@ -25,9 +25,14 @@ import (
 
 // Fetches all the clones on the NDB instance and retutns a slice of the databases
 func GetAllClones(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface) (clones []DatabaseResponse, err error) {
+	return ListClones(ctx, ndbClient, ListOptions{Detailed: true})
+}
+
+// Fetches the clones on the NDB instance matching the list options and returns a slice of the databases
+func ListClones(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, opts ListOptions) (clones []DatabaseResponse, err error) {
 	log := ctrllog.FromContext(ctx)
-	if _, err = sendRequest(ctx, ndbClient, http.MethodGet, "clones?detailed=true", nil, &clones); err != nil {
-		log.Error(err, "Error in GetAllClones")
+	if clones, err = list(ctx, ndbClient, "clones", opts, func(d DatabaseResponse) string { return d.Id }); err != nil {
+		log.Error(err, "Error in ListClones")
 		return
 	}
 	return
@@ -82,3 +87,19 @@ func DeprovisionClone(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInt
 	}
 	return
 }
+
+// Updates the clone with the given id as per the update request
+// Returns the updated clone
+func UpdateClone(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, id string, req *DatabaseUpdateRequest) (clone *DatabaseResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	if id == "" {
+		err = fmt.Errorf("id is empty")
+		log.Error(err, "no clone id provided")
+		return
+	}
+	if _, err = sendRequest(ctx, ndbClient, http.MethodPatch, "clones/"+id, req, &clone); err != nil {
+		log.Error(err, "Error in UpdateClone")
+		return
+	}
+	return
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -43,6 +44,11 @@ func GenerateDeprovisionCloneRequest() (req *CloneDeprovisionRequest) {
 func GenerateCloningRequest(ctx context.Context, ndb_client ndb_client.NDBClientHTTPInterface, database DatabaseInterface, reqData map[string]interface{}) (requestBody *DatabaseCloneRequest, err error) {
 	log := ctrllog.FromContext(ctx)
 	log.Info("Entered ndb_api.GenerateCloningRequest", "database name", database.GetName())
+	ctx, span := tracing.StartSpan(ctx, "ndb_api.GenerateCloningRequest", tracing.ATTRIBUTE_DATABASE_NAME.String(database.GetName()))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 
 	sourceDatabase, err := GetDatabaseById(ctx, ndb_client, database.GetCloneSourceDBId())
 	if err != nil {
@@ -90,12 +96,18 @@ func GenerateCloningRequest(ctx context.Context, ndb_client ndb_client.NDBClient
 		},
 		// Added by request appenders as per the engine
 		ActionArguments:            []ActionArgument{},
-		Tags:                       make([]interface{}, 0),
+		Tags:                       make([]Tag, 0),
 		VmPassword:                 "",
 		ComputeProfileId:           profilesMap[common.PROFILE_TYPE_COMPUTE].Id,
 		NetworkProfileId:           profilesMap[common.PROFILE_TYPE_NETWORK].Id,
 		DatabaseParameterProfileId: profilesMap[common.PROFILE_TYPE_DATABASE_PARAMETER].Id,
 	}
+	if tags, ok := reqData[common.NDB_PARAM_TAGS].([]Tag); ok {
+		requestBody.Tags = tags
+	}
+	if tags, ok := reqData[common.NDB_PARAM_DATABASE_SERVER_TAGS].([]Tag); ok {
+		requestBody.Nodes[0].Tags = tags
+	}
 	// Appending request body based on database type
 	appender, err := GetRequestAppender(databaseType)
 	if err != nil {
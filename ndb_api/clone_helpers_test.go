@@ -52,7 +52,7 @@ func TestGenerateCloningRequest(t *testing.T) {
 	mockNDBClient.On("NewRequest", http.MethodGet, "databases/test-sourcedb-id?detailed=true", nil).Return(reqGetDatabaseById, nil)
 	mockNDBClient.On("Do", reqGetDatabaseById).Return(resGetDatabaseById, nil)
 	mockDatabase.On("GetProfileResolvers").Once().Return(ProfileResolvers{})
-	mockNDBClient.On("NewRequest", http.MethodGet, "profiles", nil).Return(nil, errors.New("profiles-error")).Once()
+	mockNDBClient.On("NewRequest", http.MethodGet, "profiles?type=Compute", nil).Return(nil, errors.New("profiles-error")).Once()
 
 	tests := []struct {
 		name            string
@@ -42,7 +42,7 @@ type DatabaseCloneRequest struct {
 	NodeCount                  int              `json:"nodeCount"`
 	Nodes                      []Node           `json:"nodes"`
 	ActionArguments            []ActionArgument `json:"actionArguments"`
-	Tags                       interface{}      `json:"tags"`
+	Tags                       []Tag            `json:"tags"`
 	LcmConfig                  *LcmConfig       `json:"lcmConfig,omitempty"`
 	VmPassword                 string           `json:"vmPassword"`
 	ComputeProfileId           string           `json:"computeProfileId"`
@@ -242,3 +242,82 @@ func TestDeprovisionClone(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateClone(t *testing.T) {
+	type args struct {
+		ctx       context.Context
+		ndbClient ndb_client.NDBClientHTTPInterface
+		id        string
+		req       *DatabaseUpdateRequest
+	}
+	updateReq := &DatabaseUpdateRequest{Name: "test-name", Description: "test-description", ResetDescription: true}
+
+	// Mocks of the NDB Client interface
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+
+	mockNDBClient.On("NewRequest", http.MethodPatch, "clones/cloneid", updateReq).Once().Return(nil, errors.New("mock-error-new-request"))
+
+	req := &http.Request{}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"id":"cloneid", "name":"test-name", "description":"test-description"}`)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodPatch, "clones/cloneid", updateReq).Once().Return(req, nil)
+	mockNDBClient.On("Do", req).Once().Return(res, nil)
+	tests := []struct {
+		name      string
+		args      args
+		wantClone *DatabaseResponse
+		wantErr   bool
+	}{
+		{
+			name: "Test 1: UpdateClone returns an error when a request with empty id is passed to it",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "",
+				req:       updateReq,
+			},
+			wantClone: nil,
+			wantErr:   true,
+		},
+		{
+			name: "Test 2: UpdateClone returns an error when sendRequest returns an error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "cloneid",
+				req:       updateReq,
+			},
+			wantClone: nil,
+			wantErr:   true,
+		},
+		{
+			name: "Test 3: UpdateClone returns the updated clone when sendRequest returns a response without error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "cloneid",
+				req:       updateReq,
+			},
+			wantClone: &DatabaseResponse{
+				Id:          "cloneid",
+				Name:        "test-name",
+				Description: "test-description",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotClone, err := UpdateClone(tt.args.ctx, tt.args.ndbClient, tt.args.id, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateClone() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotClone, tt.wantClone) {
+				t.Errorf("UpdateClone() = %v, want %v", gotClone, tt.wantClone)
+			}
+		})
+	}
+}
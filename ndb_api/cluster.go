@@ -0,0 +1,39 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Fetches and returns all the Nutanix clusters registered with NDB as a cluster slice, served from the cache if enabled
+func GetAllClusters(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface) (clusters []ClusterResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	clusters, err = getCached(ndbClient, "clusters", "clusters", func() (clusters []ClusterResponse, err error) {
+		_, err = sendRequest(ctx, ndbClient, http.MethodGet, "clusters", nil, &clusters)
+		return
+	})
+	if err != nil {
+		log.Error(err, "Error in GetAllClusters")
+		return
+	}
+	return
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common/util"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// Fetches all the clusters from the ndb and returns the cluster matching the name
+// Returns an error listing the names of the available clusters if not found.
+func GetClusterByName(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, name string) (cluster ClusterResponse, err error) {
+	clusters, err := GetAllClusters(ctx, ndbClient)
+	if err != nil {
+		return
+	}
+	cluster, err = util.FindFirst(clusters, func(c ClusterResponse) bool { return c.Name == name })
+	if err != nil {
+		names := make([]string, len(clusters))
+		for i, c := range clusters {
+			names[i] = c.Name
+		}
+		err = fmt.Errorf("cluster %s not found, available clusters: [%s]", name, strings.Join(names, ", "))
+	}
+	return
+}
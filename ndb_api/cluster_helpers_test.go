@@ -0,0 +1,91 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// Tests the GetClusterByName function, tests the following cases:
+// 1. Cluster for a given name exists.
+// 2. Cluster for a given name does not exist, the error lists the available clusters.
+// 3. Unable to fetch all clusters to filter.
+func TestGetClusterByName(t *testing.T) {
+	CLUSTER_RESPONSES := []ClusterResponse{
+		{Id: "cluster-1-id", Name: "staging"},
+		{Id: "cluster-2-id", Name: "production"},
+	}
+	tests := []struct {
+		clusterName             string
+		responseMap             map[string]interface{}
+		responseStatus          int
+		expectedClusterResponse ClusterResponse
+		expectedError           error
+	}{
+		// Cluster for a given name exists
+		{
+			clusterName: "production",
+			responseMap: map[string]interface{}{
+				"GET /clusters": CLUSTER_RESPONSES,
+			},
+			expectedClusterResponse: CLUSTER_RESPONSES[1],
+			expectedError:           nil,
+		},
+		// Cluster for a given name does not exist.
+		{
+			clusterName: "development",
+			responseMap: map[string]interface{}{
+				"GET /clusters": CLUSTER_RESPONSES,
+			},
+			expectedClusterResponse: ClusterResponse{},
+			expectedError:           fmt.Errorf("cluster development not found, available clusters: [staging, production]"),
+		},
+		// Unable to fetch all clusters to filter.
+		{
+			clusterName:             "production",
+			responseStatus:          http.StatusBadRequest,
+			expectedClusterResponse: ClusterResponse{},
+			expectedError:           fmt.Errorf("GET clusters error, status: 400"),
+		},
+	}
+
+	for _, tc := range tests {
+		var server *httptest.Server
+		if tc.responseStatus != 0 {
+			server = GetServerTestHelperWithStatus(t, tc.responseStatus)
+		} else {
+			server = GetServerTestHelperWithResponseMap(t, tc.responseMap)
+		}
+		defer server.Close()
+		ndb_client := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+
+		cluster, err := GetClusterByName(context.Background(), ndb_client, tc.clusterName)
+		if !reflect.DeepEqual(tc.expectedClusterResponse, cluster) {
+			t.Fatalf("expected: %v, got: %v", tc.expectedClusterResponse, cluster)
+		}
+		if tc.expectedError != err && tc.expectedError.Error() != err.Error() {
+			t.Fatalf("expected: %v, got: %v", tc.expectedError, err)
+		}
+	}
+}
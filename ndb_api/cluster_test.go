@@ -0,0 +1,83 @@
+package ndb_api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+func TestGetAllClusters(t *testing.T) {
+	type args struct {
+		ctx       context.Context
+		ndbClient ndb_client.NDBClientHTTPInterface
+	}
+
+	// Mocks of the NDB Client interface
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+
+	mockNDBClient.On("NewRequest", http.MethodGet, "clusters", nil).Once().Return(nil, errors.New("mock-error-new-request"))
+
+	req := &http.Request{Method: http.MethodGet}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			`[{"id":"1", "name":"cluster-1"},{"id":"2", "name":"cluster-2"}]`,
+		)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodGet, "clusters", nil).Once().Return(req, nil)
+	mockNDBClient.On("Do", req).Once().Return(res, nil)
+
+	tests := []struct {
+		name         string
+		args         args
+		wantClusters []ClusterResponse
+		wantErr      bool
+	}{
+		{
+			name: "Test 1: GetAllClusters returns an error when sendRequest returns an error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+			},
+			wantClusters: nil,
+			wantErr:      true,
+		},
+		{
+			name: "Test 2: GetAllClusters returns a slice of clusters when sendRequest does not return an error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+			},
+			wantClusters: []ClusterResponse{
+				{
+					Id:   "1",
+					Name: "cluster-1",
+				},
+				{
+					Id:   "2",
+					Name: "cluster-2",
+				},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotClusters, err := GetAllClusters(tt.args.ctx, tt.args.ndbClient)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAllClusters() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotClusters, tt.wantClusters) {
+				t.Errorf("GetAllClusters() = %v, want %v", gotClusters, tt.wantClusters)
+			}
+		})
+	}
+}
@@ -20,12 +20,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -34,6 +38,17 @@ import (
 func sendRequest(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, method, endpoint string, payload interface{}, responseBody interface{}) (resp *http.Response, err error) {
 	log := ctrllog.FromContext(ctx)
 	log.Info("Entered ndb_api.sendRequest", "Method", method, "Endpoint", endpoint)
+	ctx, span := tracing.StartSpan(ctx, "NDB "+method+" "+getEndpointLabel(endpoint),
+		semconv.HTTPMethod(method),
+		attribute.String("ndb.endpoint", endpoint),
+	)
+	defer func() {
+		if resp != nil {
+			span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+		}
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 
 	if ndbClient == nil {
 		err = errors.New("nil reference: received nil reference for ndbClient")
@@ -45,17 +60,23 @@ func sendRequest(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterfac
 		return
 	}
 
-	req, err := ndbClient.NewRequest(method, endpoint, payload)
+	req, err := ndbClient.NewRequest(ctx, method, endpoint, payload)
 	if err != nil {
 		log.Error(err, "An error occurred while creating the HTTP request")
 		return
 	}
 
+	start := time.Now()
 	res, err := ndbClient.Do(req)
+	resp = res
 	if err != nil {
+		observeRequest(method, endpoint, 0, start)
 		log.Error(err, "An error occurred while calling the HTTP endpoint")
 		return
 	}
+	if res != nil {
+		observeRequest(method, endpoint, res.StatusCode, start)
+	}
 
 	// Read the NDB API response.
 	// Perform error checks.
@@ -69,10 +90,7 @@ func sendRequest(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterfac
 		}
 		// Considering any status >= 400 to be an error.
 		if res.StatusCode >= http.StatusBadRequest {
-			err = fmt.Errorf("%s %s error", method, endpoint)
-			if body != nil {
-				err = errors.Join(err, fmt.Errorf("ndb api error response status: %d, response body: %s", res.StatusCode, body))
-			}
+			err = newNDBError(method, endpoint, res.StatusCode, body)
 			log.Error(err, "NDB API error")
 			return
 		}
@@ -81,6 +99,7 @@ func sendRequest(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterfac
 			log.Error(err, "Error occurred while unmarshalling the response body")
 			return
 		}
+		setResponseAttributes(span, responseBody)
 	} else {
 		log.Info("Received an empty/nil response")
 	}
@@ -88,6 +107,26 @@ func sendRequest(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterfac
 	return
 }
 
+// Adds the ids of the NDB entity and operation in the response (if any) to the span
+func setResponseAttributes(span trace.Span, responseBody interface{}) {
+	switch response := responseBody.(type) {
+	case **TaskInfoSummaryResponse:
+		if *response != nil {
+			span.SetAttributes(
+				tracing.ATTRIBUTE_ENTITY_ID.String((*response).EntityId),
+				tracing.ATTRIBUTE_OPERATION_ID.String((*response).OperationId),
+			)
+		}
+	case **OperationResponse:
+		if *response != nil {
+			span.SetAttributes(
+				tracing.ATTRIBUTE_OPERATION_ID.String((*response).Id),
+				tracing.ATTRIBUTE_OPERATION_STATUS.String((*response).Status),
+			)
+		}
+	}
+}
+
 func GetDatabaseEngineName(dbType string) string {
 	switch dbType {
 	case common.DATABASE_TYPE_POSTGRES:
@@ -30,12 +30,19 @@ type DatabaseServer struct {
 	NxClusterId string   `json:"nxClusterId"`
 }
 
+// Value of a tag on an NDB entity (database, clone, time machine, database server), the tag has to be defined on NDB
+type Tag struct {
+	TagId   string `json:"tagId"`
+	TagName string `json:"tagName"`
+	Value   string `json:"value"`
+}
+
 type TimeMachineInfo struct {
 	Name             string   `json:"name"`
 	Description      string   `json:"description"`
 	SlaId            string   `json:"slaId"`
 	Schedule         Schedule `json:"schedule"`
-	Tags             []string `json:"tags"`
+	Tags             []Tag    `json:"tags"`
 	AutoTuneLogDrive bool     `json:"autoTuneLogDrive"`
 }
 
@@ -94,6 +101,7 @@ type Node struct {
 	NewDbServerTimeZone string   `json:"newDbServerTimeZone,omitempty"`
 	NxClusterId         string   `json:"nxClusterId,omitempty"`
 	Properties          []string `json:"properties"`
+	Tags                []Tag    `json:"tags,omitempty"`
 }
 
 type Property struct {
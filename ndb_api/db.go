@@ -27,9 +27,14 @@ import (
 
 // Fetches all the databases on the NDB instance and retutns a slice of the databases
 func GetAllDatabases(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface) (databases []DatabaseResponse, err error) {
+	return ListDatabases(ctx, ndbClient, ListOptions{Detailed: true})
+}
+
+// Fetches the databases on the NDB instance matching the list options and returns a slice of the databases
+func ListDatabases(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, opts ListOptions) (databases []DatabaseResponse, err error) {
 	log := ctrllog.FromContext(ctx)
-	if _, err = sendRequest(ctx, ndbClient, http.MethodGet, "databases?detailed=true", nil, &databases); err != nil {
-		log.Error(err, "Error in GetAllDatabases")
+	if databases, err = list(ctx, ndbClient, "databases", opts, func(d DatabaseResponse) string { return d.Id }); err != nil {
+		log.Error(err, "Error in ListDatabases")
 		return
 	}
 	return
@@ -95,3 +100,19 @@ func DeprovisionDatabase(ctx context.Context, ndbClient ndb_client.NDBClientHTTP
 	}
 	return
 }
+
+// Updates the database with the given id as per the update request
+// Returns the updated database
+func UpdateDatabase(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, id string, req *DatabaseUpdateRequest) (database *DatabaseResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	if id == "" {
+		err = fmt.Errorf("id is empty")
+		log.Error(err, "no database id provided")
+		return
+	}
+	if _, err = sendRequest(ctx, ndbClient, http.MethodPatch, "databases/"+id, req, &database); err != nil {
+		log.Error(err, "Error in UpdateDatabase")
+		return
+	}
+	return
+}
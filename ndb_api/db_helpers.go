@@ -23,6 +23,7 @@ import (
 	"strconv"
 
 	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
 	"github.com/nutanix-cloud-native/ndb-operator/common/util"
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
@@ -33,6 +34,11 @@ import (
 func GenerateProvisioningRequest(ctx context.Context, ndb_client *ndb_client.NDBClient, database DatabaseInterface, reqData map[string]interface{}) (requestBody *DatabaseProvisionRequest, err error) {
 	log := ctrllog.FromContext(ctx)
 	log.Info("Entered ndb_api.GenerateProvisioningRequest", "database name", database.GetName(), "database type", database.GetInstanceType())
+	ctx, span := tracing.StartSpan(ctx, "ndb_api.GenerateProvisioningRequest", tracing.ATTRIBUTE_DATABASE_NAME.String(database.GetName()))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 
 	// Fetching the TM details
 	tmName, tmDescription, slaName := database.GetInstanceTMDetails()
@@ -87,7 +93,7 @@ func GenerateProvisioningRequest(ctx context.Context, ndb_client *ndb_client.NDB
 			Description:      tmDescription,
 			SlaId:            sla.Id,
 			Schedule:         schedule,
-			Tags:             make([]string, 0),
+			Tags:             make([]Tag, 0),
 			AutoTuneLogDrive: true,
 		},
 		Nodes: []Node{
@@ -108,6 +114,16 @@ func GenerateProvisioningRequest(ctx context.Context, ndb_client *ndb_client.NDB
 		},
 	}
 
+	if tags, ok := reqData[common.NDB_PARAM_TAGS].([]Tag); ok {
+		requestBody.Tags = tags
+	}
+	if tags, ok := reqData[common.NDB_PARAM_TIME_MACHINE_TAGS].([]Tag); ok {
+		requestBody.TimeMachineInfo.Tags = tags
+	}
+	if tags, ok := reqData[common.NDB_PARAM_DATABASE_SERVER_TAGS].([]Tag); ok {
+		requestBody.Nodes[0].Tags = tags
+	}
+
 	// Appending request body based on database type
 	appender, err := GetRequestAppender(database.GetInstanceType())
 	if err != nil {
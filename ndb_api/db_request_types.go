@@ -36,6 +36,7 @@ type DatabaseProvisionRequest struct {
 	ActionArguments          []ActionArgument `json:"actionArguments"`
 	Nodes                    []Node           `json:"nodes"`
 	DatabaseName             string           `json:"databaseName,omitempty"`
+	Tags                     []Tag            `json:"tags,omitempty"`
 }
 
 type DatabaseDeprovisionRequest struct {
@@ -46,3 +47,13 @@ type DatabaseDeprovisionRequest struct {
 	DeleteTimeMachine    bool `json:"deleteTimeMachine"`
 	DeleteLogicalCluster bool `json:"deleteLogicalCluster"`
 }
+
+// Updates the name, description and tags of a database, the fields are only changed if their reset flag is set
+type DatabaseUpdateRequest struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Tags             []Tag  `json:"tags,omitempty"`
+	ResetName        bool   `json:"resetName"`
+	ResetDescription bool   `json:"resetDescription"`
+	ResetTags        bool   `json:"resetTags"`
+}
@@ -19,9 +19,13 @@ package ndb_api
 type DatabaseResponse struct {
 	Id            string         `json:"id"`
 	Name          string         `json:"name"`
+	Description   string         `json:"description"`
 	Status        string         `json:"status"`
 	DatabaseNodes []DatabaseNode `json:"databaseNodes"`
 	Properties    []Property     `json:"properties"`
 	TimeMachineId string         `json:"timeMachineId"`
 	Type          string         `json:"type"`
+	Tags          []Tag          `json:"tags"`
+	// In the OPERATION_TIME_LAYOUT (UTC)
+	DateCreated string `json:"dateCreated"`
 }
@@ -299,3 +299,82 @@ func TestDeprovisionDatabase(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateDatabase(t *testing.T) {
+	type args struct {
+		ctx       context.Context
+		ndbClient ndb_client.NDBClientHTTPInterface
+		id        string
+		req       *DatabaseUpdateRequest
+	}
+	updateReq := &DatabaseUpdateRequest{Name: "test-name", Description: "test-description", ResetDescription: true}
+
+	// Mocks of the NDB Client interface
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+
+	mockNDBClient.On("NewRequest", http.MethodPatch, "databases/databaseid", updateReq).Once().Return(nil, errors.New("mock-error-new-request"))
+
+	req := &http.Request{}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"id":"databaseid", "name":"test-name", "description":"test-description"}`)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodPatch, "databases/databaseid", updateReq).Once().Return(req, nil)
+	mockNDBClient.On("Do", req).Once().Return(res, nil)
+	tests := []struct {
+		name         string
+		args         args
+		wantDatabase *DatabaseResponse
+		wantErr      bool
+	}{
+		{
+			name: "Test 1: UpdateDatabase returns an error when a request with empty id is passed to it",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "",
+				req:       updateReq,
+			},
+			wantDatabase: nil,
+			wantErr:      true,
+		},
+		{
+			name: "Test 2: UpdateDatabase returns an error when sendRequest returns an error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "databaseid",
+				req:       updateReq,
+			},
+			wantDatabase: nil,
+			wantErr:      true,
+		},
+		{
+			name: "Test 3: UpdateDatabase returns the updated database when sendRequest returns a response without error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "databaseid",
+				req:       updateReq,
+			},
+			wantDatabase: &DatabaseResponse{
+				Id:          "databaseid",
+				Name:        "test-name",
+				Description: "test-description",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDatabase, err := UpdateDatabase(tt.args.ctx, tt.args.ndbClient, tt.args.id, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateDatabase() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotDatabase, tt.wantDatabase) {
+				t.Errorf("UpdateDatabase() = %v, want %v", gotDatabase, tt.wantDatabase)
+			}
+		})
+	}
+}
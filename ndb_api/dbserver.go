@@ -25,6 +25,32 @@ import (
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// Fetches and returns a database server by an Id
+func GetDatabaseServerById(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, id string) (dbServer *DatabaseServerResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	// Checking if id is empty, this is necessary otherwise the request becomes a call to get all database servers (/dbservers)
+	if id == "" {
+		err = fmt.Errorf("database server id is empty")
+		log.Error(err, "no database server id provided")
+		return
+	}
+	if _, err = sendRequest(ctx, ndbClient, http.MethodGet, "dbservers/"+id, nil, &dbServer); err != nil {
+		log.Error(err, "Error in GetDatabaseServerById")
+		return
+	}
+	return
+}
+
+// Fetches the database servers on the NDB instance matching the list options and returns a slice of the database servers
+func ListDatabaseServers(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, opts ListOptions) (dbServers []DatabaseServerResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	if dbServers, err = list(ctx, ndbClient, "dbservers", opts, func(d DatabaseServerResponse) string { return d.Id }); err != nil {
+		log.Error(err, "Error in ListDatabaseServers")
+		return
+	}
+	return
+}
+
 // Deprovisions a database server vm given a server id
 // Returns the task info summary response for the operation
 func DeprovisionDatabaseServer(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, id string, req *DatabaseServerDeprovisionRequest) (task *TaskInfoSummaryResponse, err error) {
@@ -40,3 +66,19 @@ func DeprovisionDatabaseServer(ctx context.Context, ndbClient ndb_client.NDBClie
 	}
 	return
 }
+
+// Updates the database server with the given id as per the update request
+// Returns the updated database server
+func UpdateDatabaseServer(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, id string, req *DatabaseServerUpdateRequest) (dbServer *DatabaseServerResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	if id == "" {
+		err = fmt.Errorf("id is empty")
+		log.Error(err, "no database server id provided")
+		return
+	}
+	if _, err = sendRequest(ctx, ndbClient, http.MethodPatch, "dbservers/"+id, req, &dbServer); err != nil {
+		log.Error(err, "Error in UpdateDatabaseServer")
+		return
+	}
+	return
+}
@@ -23,3 +23,13 @@ type DatabaseServerDeprovisionRequest struct {
 	DeleteVgs         bool `json:"deleteVgs"`
 	DeleteVmSnapshots bool `json:"deleteVmSnapshots"`
 }
+
+// Updates the name, description and tags of a database server, the fields are only changed if their reset flag is set
+type DatabaseServerUpdateRequest struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Tags             []Tag  `json:"tags,omitempty"`
+	ResetName        bool   `json:"resetName"`
+	ResetDescription bool   `json:"resetDescription"`
+	ResetTags        bool   `json:"resetTags"`
+}
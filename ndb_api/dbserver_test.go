@@ -28,6 +28,126 @@ import (
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
 )
 
+func TestGetDatabaseServerById(t *testing.T) {
+	type args struct {
+		ctx       context.Context
+		ndbClient ndb_client.NDBClientHTTPInterface
+		id        string
+	}
+	// Mocks of the NDB Client interface
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+
+	mockNDBClient.On("NewRequest", http.MethodGet, "dbservers/dbserverid", nil).Once().Return(nil, errors.New("mock-error-new-request"))
+
+	req := &http.Request{}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"id":"dbserverid", "properties":[{"name":"compute_profile_id", "value":"profile-id"}]}`)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodGet, "dbservers/dbserverid", nil).Once().Return(req, nil)
+	mockNDBClient.On("Do", req).Once().Return(res, nil)
+	tests := []struct {
+		name         string
+		args         args
+		wantDbServer *DatabaseServerResponse
+		wantErr      bool
+	}{
+		{
+			name: "Test 1: GetDatabaseServerById returns an error when a request with empty id is passed to it",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "",
+			},
+			wantDbServer: nil,
+			wantErr:      true,
+		},
+		{
+			name: "Test 2: GetDatabaseServerById returns an error when sendRequest returns an error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "dbserverid",
+			},
+			wantDbServer: nil,
+			wantErr:      true,
+		},
+		{
+			name: "Test 3: GetDatabaseServerById returns a DatabaseServerResponse when sendRequest returns a response without error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "dbserverid",
+			},
+			wantDbServer: &DatabaseServerResponse{
+				Id:         "dbserverid",
+				Properties: []Property{{Name: "compute_profile_id", Value: "profile-id"}},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDbServer, err := GetDatabaseServerById(tt.args.ctx, tt.args.ndbClient, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDatabaseServerById() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotDbServer, tt.wantDbServer) {
+				t.Errorf("GetDatabaseServerById() = %v, want %v", gotDbServer, tt.wantDbServer)
+			}
+		})
+	}
+}
+
+func TestListDatabaseServers(t *testing.T) {
+	// Mocks of the NDB Client interface
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+
+	mockNDBClient.On("NewRequest", http.MethodGet, "dbservers", nil).Once().Return(nil, errors.New("mock-error-new-request"))
+
+	req := &http.Request{}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`[{"id":"dbserverid", "tags":[{"tagId":"tagid", "tagName":"ndb-operator-cluster", "value":"cluster-1"}]}]`)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodGet, "dbservers", nil).Once().Return(req, nil)
+	mockNDBClient.On("Do", req).Once().Return(res, nil)
+	tests := []struct {
+		name          string
+		wantDbServers []DatabaseServerResponse
+		wantErr       bool
+	}{
+		{
+			name:          "Test 1: ListDatabaseServers returns an error when sendRequest returns an error",
+			wantDbServers: nil,
+			wantErr:       true,
+		},
+		{
+			name: "Test 2: ListDatabaseServers returns the database servers when sendRequest returns a response without error",
+			wantDbServers: []DatabaseServerResponse{
+				{
+					Id:   "dbserverid",
+					Tags: []Tag{{TagId: "tagid", TagName: "ndb-operator-cluster", Value: "cluster-1"}},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDbServers, err := ListDatabaseServers(context.TODO(), mockNDBClient, ListOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ListDatabaseServers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotDbServers, tt.wantDbServers) {
+				t.Errorf("ListDatabaseServers() = %v, want %v", gotDbServers, tt.wantDbServers)
+			}
+		})
+	}
+}
+
 func TestDeprovisionDatabaseServer(t *testing.T) {
 	type args struct {
 		ctx       context.Context
@@ -103,3 +223,82 @@ func TestDeprovisionDatabaseServer(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateDatabaseServer(t *testing.T) {
+	type args struct {
+		ctx       context.Context
+		ndbClient ndb_client.NDBClientHTTPInterface
+		id        string
+		req       *DatabaseServerUpdateRequest
+	}
+	updateReq := &DatabaseServerUpdateRequest{Name: "test-name", Tags: []Tag{{TagId: "tagid", TagName: "team", Value: "dba"}}, ResetTags: true}
+
+	// Mocks of the NDB Client interface
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+
+	mockNDBClient.On("NewRequest", http.MethodPatch, "dbservers/dbserverid", updateReq).Once().Return(nil, errors.New("mock-error-new-request"))
+
+	req := &http.Request{}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"id":"dbserverid", "name":"test-name", "tags":[{"tagId":"tagid", "tagName":"team", "value":"dba"}]}`)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodPatch, "dbservers/dbserverid", updateReq).Once().Return(req, nil)
+	mockNDBClient.On("Do", req).Once().Return(res, nil)
+	tests := []struct {
+		name         string
+		args         args
+		wantDBServer *DatabaseServerResponse
+		wantErr      bool
+	}{
+		{
+			name: "Test 1: UpdateDatabaseServer returns an error when a request with empty id is passed to it",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "",
+				req:       updateReq,
+			},
+			wantDBServer: nil,
+			wantErr:      true,
+		},
+		{
+			name: "Test 2: UpdateDatabaseServer returns an error when sendRequest returns an error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "dbserverid",
+				req:       updateReq,
+			},
+			wantDBServer: nil,
+			wantErr:      true,
+		},
+		{
+			name: "Test 3: UpdateDatabaseServer returns the updated database server when sendRequest returns a response without error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				id:        "dbserverid",
+				req:       updateReq,
+			},
+			wantDBServer: &DatabaseServerResponse{
+				Id:   "dbserverid",
+				Name: "test-name",
+				Tags: []Tag{{TagId: "tagid", TagName: "team", Value: "dba"}},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDBServer, err := UpdateDatabaseServer(tt.args.ctx, tt.args.ndbClient, tt.args.id, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateDatabaseServer() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotDBServer, tt.wantDBServer) {
+				t.Errorf("UpdateDatabaseServer() = %v, want %v", gotDBServer, tt.wantDBServer)
+			}
+		})
+	}
+}
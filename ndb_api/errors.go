@@ -0,0 +1,113 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// NDBError is returned for responses from the NDB API with a status of 400 or higher.
+// The error code, reason and remedy are parsed from NDB's JSON error payload when present.
+type NDBError struct {
+	Method     string `json:"-"`
+	Endpoint   string `json:"-"`
+	StatusCode int    `json:"-"`
+	ErrorCode  string `json:"errorCode"`
+	Reason     string `json:"reason"`
+	Remedy     string `json:"remedy"`
+	Message    string `json:"message"`
+	// Raw response body, set if it is not an NDB error payload
+	Body string `json:"-"`
+}
+
+// Returns an NDBError for the response status and body of a failed request
+func newNDBError(method, endpoint string, statusCode int, body []byte) *NDBError {
+	ndbError := &NDBError{}
+	if err := json.Unmarshal(body, ndbError); err != nil || (ndbError.ErrorCode == "" && ndbError.Reason == "" && ndbError.Message == "") {
+		ndbError = &NDBError{Body: strings.TrimSpace(string(body))}
+	}
+	ndbError.Method = method
+	ndbError.Endpoint = endpoint
+	ndbError.StatusCode = statusCode
+	return ndbError
+}
+
+func (e *NDBError) Error() string {
+	msg := fmt.Sprintf("%s %s error, status: %d", e.Method, e.Endpoint, e.StatusCode)
+	if e.ErrorCode != "" {
+		msg += ", error code: " + e.ErrorCode
+	}
+	if e.Reason != "" {
+		msg += ", reason: " + e.Reason
+	} else if e.Message != "" {
+		msg += ", message: " + e.Message
+	}
+	if e.Remedy != "" {
+		msg += ", remedy: " + e.Remedy
+	}
+	if e.Body != "" {
+		msg += ", response body: " + e.Body
+	}
+	return msg
+}
+
+// Returns the NDBError wrapped in err, nil if there is none
+func AsNDBError(err error) *NDBError {
+	var ndbError *NDBError
+	if errors.As(err, &ndbError) {
+		return ndbError
+	}
+	return nil
+}
+
+// Returns true if NDB responded that the requested entity does not exist
+func IsNotFound(err error) bool {
+	ndbError := AsNDBError(err)
+	return ndbError != nil && ndbError.StatusCode == http.StatusNotFound
+}
+
+// Returns true if NDB rejected the request because it conflicts with the current state of an entity
+func IsConflict(err error) bool {
+	ndbError := AsNDBError(err)
+	return ndbError != nil && ndbError.StatusCode == http.StatusConflict
+}
+
+// Returns true if NDB rejected the credentials of the request
+func IsUnauthorized(err error) bool {
+	ndbError := AsNDBError(err)
+	return ndbError != nil && (ndbError.StatusCode == http.StatusUnauthorized || ndbError.StatusCode == http.StatusForbidden)
+}
+
+// Returns true if the request may succeed when sent again: NDB responded with a 5xx,
+// 408 Request Timeout or 429 Too Many Requests, or the request did not get a response from NDB.
+// Other 4xx responses are terminal, sending the same request again fails again.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	ndbError := AsNDBError(err)
+	if ndbError == nil {
+		return true
+	}
+	return ndbError.StatusCode >= http.StatusInternalServerError ||
+		ndbError.StatusCode == http.StatusRequestTimeout ||
+		ndbError.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests the newNDBError function, tests the following cases:
+//  1. NDB's JSON error payload is parsed
+//  2. A body which is not an NDB error payload is kept as is
+func TestNewNDBError(t *testing.T) {
+	ndbError := newNDBError(http.MethodGet, "databases/id", http.StatusNotFound,
+		[]byte(`{"errorCode":"ERA-ENT-0000001","reason":"Database not found","remedy":"Check the database id","message":"","stackTrace":[]}`))
+	assert.Equal(t, http.StatusNotFound, ndbError.StatusCode)
+	assert.Equal(t, "ERA-ENT-0000001", ndbError.ErrorCode)
+	assert.Equal(t, "Database not found", ndbError.Reason)
+	assert.Equal(t, "Check the database id", ndbError.Remedy)
+	assert.Empty(t, ndbError.Body)
+	assert.Contains(t, ndbError.Error(), "Database not found")
+
+	ndbError = newNDBError(http.MethodPost, "databases/provision", http.StatusBadGateway, []byte("<html>Bad Gateway</html>"))
+	assert.Empty(t, ndbError.ErrorCode)
+	assert.Equal(t, "<html>Bad Gateway</html>", ndbError.Body)
+	assert.Contains(t, ndbError.Error(), "status: 502")
+}
+
+// Tests the NDBError helpers, tests the following cases:
+//  1. IsNotFound, IsConflict and IsUnauthorized match the status code
+//  2. 5xx, 408 and 429 responses and errors without a response are retryable
+//  3. Other 4xx responses are terminal
+//  4. Wrapped NDBErrors are matched
+func TestNDBErrorHelpers(t *testing.T) {
+	newError := func(statusCode int) error {
+		return newNDBError(http.MethodGet, "endpoint", statusCode, nil)
+	}
+	tests := []struct {
+		name             string
+		err              error
+		wantNotFound     bool
+		wantConflict     bool
+		wantUnauthorized bool
+		wantRetryable    bool
+	}{
+		{name: "Test 1: 404", err: newError(http.StatusNotFound), wantNotFound: true},
+		{name: "Test 2: 409", err: newError(http.StatusConflict), wantConflict: true},
+		{name: "Test 3: 401", err: newError(http.StatusUnauthorized), wantUnauthorized: true},
+		{name: "Test 4: 400", err: newError(http.StatusBadRequest)},
+		{name: "Test 5: 500", err: newError(http.StatusInternalServerError), wantRetryable: true},
+		{name: "Test 6: 429", err: newError(http.StatusTooManyRequests), wantRetryable: true},
+		{name: "Test 7: connection error", err: errors.New("connection refused"), wantRetryable: true},
+		{name: "Test 8: nil", err: nil},
+		{name: "Test 9: wrapped 404", err: fmt.Errorf("wrapped: %w", newError(http.StatusNotFound)), wantNotFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantNotFound, IsNotFound(tt.err), "IsNotFound")
+			assert.Equal(t, tt.wantConflict, IsConflict(tt.err), "IsConflict")
+			assert.Equal(t, tt.wantUnauthorized, IsUnauthorized(tt.err), "IsUnauthorized")
+			assert.Equal(t, tt.wantRetryable, IsRetryable(tt.err), "IsRetryable")
+		})
+	}
+}
+
+func Test_sendRequest_returnsNDBError(t *testing.T) {
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+	req := &http.Request{Method: http.MethodDelete}
+	res := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"errorCode":"ERA-ENT-0000001","reason":"Database not found"}`)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodDelete, "databases/id", nil).Return(req, nil)
+	mockNDBClient.On("Do", req).Return(res, nil)
+
+	_, err := sendRequest(context.Background(), mockNDBClient, http.MethodDelete, "databases/id", nil, &struct{}{})
+	ndbError := AsNDBError(err)
+	if assert.NotNil(t, ndbError) {
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, "ERA-ENT-0000001", ndbError.ErrorCode)
+		assert.Equal(t, "databases/id", ndbError.Endpoint)
+	}
+}
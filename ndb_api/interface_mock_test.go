@@ -143,7 +143,7 @@ func (m *MockDatabaseInterface) GetAdditionalArguments() map[string]string {
 	return map[string]string{}
 }
 
-func (m *MockNDBClientHTTPInterface) NewRequest(method, endpoint string, requestBody interface{}) (*http.Request, error) {
+func (m *MockNDBClientHTTPInterface) NewRequest(ctx context.Context, method, endpoint string, requestBody interface{}) (*http.Request, error) {
 	args := m.Called(method, endpoint, requestBody)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -0,0 +1,96 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+)
+
+// Options for the requests listing entities (databases, clones, profiles, SLAs) on NDB.
+// The zero value lists all the entities with the default (non-detailed) responses.
+type ListOptions struct {
+	// Filters the entities on NDB by the attribute ValueType (for example "name" or "id") matching Value
+	ValueType string
+	Value     string
+	// Fetches the detailed entities, including nested entities such as the database nodes and servers
+	Detailed bool
+	// Fetches the entities in pages of PageSize entities (limit and offset), 0 fetches all the entities at once.
+	// Only used by the endpoints supporting paging (databases and clones).
+	PageSize int
+	// Endpoint specific filters, for example "engine" or "type" for profiles
+	Filters map[string]string
+}
+
+// Returns the endpoint with the query parameters for the options
+func (o ListOptions) endpoint(path string, offset int) string {
+	query := url.Values{}
+	if o.ValueType != "" && o.Value != "" {
+		query.Set("value-type", o.ValueType)
+		query.Set("value", o.Value)
+	}
+	if o.Detailed {
+		query.Set("detailed", "true")
+	}
+	if o.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(o.PageSize))
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	for key, value := range o.Filters {
+		query.Set(key, value)
+	}
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// Sends the list request for the options, fetches all the pages if paging is requested.
+// Entities already fetched in an earlier page (for example shifted by an entity created
+// in the meantime) are skipped. Paging stops at the first page with less than PageSize
+// entities, or if NDB ignores the paging parameters (more than PageSize entities or
+// only already fetched entities are returned).
+func list[T any](ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, path string, opts ListOptions, getId func(T) string) (entities []T, err error) {
+	if opts.PageSize <= 0 {
+		_, err = sendRequest(ctx, ndbClient, http.MethodGet, opts.endpoint(path, 0), nil, &entities)
+		return
+	}
+	seen := make(map[string]bool)
+	for offset := 0; ; offset += opts.PageSize {
+		var page []T
+		if _, err = sendRequest(ctx, ndbClient, http.MethodGet, opts.endpoint(path, offset), nil, &page); err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, entity := range page {
+			if seen[getId(entity)] {
+				continue
+			}
+			seen[getId(entity)] = true
+			entities = append(entities, entity)
+			added++
+		}
+		if len(page) != opts.PageSize || added == 0 {
+			// Last page, or paging is not supported and the same page was returned again
+			return
+		}
+	}
+}
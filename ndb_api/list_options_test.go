@@ -0,0 +1,111 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListOptions_endpoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ListOptions
+		offset int
+		want   string
+	}{
+		{name: "Test 1: no options", opts: ListOptions{}, want: "profiles"},
+		{name: "Test 2: detailed", opts: ListOptions{Detailed: true}, want: "profiles?detailed=true"},
+		{name: "Test 3: value type filter", opts: ListOptions{ValueType: "name", Value: "db 1"}, want: "profiles?value=db+1&value-type=name"},
+		{name: "Test 4: paging", opts: ListOptions{PageSize: 10}, offset: 20, want: "profiles?limit=10&offset=20"},
+		{name: "Test 5: filters", opts: ListOptions{Filters: map[string]string{"engine": "postgres_database", "type": "Software"}}, want: "profiles?engine=postgres_database&type=Software"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.endpoint("profiles", tt.offset))
+		})
+	}
+}
+
+// Returns a mock NDB server listing count databases, in pages if supportsPaging is true.
+// The number of requests received is tracked in requests.
+func getListTestServer(count int, supportsPaging bool, createdAfterFirstPage int, requests *int) *httptest.Server {
+	databases := make([]DatabaseResponse, count)
+	for i := range databases {
+		databases[i] = DatabaseResponse{Id: fmt.Sprintf("db-%d", i)}
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if *requests == 2 {
+			// Databases created while paging shift the following pages
+			for i := 0; i < createdAfterFirstPage; i++ {
+				databases = append([]DatabaseResponse{{Id: fmt.Sprintf("new-db-%d", i)}}, databases...)
+			}
+		}
+		page := databases
+		if supportsPaging && r.URL.Query().Get("limit") != "" {
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			page = databases[min(offset, len(databases)):min(offset+limit, len(databases))]
+		}
+		body, _ := json.Marshal(page)
+		w.Write(body)
+	}))
+}
+
+// Tests the paging of ListDatabases, tests the following cases:
+//  1. All the pages are fetched
+//  2. A last full page is followed by an empty page
+//  3. Paging parameters ignored by NDB return all the databases at once
+//  4. Paging parameters ignored by NDB with exactly one page of databases
+//  5. Databases repeated in a later page (shifted by a database created while paging) are skipped
+func TestListDatabases_paging(t *testing.T) {
+	tests := []struct {
+		name                  string
+		count                 int
+		supportsPaging        bool
+		createdAfterFirstPage int
+		wantRequests          int
+	}{
+		{name: "Test 1: all the pages are fetched", count: 25, supportsPaging: true, wantRequests: 3},
+		{name: "Test 2: a last full page is followed by an empty page", count: 20, supportsPaging: true, wantRequests: 3},
+		{name: "Test 3: paging ignored by NDB", count: 25, supportsPaging: false, wantRequests: 1},
+		{name: "Test 4: paging ignored by NDB with exactly one page", count: 10, supportsPaging: false, wantRequests: 2},
+		{name: "Test 5: databases shifted to a later page are skipped", count: 25, supportsPaging: true, createdAfterFirstPage: 1, wantRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := getListTestServer(tt.count, tt.supportsPaging, tt.createdAfterFirstPage, &requests)
+			defer server.Close()
+			ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+
+			databases, err := ListDatabases(context.Background(), ndbClient, ListOptions{PageSize: 10})
+			assert.NoError(t, err)
+			assert.Len(t, databases, tt.count)
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
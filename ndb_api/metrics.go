@@ -0,0 +1,80 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ndb_api_requests_total",
+		Help: "Number of requests sent to NDB by endpoint, method and status (the HTTP status code or error)",
+	}, []string{"endpoint", "method", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ndb_api_request_duration_seconds",
+		Help:    "Latency of the requests sent to NDB by endpoint, method and status (the HTTP status code or error)",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"endpoint", "method", "status"})
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ndb_api_cache_requests_total",
+		Help: "Number of lookups of the NDB catalog cache by kind (profiles, slas, clusters) and result (hit, miss)",
+	}, []string{"kind", "result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestDuration, cacheRequests)
+}
+
+// Path segments of the NDB endpoints that are not ids or names
+var staticEndpointSegments = map[string]bool{
+	"clones":    true,
+	"provision": true,
+	"snapshots": true,
+	"validate":  true,
+}
+
+// Returns the endpoint without the query and with the ids and names replaced by
+// a placeholder (databases/<id>?detailed=true => databases/{id}), to keep the number
+// of label values of the request metrics bounded.
+func getEndpointLabel(endpoint string) string {
+	path, _, _ := strings.Cut(endpoint, "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	// The first segment is the resource
+	for i := 1; i < len(segments); i++ {
+		if !staticEndpointSegments[segments[i]] {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Records the count and latency of a request sent to NDB, statusCode is 0 if no response was received
+func observeRequest(method, endpoint string, statusCode int, start time.Time) {
+	status := "error"
+	if statusCode != 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	endpointLabel := getEndpointLabel(endpoint)
+	requestsTotal.WithLabelValues(endpointLabel, method, status).Inc()
+	requestDuration.WithLabelValues(endpointLabel, method, status).Observe(time.Since(start).Seconds())
+}
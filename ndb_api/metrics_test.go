@@ -0,0 +1,74 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEndpointLabel(t *testing.T) {
+	// Test cases for getEndpointLabel
+	testCases := []struct {
+		endpoint      string
+		expectedLabel string
+	}{
+		{"profiles?engine=postgres_database", "profiles"},
+		{"databases/provision", "databases/provision"},
+		{"databases/db-id?detailed=true", "databases/{id}"},
+		{"databases/db-name?value-type=name&detailed=true", "databases/{id}"},
+		{"tms/tm-id/clones", "tms/{id}/clones"},
+		{"tms/tm-id/snapshots", "tms/{id}/snapshots"},
+		{"operations/op-id?display=true", "operations/{id}"},
+		{"auth/validate", "auth/validate"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedLabel, getEndpointLabel(tc.endpoint))
+	}
+}
+
+// Tests that sendRequest records the requests by endpoint, method and status
+func TestSendRequest_recordsMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/operations/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id": "op-id"}`))
+	}))
+	defer server.Close()
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+	count := func(status string) float64 {
+		return testutil.ToFloat64(requestsTotal.WithLabelValues("operations/{id}", http.MethodGet, status))
+	}
+	initialOk, initialNotFound := count("200"), count("404")
+
+	_, err := GetOperationById(context.Background(), ndbClient, "op-id")
+	assert.NoError(t, err)
+	_, err = GetOperationById(context.Background(), ndbClient, "missing")
+	assert.Error(t, err)
+
+	assert.Equal(t, float64(1), count("200")-initialOk)
+	assert.Equal(t, float64(1), count("404")-initialNotFound)
+}
@@ -44,3 +44,16 @@ func GetOperationById(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInt
 	}
 	return
 }
+
+// Fetches the operations on NDB matching the list options, for example the operations
+// of an entity with the "entity-id" filter
+func ListOperations(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, opts ListOptions) (operations []OperationResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	var response OperationListResponse
+	if _, err = sendRequest(ctx, ndbClient, http.MethodGet, opts.endpoint("operations", 0), nil, &response); err != nil {
+		log.Error(err, "Error in ListOperations")
+		return
+	}
+	operations = response.Operations
+	return
+}
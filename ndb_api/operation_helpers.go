@@ -16,9 +16,14 @@ limitations under the License.
 
 package ndb_api
 
+import "time"
+
 const OPERATION_STATUS_FAILED = "FAILED"
 const OPERATION_STATUS_PASSED = "PASSED"
 
+// Layout of the start and end times of the operations on NDB (in UTC)
+const OPERATION_TIME_LAYOUT = "2006-01-02 15:04:05"
+
 // Returns an operation status string
 func GetOperationStatus(o *OperationResponse) string {
 	status := ""
@@ -37,3 +42,22 @@ func GetOperationStatus(o *OperationResponse) string {
 	}
 	return status
 }
+
+// Returns the start time of an operation
+func GetOperationStartTime(o *OperationResponse) (time.Time, error) {
+	return time.Parse(OPERATION_TIME_LAYOUT, o.StartTime)
+}
+
+// Returns the time taken by a completed operation
+func GetOperationDuration(o *OperationResponse) (duration time.Duration, err error) {
+	startTime, err := GetOperationStartTime(o)
+	if err != nil {
+		return
+	}
+	endTime, err := time.Parse(OPERATION_TIME_LAYOUT, o.EndTime)
+	if err != nil {
+		return
+	}
+	duration = endTime.Sub(startTime)
+	return
+}
@@ -18,6 +18,7 @@ package ndb_api
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -41,3 +42,28 @@ func TestGetOperationStatus(t *testing.T) {
 		assert.Equal(t, tc.expectedStatus, result)
 	}
 }
+
+func TestGetOperationDuration(t *testing.T) {
+	// Test cases for GetOperationDuration
+	testCases := []struct {
+		startTime        string
+		endTime          string
+		expectedDuration time.Duration
+		expectError      bool
+	}{
+		{"2023-10-12 06:47:52", "2023-10-12 07:20:02", 32*time.Minute + 10*time.Second, false},
+		{"2023-10-12 06:47:52", "", 0, true},
+		{"", "2023-10-12 07:20:02", 0, true},
+	}
+
+	for _, tc := range testCases {
+		operationResponse := &OperationResponse{StartTime: tc.startTime, EndTime: tc.endTime}
+		duration, err := GetOperationDuration(operationResponse)
+		if tc.expectError {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedDuration, duration)
+		}
+	}
+}
@@ -24,4 +24,10 @@ type OperationResponse struct {
 	Message            string `json:"message"`
 	StartTime          string `json:"startTime"`
 	EndTime            string `json:"endTime"`
+	EntityId           string `json:"entityId"`
+	EntityType         string `json:"entityType"`
+}
+
+type OperationListResponse struct {
+	Operations []OperationResponse `json:"operations"`
 }
@@ -18,7 +18,6 @@ package ndb_api
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
@@ -26,9 +25,18 @@ import (
 
 // Fetches and returns all the available profiles as a profile slice
 func GetAllProfiles(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface) (profiles []ProfileResponse, err error) {
+	return ListProfiles(ctx, ndbClient, ListOptions{})
+}
+
+// Fetches the profiles matching the list options as a profile slice.
+// NDB filters the profiles by the "engine", "type" and "topology" filters.
+func ListProfiles(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, opts ListOptions) (profiles []ProfileResponse, err error) {
 	log := ctrllog.FromContext(ctx)
-	if _, err = sendRequest(ctx, ndbClient, http.MethodGet, "profiles", nil, &profiles); err != nil {
-		log.Error(err, "Error in GetAllProfiles")
+	profiles, err = getCached(ndbClient, "profiles", opts.endpoint("profiles", 0), func() ([]ProfileResponse, error) {
+		return list(ctx, ndbClient, "profiles", opts, func(p ProfileResponse) string { return p.Id })
+	})
+	if err != nil {
+		log.Error(err, "Error in ListProfiles")
 		return
 	}
 	return
@@ -28,24 +28,33 @@ import (
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// Fetches all the profiles and returns a map of profiles
+// Fetches the compute profiles and the profiles of the database engine and returns a map of profiles
 // Returns an error if any profile is not found
 func ResolveProfiles(ctx context.Context, ndb_client ndb_client.NDBClientHTTPInterface, databaseType string, profileResolvers ProfileResolvers) (profilesMap map[string]ProfileResponse, err error) {
 	log := ctrllog.FromContext(ctx)
 
 	log.Info("Entered ndb_api.GetProfiles", "Input profiles", profileResolvers)
 
-	allProfiles, err := GetAllProfiles(ctx, ndb_client)
+	// Compute profiles are not engine specific, the other profiles are fetched for the engine only
+	computeProfiles, err := ListProfiles(ctx, ndb_client, ListOptions{Filters: map[string]string{"type": common.PROFILE_TYPE_COMPUTE}})
+	if err != nil {
+		log.Error(err, "Compute profiles could not be fetched")
+		return
+	}
+	engineProfiles, err := ListProfiles(ctx, ndb_client, ListOptions{Filters: map[string]string{"engine": GetDatabaseEngineName(databaseType)}})
 	if err != nil {
 		log.Error(err, "Profiles could not be fetched")
 		return
 	}
 
-	// profiles need to be in the ready state
-	activeProfiles := util.Filter(allProfiles, func(p ProfileResponse) bool { return p.Status == common.PROFILE_STATUS_READY })
+	// profiles need to be in the ready state, the filters are applied again
+	// in case the NDB version does not support filtering the profiles
+	activeComputeProfiles := util.Filter(computeProfiles, func(p ProfileResponse) bool {
+		return p.Status == common.PROFILE_STATUS_READY && p.Type == common.PROFILE_TYPE_COMPUTE
+	})
 
-	dbEngineSpecific := util.Filter(activeProfiles, func(p ProfileResponse) bool {
-		return p.EngineType == GetDatabaseEngineName(databaseType)
+	dbEngineSpecific := util.Filter(engineProfiles, func(p ProfileResponse) bool {
+		return p.Status == common.PROFILE_STATUS_READY && p.EngineType == GetDatabaseEngineName(databaseType)
 	})
 
 	computeProfileResolver := profileResolvers[common.PROFILE_TYPE_COMPUTE]
@@ -55,7 +64,7 @@ func ResolveProfiles(ctx context.Context, ndb_client ndb_client.NDBClientHTTPInt
 	dbParamInstanceProfileResolver := profileResolvers[common.PROFILE_TYPE_DATABASE_PARAMETER_INSTANCE]
 
 	// Compute Profile
-	compute, err := computeProfileResolver.Resolve(ctx, activeProfiles, ComputeOOBProfileResolver)
+	compute, err := computeProfileResolver.Resolve(ctx, activeComputeProfiles, ComputeOOBProfileResolver)
 	if err != nil {
 		log.Error(err, "Compute Profile could not be resolved", "Input Profile", computeProfileResolver)
 		return
@@ -17,12 +17,22 @@ limitations under the License.
 package ndb_api
 
 type ProfileResponse struct {
-	Id              string `json:"id"`
-	Name            string `json:"name"`
-	Type            string `json:"type"`
-	EngineType      string `json:"engineType"`
-	LatestVersionId string `json:"latestVersionId"`
-	Topology        string `json:"topology"`
-	SystemProfile   bool   `json:"systemProfile"`
-	Status          string `json:"status"`
+	Id              string                   `json:"id"`
+	Name            string                   `json:"name"`
+	Type            string                   `json:"type"`
+	EngineType      string                   `json:"engineType"`
+	LatestVersionId string                   `json:"latestVersionId"`
+	Topology        string                   `json:"topology"`
+	SystemProfile   bool                     `json:"systemProfile"`
+	Status          string                   `json:"status"`
+	Versions        []ProfileVersionResponse `json:"versions"`
+}
+
+type ProfileVersionResponse struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Status     string `json:"status"`
+	Published  bool   `json:"published"`
+	Deprecated bool   `json:"deprecated"`
 }
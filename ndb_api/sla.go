@@ -18,17 +18,24 @@ package ndb_api
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// Fetches and returns all the SLAs as a sla slice
+// Fetches and returns all the SLAs as a sla slice, served from the cache if enabled
 func GetAllSLAs(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface) (slas []SLAResponse, err error) {
+	return ListSLAs(ctx, ndbClient, ListOptions{})
+}
+
+// Fetches the SLAs matching the list options as a sla slice
+func ListSLAs(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, opts ListOptions) (slas []SLAResponse, err error) {
 	log := ctrllog.FromContext(ctx)
-	if _, err = sendRequest(ctx, ndbClient, http.MethodGet, "slas", nil, &slas); err != nil {
-		log.Error(err, "Error in GetAllSLAs")
+	slas, err = getCached(ndbClient, "slas", opts.endpoint("slas", 0), func() ([]SLAResponse, error) {
+		return list(ctx, ndbClient, "slas", opts, func(s SLAResponse) string { return s.Id })
+	})
+	if err != nil {
+		log.Error(err, "Error in ListSLAs")
 		return
 	}
 	return
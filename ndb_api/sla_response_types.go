@@ -17,13 +17,14 @@ limitations under the License.
 package ndb_api
 
 type SLAResponse struct {
-	Id                 string `json:"id"`
-	Name               string `json:"name"`
-	UniqueName         string `json:"uniqueName"`
-	Description        string `json:"description"`
-	DailyRetention     int    `json:"dailyRetention"`
-	WeeklyRetention    int    `json:"weeklyRetention"`
-	MonthlyRetention   int    `json:"monthlyRetention"`
-	QuarterlyRetention int    `json:"quarterlyRetention"`
-	YearlyRetention    int    `json:"yearlyRetention"`
+	Id                  string `json:"id"`
+	Name                string `json:"name"`
+	UniqueName          string `json:"uniqueName"`
+	Description         string `json:"description"`
+	ContinuousRetention int    `json:"continuousRetention"`
+	DailyRetention      int    `json:"dailyRetention"`
+	WeeklyRetention     int    `json:"weeklyRetention"`
+	MonthlyRetention    int    `json:"monthlyRetention"`
+	QuarterlyRetention  int    `json:"quarterlyRetention"`
+	YearlyRetention     int    `json:"yearlyRetention"`
 }
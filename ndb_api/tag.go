@@ -0,0 +1,43 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Fetches the tags defined on NDB for the entity type (e.g. DATABASE or CLONE)
+func GetTagsForEntityType(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, entityType string) (tags []TagResponse, err error) {
+	return ListTags(ctx, ndbClient, ListOptions{Filters: map[string]string{"entityType": entityType}})
+}
+
+// Fetches the tags defined on NDB matching the list options.
+// NDB filters the tags by the "entityType" filter.
+func ListTags(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, opts ListOptions) (tags []TagResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	tags, err = getCached(ndbClient, "tags", opts.endpoint("tags", 0), func() ([]TagResponse, error) {
+		return list(ctx, ndbClient, "tags", opts, func(t TagResponse) string { return t.Id })
+	})
+	if err != nil {
+		log.Error(err, "Error in ListTags")
+		return
+	}
+	return
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"sort"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/common/util"
+)
+
+// Returns the value of the tag with the name on the entity
+func GetTagValue(tags []Tag, name string) (value string, found bool) {
+	for _, tag := range tags {
+		if tag.TagName == name {
+			return tag.Value, true
+		}
+	}
+	return
+}
+
+// Resolves the ids of the tags (name: value) from the tags defined on NDB for an entity type.
+// Returns the tags sorted by name and the names of the tags that are not defined or not enabled on NDB.
+func ResolveTags(definedTags []TagResponse, values map[string]string) (tags []Tag, undefined []string) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tag, err := util.FindFirst(definedTags, func(t TagResponse) bool { return t.Name == name && t.Status == common.TAG_STATUS_ENABLED })
+		if err != nil {
+			undefined = append(undefined, name)
+			continue
+		}
+		tags = append(tags, Tag{TagId: tag.Id, TagName: tag.Name, Value: values[name]})
+	}
+	return
+}
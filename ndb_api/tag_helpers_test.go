@@ -0,0 +1,46 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+)
+
+// Tests the ResolveTags function, tests the following cases:
+// 1. The defined and enabled tags are resolved in order of name
+// 2. The undefined and disabled tags are returned by name
+func TestResolveTags(t *testing.T) {
+	definedTags := []TagResponse{
+		{Id: "1", Name: "team", Status: common.TAG_STATUS_ENABLED},
+		{Id: "2", Name: "cost-center", Status: common.TAG_STATUS_ENABLED},
+		{Id: "3", Name: "owner", Status: "DISABLED"},
+	}
+	values := map[string]string{"team": "dba", "cost-center": "42", "owner": "someone", "missing": "value"}
+
+	tags, undefined := ResolveTags(definedTags, values)
+
+	wantTags := []Tag{{TagId: "2", TagName: "cost-center", Value: "42"}, {TagId: "1", TagName: "team", Value: "dba"}}
+	if !reflect.DeepEqual(tags, wantTags) {
+		t.Errorf("ResolveTags() tags = %v, want %v", tags, wantTags)
+	}
+	if wantUndefined := []string{"missing", "owner"}; !reflect.DeepEqual(undefined, wantUndefined) {
+		t.Errorf("ResolveTags() undefined = %v, want %v", undefined, wantUndefined)
+	}
+}
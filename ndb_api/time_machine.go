@@ -81,3 +81,19 @@ func GetTimeMachineById(ctx context.Context, ndbClient ndb_client.NDBClientHTTPI
 	}
 	return
 }
+
+// Updates the TimeMachine with the given id as per the update request
+// Returns the updated TimeMachine
+func UpdateTimeMachine(ctx context.Context, ndbClient ndb_client.NDBClientHTTPInterface, tmId string, req *TimeMachineUpdateRequest) (timeMachine *TimeMachineResponse, err error) {
+	log := ctrllog.FromContext(ctx)
+	if tmId == "" {
+		err = fmt.Errorf("timemachine id is empty")
+		log.Error(err, "no timemachine id provided")
+		return
+	}
+	if _, err = sendRequest(ctx, ndbClient, http.MethodPatch, "tms/"+tmId, req, &timeMachine); err != nil {
+		log.Error(err, "Error in UpdateTimeMachine")
+		return
+	}
+	return
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+// Updates the name, description, SLA, schedule and tags of a time machine, the fields are only changed if their reset flag is set
+type TimeMachineUpdateRequest struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	SlaId            string   `json:"slaId"`
+	Schedule         Schedule `json:"schedule"`
+	Tags             []Tag    `json:"tags,omitempty"`
+	ResetName        bool     `json:"resetName"`
+	ResetDescription bool     `json:"resetDescription"`
+	ResetSlaId       bool     `json:"resetSlaId"`
+	ResetSchedule    bool     `json:"resetSchedule"`
+	ResetTags        bool     `json:"resetTags"`
+}
@@ -24,11 +24,18 @@ type TimeMachineResponse struct {
 	Status      string              `json:"Status"`
 	Sla         TimeMachineSLA      `json:"sla"`
 	Schedule    TimeMachineSchedule `json:"schedule"`
+	Tags        []Tag               `json:"tags"`
 }
 
 type TimeMachineSLA struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id                  string `json:"id"`
+	Name                string `json:"name"`
+	ContinuousRetention int    `json:"continuousRetention"`
+	DailyRetention      int    `json:"dailyRetention"`
+	WeeklyRetention     int    `json:"weeklyRetention"`
+	MonthlyRetention    int    `json:"monthlyRetention"`
+	QuarterlyRetention  int    `json:"quarterlyRetention"`
+	YearlyRetention     int    `json:"yearlyRetention"`
 }
 
 type TimeMachineSchedule struct {
@@ -39,6 +46,7 @@ type TimeMachineSchedule struct {
 	WeeklySchedule     TimeMachineWeeklySchedule     `json:"weeklySchedule"`
 	MonthlySchedule    TimeMachineMonthlySchedule    `json:"monthlySchedule"`
 	QuarterlySchedule  TimeMachineQuarterlySchedule  `json:"quarterlySchedule"`
+	YearlySchedule     TimeMachineYearlySchedule     `json:"yearlySchedule"`
 }
 
 type TimeMachineSnapshotTimeOfDay struct {
@@ -48,20 +56,31 @@ type TimeMachineSnapshotTimeOfDay struct {
 }
 
 type TimeMachineContinuousSchedule struct {
-	LogBackupInterval int `json:"logBackupInterval"`
-	SnapshotsPerDay   int `json:"snapshotsPerDay"`
+	Enabled           bool `json:"enabled"`
+	LogBackupInterval int  `json:"logBackupInterval"`
+	SnapshotsPerDay   int  `json:"snapshotsPerDay"`
 }
 
 type TimeMachineWeeklySchedule struct {
+	Enabled   bool   `json:"enabled"`
 	DayOfWeek string `json:"dayOfWeek"`
 }
 
 type TimeMachineMonthlySchedule struct {
-	DayOfMonth int `json:"dayOfMonth"`
+	Enabled    bool `json:"enabled"`
+	DayOfMonth int  `json:"dayOfMonth"`
 }
 
 type TimeMachineQuarterlySchedule struct {
+	Enabled    bool   `json:"enabled"`
 	StartMonth string `json:"startMonth"`
+	DayOfMonth int    `json:"dayOfMonth"`
+}
+
+type TimeMachineYearlySchedule struct {
+	Enabled    bool   `json:"enabled"`
+	DayOfMonth int    `json:"dayOfMonth"`
+	Month      string `json:"month"`
 }
 
 type TimeMachineGetSnapshotsResponse struct {
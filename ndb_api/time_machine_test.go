@@ -272,3 +272,84 @@ func TestGetTimeMachineById(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateTimeMachine(t *testing.T) {
+	type args struct {
+		ctx       context.Context
+		ndbClient ndb_client.NDBClientHTTPInterface
+		tmId      string
+		req       *TimeMachineUpdateRequest
+	}
+
+	tmId := "1"
+	updateReq := &TimeMachineUpdateRequest{SlaId: "sla-id", ResetSlaId: true}
+
+	// Mocks of the NDB Client interface
+	mockNDBClient := &MockNDBClientHTTPInterface{}
+
+	mockNDBClient.On("NewRequest", http.MethodPatch, "tms/"+tmId, updateReq).Once().Return(nil, errors.New("mock-error-new-request"))
+
+	req := &http.Request{}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"id":"test-id", "sla":{"id":"sla-id"}}`)),
+	}
+	mockNDBClient.On("NewRequest", http.MethodPatch, "tms/"+tmId, updateReq).Once().Return(req, nil)
+	mockNDBClient.On("Do", req).Once().Return(res, nil)
+
+	tests := []struct {
+		name           string
+		args           args
+		wantTMResponse *TimeMachineResponse
+		wantErr        bool
+	}{
+		{
+			name: "Test 1: UpdateTimeMachine returns an error when a request with empty id is passed to it",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				tmId:      "",
+				req:       updateReq,
+			},
+			wantTMResponse: nil,
+			wantErr:        true,
+		},
+		{
+			name: "Test 2: UpdateTimeMachine returns an error when sendRequest returns an error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				tmId:      tmId,
+				req:       updateReq,
+			},
+			wantTMResponse: nil,
+			wantErr:        true,
+		},
+		{
+			name: "Test 3: UpdateTimeMachine returns the updated TimeMachineResponse when sendRequest returns a response without error",
+			args: args{
+				ctx:       context.TODO(),
+				ndbClient: mockNDBClient,
+				tmId:      tmId,
+				req:       updateReq,
+			},
+			wantTMResponse: &TimeMachineResponse{
+				Id:  "test-id",
+				Sla: TimeMachineSLA{Id: "sla-id"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTMResponse, err := UpdateTimeMachine(tt.args.ctx, tt.args.ndbClient, tt.args.tmId, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateTimeMachine() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotTMResponse, tt.wantTMResponse) {
+				t.Errorf("UpdateTimeMachine() = %v, want %v", gotTMResponse, tt.wantTMResponse)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common/tracing"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Tests that sendRequest records a span with the NDB entity and operation ids and propagates the trace context to NDB
+func TestSendRequest_tracing(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"entityId": "entity-id", "operationId": "operation-id"}`))
+	}))
+	defer server.Close()
+	ndbClient := ndb_client.NewNDBClient("username", "password", server.URL, "", true)
+
+	ctx, parent := tracing.StartSpan(context.Background(), "parent")
+	_, err := ProvisionDatabase(ctx, ndbClient, &DatabaseProvisionRequest{})
+	parent.End()
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "NDB POST databases/provision", span.Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	assert.Contains(t, span.Attributes(), tracing.ATTRIBUTE_ENTITY_ID.String("entity-id"))
+	assert.Contains(t, span.Attributes(), tracing.ATTRIBUTE_OPERATION_ID.String("operation-id"))
+	assert.Contains(t, span.Attributes(), attribute.Int("http.status_code", http.StatusOK))
+	// The trace context sent to NDB is the one of the request span
+	assert.Contains(t, traceparent, span.SpanContext().SpanID().String())
+}
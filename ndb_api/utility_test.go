@@ -69,3 +69,10 @@ func GetServerTestHelperWithResponseMap(t *testing.T, responseMap map[string]int
 		}
 	}))
 }
+
+// Returns a server that responds to every request with the given (error) status code.
+func GetServerTestHelperWithStatus(t *testing.T, statusCode int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+}
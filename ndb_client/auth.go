@@ -0,0 +1,221 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Lifetime requested for the tokens fetched from NDB
+	DEFAULT_TOKEN_LIFETIME = 60 * time.Minute
+	// Tokens are refreshed when they expire within this duration
+	DEFAULT_TOKEN_REFRESH_BEFORE_EXPIRY = 5 * time.Minute
+	// The token endpoint is not called again within this duration after a failed token request,
+	// the requests sent in the meantime use basic auth
+	DEFAULT_TOKEN_RETRY_BACKOFF = 5 * time.Minute
+)
+
+// AuthStrategy authorizes the requests sent to NDB by an NDBClient.
+type AuthStrategy interface {
+	// Sets the authorization of a request sent by the client.
+	Authorize(ndbClient *NDBClient, req *http.Request) error
+	// Discards the cached authorization after NDB rejected a request with 401 Unauthorized.
+	// Returns true if the request should be retried with a new authorization.
+	Invalidate() bool
+}
+
+// BasicAuth sends the NDB username and password with every request.
+type BasicAuth struct {
+	username string
+	password string
+}
+
+func NewBasicAuth(username, password string) *BasicAuth {
+	return &BasicAuth{username: username, password: password}
+}
+
+func (a *BasicAuth) Authorize(ndbClient *NDBClient, req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+func (a *BasicAuth) Invalidate() bool {
+	// Retrying with the same credentials would fail again
+	return false
+}
+
+// TokenAuth fetches a token from NDB's token endpoint using the NDB username and password
+// and sends it as a bearer token with every request. The token is cached and refreshed
+// before it expires. Falls back to basic auth if the token cannot be fetched, the failure
+// is logged and the token endpoint is called again only after DEFAULT_TOKEN_RETRY_BACKOFF.
+// A single token request is sent at a time, the concurrent requests wait for its outcome.
+type TokenAuth struct {
+	basicAuth     *BasicAuth
+	lifetime      time.Duration
+	refreshBefore time.Duration
+	retryBackoff  time.Duration
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+	// Error of the last failed token request and the time before which it is not retried
+	tokenErr   error
+	retryAfter time.Time
+	// Closed once the token request in flight completes, nil if there is none
+	fetching chan struct{}
+	// Used to stub the current time in the tests
+	now func() time.Time
+}
+
+func NewTokenAuth(username, password string, lifetime time.Duration) *TokenAuth {
+	if lifetime <= 0 {
+		lifetime = DEFAULT_TOKEN_LIFETIME
+	}
+	refreshBefore := DEFAULT_TOKEN_REFRESH_BEFORE_EXPIRY
+	if refreshBefore >= lifetime {
+		refreshBefore = lifetime / 2
+	}
+	return &TokenAuth{
+		basicAuth:     NewBasicAuth(username, password),
+		lifetime:      lifetime,
+		refreshBefore: refreshBefore,
+		retryBackoff:  DEFAULT_TOKEN_RETRY_BACKOFF,
+		now:           time.Now,
+	}
+}
+
+type tokenResponse struct {
+	Token string `json:"token"`
+}
+
+func (a *TokenAuth) Authorize(ndbClient *NDBClient, req *http.Request) error {
+	token, err := a.getToken(ndbClient, req)
+	if err != nil {
+		// Fall back to basic auth until the token can be fetched again
+		return a.basicAuth.Authorize(ndbClient, req)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a *TokenAuth) Invalidate() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.token == "" {
+		// The request used basic auth, retrying would fail again
+		return false
+	}
+	a.token = ""
+	return true
+}
+
+// Returns the cached token, fetches a new one if there is no token or if it is about to expire.
+// A failed token request is not retried before the retry backoff has passed. The mutex is not held
+// while the token is fetched, the callers arriving meanwhile wait for the token request in flight.
+func (a *TokenAuth) getToken(ndbClient *NDBClient, req *http.Request) (string, error) {
+	a.mutex.Lock()
+	for {
+		if a.token != "" && a.now().Add(a.refreshBefore).Before(a.expiry) {
+			token := a.token
+			a.mutex.Unlock()
+			return token, nil
+		}
+		if a.tokenErr != nil && a.now().Before(a.retryAfter) {
+			tokenErr := a.tokenErr
+			a.mutex.Unlock()
+			return "", tokenErr
+		}
+		if a.fetching == nil {
+			break
+		}
+		fetching := a.fetching
+		a.mutex.Unlock()
+		select {
+		case <-fetching:
+		case <-req.Context().Done():
+			return "", req.Context().Err()
+		}
+		a.mutex.Lock()
+	}
+	fetching := make(chan struct{})
+	a.fetching = fetching
+	a.mutex.Unlock()
+
+	token, expiry, err := a.fetchToken(ndbClient, req)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.fetching = nil
+	close(fetching)
+	if err != nil {
+		ctrllog.FromContext(req.Context()).Error(err, "Failed to fetch an NDB token, falling back to basic auth", "retryAfter", a.retryBackoff.String())
+		a.token = ""
+		a.tokenErr = err
+		a.retryAfter = a.now().Add(a.retryBackoff)
+		return "", err
+	}
+	if a.tokenErr != nil {
+		ctrllog.FromContext(req.Context()).Info("Fetched an NDB token, no longer falling back to basic auth")
+	}
+	a.token = token
+	a.expiry = expiry
+	a.tokenErr = nil
+	return a.token, nil
+}
+
+// Fetches a new token from the token endpoint of NDB, returns the token and its expiry
+func (a *TokenAuth) fetchToken(ndbClient *NDBClient, req *http.Request) (token string, expiry time.Time, err error) {
+	url := fmt.Sprintf("%s/auth/token?expire=%d", ndbClient.url, int(a.lifetime.Minutes()))
+	tokenReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	a.basicAuth.Authorize(ndbClient, tokenReq)
+	expiry = a.now().Add(a.lifetime)
+	// Token requests count against the rate and in-flight limits like any other request
+	res, err := ndbClient.limiter.do(ndbClient.client, tokenReq)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+	if res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("token request failed with status %d", res.StatusCode)
+		return
+	}
+	var response tokenResponse
+	if err = json.Unmarshal(body, &response); err != nil {
+		return
+	}
+	if response.Token == "" {
+		err = fmt.Errorf("token response does not contain a token")
+		return
+	}
+	token = response.Token
+	return
+}
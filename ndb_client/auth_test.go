@@ -0,0 +1,236 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a mock NDB server issuing tokens "token-1", "token-2", ... and accepting only the latest token
+// (or basic auth if tokens are disabled). The number of (successful or failed) token requests is tracked in tokenRequests.
+func getTokenAuthTestServer(tokensEnabled bool, tokenRequests *int32) *httptest.Server {
+	var latestToken atomic.Value
+	latestToken.Store("")
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/token" {
+			tokenRequest := atomic.AddInt32(tokenRequests, 1)
+			username, password, ok := r.BasicAuth()
+			if !tokensEnabled || !ok || username != "username" || password != "password" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			token := "token-" + strconv.Itoa(int(tokenRequest))
+			latestToken.Store(token)
+			w.Write([]byte(`{"token": "` + token + `"}`))
+			return
+		}
+		if tokensEnabled && r.Header.Get("Authorization") != "Bearer "+latestToken.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if username, _, ok := r.BasicAuth(); !tokensEnabled && (!ok || username != "username") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+}
+
+func sendTestRequest(t *testing.T, ndbClient *NDBClient) int {
+	req, err := ndbClient.NewRequest(context.Background(), http.MethodGet, "databases", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	res, err := ndbClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	res.Body.Close()
+	return res.StatusCode
+}
+
+func TestTokenAuth_cachesAndRefreshesToken(t *testing.T) {
+	var tokenRequests int32
+	server := getTokenAuthTestServer(true, &tokenRequests)
+	defer server.Close()
+	auth := NewTokenAuth("username", "password", time.Hour)
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+	ndbClient := NewNDBClientWithAuth(auth, server.URL, "", true, DefaultClientOptions())
+
+	// The token is fetched once and reused
+	for i := 0; i < 3; i++ {
+		if status := sendTestRequest(t, ndbClient); status != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected 1 token request, got %d", tokenRequests)
+	}
+
+	// The token is refreshed before it expires
+	now = now.Add(time.Hour - DEFAULT_TOKEN_REFRESH_BEFORE_EXPIRY + time.Second)
+	if status := sendTestRequest(t, ndbClient); status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("expected 2 token requests, got %d", tokenRequests)
+	}
+}
+
+func TestTokenAuth_retriesOnUnauthorized(t *testing.T) {
+	var tokenRequests int32
+	server := getTokenAuthTestServer(true, &tokenRequests)
+	defer server.Close()
+	auth := NewTokenAuth("username", "password", time.Hour)
+	ndbClient := NewNDBClientWithAuth(auth, server.URL, "", true, DefaultClientOptions())
+
+	if status := sendTestRequest(t, ndbClient); status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+	// The cached token is revoked on NDB
+	auth.token = "revoked"
+	if status := sendTestRequest(t, ndbClient); status != http.StatusOK {
+		t.Fatalf("expected the request to be retried with a new token, got status %d", status)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("expected 2 token requests, got %d", tokenRequests)
+	}
+}
+
+func TestTokenAuth_fallsBackToBasicAuth(t *testing.T) {
+	var tokenRequests int32
+	server := getTokenAuthTestServer(false, &tokenRequests)
+	defer server.Close()
+	auth := NewTokenAuth("username", "password", time.Hour)
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+	ndbClient := NewNDBClientWithAuth(auth, server.URL, "", true, DefaultClientOptions())
+
+	// The failed token request is not retried within the backoff
+	for i := 0; i < 3; i++ {
+		if status := sendTestRequest(t, ndbClient); status != http.StatusOK {
+			t.Fatalf("expected the request to fall back to basic auth, got status %d", status)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected 1 token request, got %d", tokenRequests)
+	}
+
+	// The token is requested again after the backoff
+	now = now.Add(DEFAULT_TOKEN_RETRY_BACKOFF)
+	if status := sendTestRequest(t, ndbClient); status != http.StatusOK {
+		t.Fatalf("expected the request to fall back to basic auth, got status %d", status)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("expected 2 token requests, got %d", tokenRequests)
+	}
+}
+
+func TestTokenAuth_sharesTokenRequestInFlight(t *testing.T) {
+	var tokenRequests int32
+	released := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/token" {
+			atomic.AddInt32(&tokenRequests, 1)
+			// The token endpoint hangs until released
+			<-released
+			w.Write([]byte(`{"token": "token-1"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	auth := NewTokenAuth("username", "password", time.Hour)
+	ndbClient := NewNDBClientWithAuth(auth, server.URL, "", true, DefaultClientOptions())
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 5)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, err := ndbClient.NewRequest(context.Background(), http.MethodGet, "databases", nil)
+			if err != nil {
+				t.Errorf("NewRequest() error = %v", err)
+				return
+			}
+			res, err := ndbClient.Do(req)
+			if err != nil {
+				t.Errorf("Do() error = %v", err)
+				return
+			}
+			res.Body.Close()
+			statuses[i] = res.StatusCode
+		}(i)
+	}
+	for atomic.LoadInt32(&tokenRequests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// The mutex is not held while the token request is in flight
+	invalidated := make(chan bool)
+	go func() { invalidated <- auth.Invalidate() }()
+	select {
+	case <-invalidated:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected Invalidate() not to wait for the token request in flight")
+	}
+	close(released)
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("request %d: expected status %d, got %d", i, http.StatusOK, status)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected 1 token request, got %d", tokenRequests)
+	}
+}
+
+func TestTokenAuth_tokenRequestsAreRateLimited(t *testing.T) {
+	var tokenRequests int32
+	server := getTokenAuthTestServer(true, &tokenRequests)
+	defer server.Close()
+	options := DefaultClientOptions()
+	options.RequestsPerSecond = 20
+	options.Burst = 1
+	ndbClient := NewNDBClientWithAuth(NewTokenAuth("username", "password", time.Hour), server.URL, "", true, options)
+
+	// The token request and 3 requests, 1 is sent immediately (burst), the remaining 3 at 20 per second
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		sendTestRequest(t, ndbClient)
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("expected the token request to be rate limited, 3 requests and the token request took %v", elapsed)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected 1 token request, got %d", tokenRequests)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"sync"
+)
+
+// ClientPool caches NDBClients so that their connections (and tokens) are reused across requests.
+// Each client is stored with a fingerprint of the configuration it was created with,
+// the client is replaced when the fingerprint changes.
+type ClientPool struct {
+	mutex   sync.Mutex
+	clients map[string]pooledClient
+}
+
+type pooledClient struct {
+	fingerprint string
+	client      *NDBClient
+}
+
+func NewClientPool() *ClientPool {
+	return &ClientPool{clients: make(map[string]pooledClient)}
+}
+
+// Returns the client cached for the key if it was created with the same fingerprint,
+// otherwise creates a new client using create and caches it.
+func (p *ClientPool) Get(key, fingerprint string, create func() (*NDBClient, error)) (*NDBClient, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	cached, ok := p.clients[key]
+	if ok && cached.fingerprint == fingerprint {
+		return cached.client, nil
+	}
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cached.client.close()
+	}
+	p.clients[key] = pooledClient{fingerprint: fingerprint, client: client}
+	return client, nil
+}
+
+// Returns the client cached for the key, if any
+func (p *ClientPool) Lookup(key string) (*NDBClient, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	cached, ok := p.clients[key]
+	return cached.client, ok
+}
+
+// Removes the client cached for the key
+func (p *ClientPool) Remove(key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if cached, ok := p.clients[key]; ok {
+		cached.client.close()
+		delete(p.clients, key)
+	}
+}
+
+// Returns the number of cached clients
+func (p *ClientPool) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return len(p.clients)
+}
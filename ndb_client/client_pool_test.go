@@ -0,0 +1,57 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClientPool_Get(t *testing.T) {
+	pool := NewClientPool()
+	creations := 0
+	create := func() (*NDBClient, error) {
+		creations++
+		return NewNDBClient("username", "password", "https://ndb/era/v0.9", "", true), nil
+	}
+
+	first, _ := pool.Get("ns/ndb", "v1", create)
+	second, _ := pool.Get("ns/ndb", "v1", create)
+	if first != second || creations != 1 {
+		t.Errorf("expected the client to be reused for the same fingerprint, got %d creations", creations)
+	}
+
+	third, _ := pool.Get("ns/ndb", "v2", create)
+	if third == first || creations != 2 {
+		t.Errorf("expected a new client for a new fingerprint, got %d creations", creations)
+	}
+
+	// A failed creation keeps the cached client
+	_, err := pool.Get("ns/ndb", "v3", func() (*NDBClient, error) { return nil, errors.New("invalid credentials") })
+	if err == nil {
+		t.Errorf("expected the creation error to be returned")
+	}
+	fourth, _ := pool.Get("ns/ndb", "v2", create)
+	if fourth != third {
+		t.Errorf("expected the cached client to be kept after a failed creation")
+	}
+
+	pool.Remove("ns/ndb")
+	if pool.Len() != 0 {
+		t.Errorf("expected no cached clients after Remove, got %d", pool.Len())
+	}
+}
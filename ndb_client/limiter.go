@@ -0,0 +1,112 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Bounds the load an NDBClient puts on NDB: requests are admitted at a sustained rate
+// (token bucket) and at most maxInFlight requests are outstanding at once.
+type requestLimiter struct {
+	server   string
+	rate     *rate.Limiter
+	inFlight chan struct{}
+}
+
+// Returns a requestLimiter for the options, a zero RequestsPerSecond or MaxInFlight disables the respective limit
+func newRequestLimiter(server string, options ClientOptions) *requestLimiter {
+	limiter := &requestLimiter{server: server}
+	if options.RequestsPerSecond > 0 {
+		burst := options.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		limiter.rate = rate.NewLimiter(rate.Limit(options.RequestsPerSecond), burst)
+	}
+	if options.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, options.MaxInFlight)
+	}
+	return limiter
+}
+
+// Waits until the request may be sent, returns a function releasing the in-flight slot.
+// The time spent waiting is recorded in the queue wait metric.
+func (l *requestLimiter) acquire(ctx context.Context) (release func(), err error) {
+	start := time.Now()
+	queueLength.WithLabelValues(l.server).Inc()
+	defer func() {
+		queueLength.WithLabelValues(l.server).Dec()
+		if err == nil {
+			queueWaitSeconds.WithLabelValues(l.server).Observe(time.Since(start).Seconds())
+		}
+	}()
+
+	if l.rate != nil {
+		if err = l.rate.Wait(ctx); err != nil {
+			return
+		}
+	}
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	requestsInFlight.WithLabelValues(l.server).Inc()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-l.inFlight
+			requestsInFlight.WithLabelValues(l.server).Dec()
+		})
+	}, nil
+}
+
+// Sends the request once admitted by the limiter, the in-flight slot is held until the response body is closed
+func (l *requestLimiter) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	release, err := l.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	res.Body = &releasingBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+// Response body releasing the in-flight slot of its request when closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
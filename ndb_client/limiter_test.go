@@ -0,0 +1,97 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNDBClient_limitsRequestsInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	ndbClient := NewNDBClientWithAuth(NewBasicAuth("username", "password"), server.URL, "", true, ClientOptions{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status := sendTestRequest(t, ndbClient); status != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, status)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+	if count := testutil.CollectAndCount(queueWaitSeconds); count == 0 {
+		t.Errorf("expected the queue wait to be recorded")
+	}
+}
+
+func TestNDBClient_limitsRequestRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	ndbClient := NewNDBClientWithAuth(NewBasicAuth("username", "password"), server.URL, "", true, ClientOptions{RequestsPerSecond: 20, Burst: 1})
+
+	// 1 request is sent immediately (burst), the remaining 4 at 20 per second
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		sendTestRequest(t, ndbClient)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected the requests to be rate limited, 5 requests took %v", elapsed)
+	}
+}
+
+func TestRequestLimiter_acquire_stopsWhenContextIsDone(t *testing.T) {
+	limiter := newRequestLimiter("test", ClientOptions{MaxInFlight: 1})
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx); err == nil {
+		t.Errorf("acquire() expected an error while all in-flight slots are taken")
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	queueWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ndb_client_queue_wait_seconds",
+		Help:    "Time requests to NDB waited for the rate limiter and a free in-flight slot",
+		Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"server"})
+	queueLength = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ndb_client_queued_requests",
+		Help: "Number of requests to NDB waiting for the rate limiter or a free in-flight slot",
+	}, []string{"server"})
+	requestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ndb_client_requests_in_flight",
+		Help: "Number of requests to NDB in flight",
+	}, []string{"server"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(queueWaitSeconds, queueLength, requestsInFlight)
+}
@@ -18,40 +18,131 @@ package ndb_client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // NDBClientInterface defines the methods for an NDB client.
 type NDBClientHTTPInterface interface {
-	NewRequest(method, endpoint string, requestBody interface{}) (*http.Request, error)
+	NewRequest(ctx context.Context, method, endpoint string, requestBody interface{}) (*http.Request, error)
 	Do(req *http.Request) (*http.Response, error)
 }
 
 type NDBClient struct {
-	username string
-	password string
-	url      string
-	client   *http.Client
+	url     string
+	client  *http.Client
+	auth    AuthStrategy
+	options ClientOptions
+	limiter *requestLimiter
+	// Waits between the retries, stubbed in the tests
+	wait func(ctx context.Context, d time.Duration) error
+	// Outcome of the last request, nil until the first request completes
+	lastRequestSucceeded atomic.Pointer[bool]
+}
+
+// Options for the requests sent by an NDBClient
+type ClientOptions struct {
+	// Timeout of a single attempt of a request
+	RequestTimeout time.Duration
+	// Maximum number of retries of the idempotent (GET) requests
+	// failing with a connection error or a 5xx/429 response
+	MaxRetries int
+	// Backoff before the first retry, doubled (with jitter) for every subsequent retry
+	RetryBackoff time.Duration
+	// Upper bound for the backoff, also caps the wait requested by the Retry-After header
+	MaxRetryBackoff time.Duration
+	// Sustained number of requests per second sent to NDB, 0 disables the rate limit
+	RequestsPerSecond float64
+	// Number of requests that may be sent at once above the sustained rate
+	Burst int
+	// Maximum number of requests outstanding at once, 0 disables the limit
+	MaxInFlight int
 }
 
+const (
+	DEFAULT_REQUEST_TIMEOUT   = 30 * time.Second
+	DEFAULT_MAX_RETRIES       = 3
+	DEFAULT_RETRY_BACKOFF     = 500 * time.Millisecond
+	DEFAULT_MAX_RETRY_BACKOFF = 30 * time.Second
+
+	DEFAULT_REQUESTS_PER_SECOND = 10
+	DEFAULT_BURST               = 20
+	DEFAULT_MAX_IN_FLIGHT       = 10
+)
+
+// Returns the default ClientOptions
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		RequestTimeout:    DEFAULT_REQUEST_TIMEOUT,
+		MaxRetries:        DEFAULT_MAX_RETRIES,
+		RetryBackoff:      DEFAULT_RETRY_BACKOFF,
+		MaxRetryBackoff:   DEFAULT_MAX_RETRY_BACKOFF,
+		RequestsPerSecond: DEFAULT_REQUESTS_PER_SECOND,
+		Burst:             DEFAULT_BURST,
+		MaxInFlight:       DEFAULT_MAX_IN_FLIGHT,
+	}
+}
+
+// Returns an NDBClient sending the NDB username and password with every request (basic auth)
 func NewNDBClient(username, password, url, caCert string, skipVerify bool) *NDBClient {
+	return NewNDBClientWithAuth(NewBasicAuth(username, password), url, caCert, skipVerify, DefaultClientOptions())
+}
+
+// Returns an NDBClient using the auth method (token or basic, defaults to token) with the NDB username and password
+func NewNDBClientWithAuthMethod(authMethod, username, password, url, caCert string, skipVerify bool, options ClientOptions) *NDBClient {
+	if authMethod == common.AUTH_METHOD_BASIC {
+		return NewNDBClientWithAuth(NewBasicAuth(username, password), url, caCert, skipVerify, options)
+	}
+	return NewNDBClientWithAuth(NewTokenAuth(username, password, DEFAULT_TOKEN_LIFETIME), url, caCert, skipVerify, options)
+}
+
+// Returns an NDBClient authorizing the requests using the given AuthStrategy
+func NewNDBClientWithAuth(auth AuthStrategy, url, caCert string, skipVerify bool, options ClientOptions) *NDBClient {
 	TLSClientConfig := &tls.Config{InsecureSkipVerify: skipVerify}
 	if caCert != "" {
 		caCertPool := x509.NewCertPool()
 		caCertPool.AppendCertsFromPEM([]byte(caCert))
 		TLSClientConfig.RootCAs = caCertPool
 	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = DEFAULT_REQUEST_TIMEOUT
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DEFAULT_RETRY_BACKOFF
+	}
+	if options.MaxRetryBackoff < options.RetryBackoff {
+		options.MaxRetryBackoff = DEFAULT_MAX_RETRY_BACKOFF
+	}
 	client := &http.Client{
 		Transport: &http.Transport{TLSClientConfig: TLSClientConfig},
+		Timeout:   options.RequestTimeout,
+	}
+	return &NDBClient{
+		url:     url,
+		client:  client,
+		auth:    auth,
+		options: options,
+		limiter: newRequestLimiter(getServerLabel(url), options),
+		wait:    waitWithContext,
 	}
-	return &NDBClient{username, password, url, client}
 }
 
-func (ndbClient *NDBClient) NewRequest(method, endpoint string, requestBody interface{}) (*http.Request, error) {
+// Creates a request bound to the context, the request is cancelled when the context is done.
+func (ndbClient *NDBClient) NewRequest(ctx context.Context, method, endpoint string, requestBody interface{}) (*http.Request, error) {
 
 	url := ndbClient.url + "/" + endpoint
 
@@ -68,19 +159,99 @@ func (ndbClient *NDBClient) NewRequest(method, endpoint string, requestBody inte
 	}
 
 	// Create a new HTTP request with the specified method and URL.
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
 	// Set headers
-	req.SetBasicAuth(ndbClient.username, ndbClient.password)
+	if err = ndbClient.auth.Authorize(ndbClient, req); err != nil {
+		return nil, err
+	}
 	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+	// Propagate the trace context (if tracing is enabled) to correlate the requests with NDB's logs
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	return req, nil
 }
 
+// Sends the request, idempotent requests are retried on connection errors and 5xx/429 responses.
 func (ndbClient *NDBClient) Do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := ndbClient.doAuthorized(req)
+		if attempt >= ndbClient.options.MaxRetries || !isRetryable(req, res, err) {
+			ndbClient.recordOutcome(res, err)
+			return res, err
+		}
+		delay := ndbClient.getRetryDelay(res, attempt)
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if err = ndbClient.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		if req, err = cloneRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Sends the request, retries once with a new authorization if it is rejected with 401 Unauthorized
+func (ndbClient *NDBClient) doAuthorized(req *http.Request) (*http.Response, error) {
 	// Use the HTTP client to send the provided request.
-	return ndbClient.client.Do(req)
+	res, err := ndbClient.limiter.do(ndbClient.client, req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !ndbClient.auth.Invalidate() {
+		return res, err
+	}
+
+	// The authorization was rejected, retry once with a new authorization
+	res.Body.Close()
+	retryReq, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	retryReq.Header.Del("Authorization")
+	if err = ndbClient.auth.Authorize(ndbClient, retryReq); err != nil {
+		return nil, err
+	}
+	return ndbClient.limiter.do(ndbClient.client, retryReq)
+}
+
+// Records whether the request reached NDB and was authenticated,
+// i.e. NDB responded with a status other than 401, 403 or 5xx
+func (ndbClient *NDBClient) recordOutcome(res *http.Response, err error) {
+	succeeded := err == nil && res.StatusCode != http.StatusUnauthorized &&
+		res.StatusCode != http.StatusForbidden && res.StatusCode < http.StatusInternalServerError
+	ndbClient.lastRequestSucceeded.Store(&succeeded)
+}
+
+// Returns true if the last request sent by the client reached NDB and was authenticated.
+// ok is false if no request has completed yet.
+func (ndbClient *NDBClient) LastRequestSucceeded() (succeeded, ok bool) {
+	if last := ndbClient.lastRequestSucceeded.Load(); last != nil {
+		return *last, true
+	}
+	return false, false
+}
+
+// Returns the url of the NDB server the client sends its requests to
+func (ndbClient *NDBClient) GetServerURL() string {
+	if ndbClient == nil {
+		return ""
+	}
+	return ndbClient.url
+}
+
+// Returns the host of the NDB server url used to label the metrics of its client
+func getServerLabel(serverURL string) string {
+	if parsed, err := url.Parse(serverURL); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return serverURL
+}
+
+// Closes the idle connections of a client that is not used anymore
+func (ndbClient *NDBClient) close() {
+	ndbClient.client.CloseIdleConnections()
 }
@@ -0,0 +1,99 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Returns true if the request is idempotent and failed with a connection error or a 5xx/429 response.
+// Requests whose context is done are not retried.
+func isRetryable(req *http.Request, res *http.Response, err error) bool {
+	if req.Method != http.MethodGet || req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests
+}
+
+// Returns the delay before the next retry: the exponential backoff with jitter for the attempt,
+// or the delay requested by the Retry-After header of the response if it is longer.
+// The delay is capped at MaxRetryBackoff.
+func (ndbClient *NDBClient) getRetryDelay(res *http.Response, attempt int) time.Duration {
+	backoff := ndbClient.options.RetryBackoff << attempt
+	if backoff <= 0 || backoff > ndbClient.options.MaxRetryBackoff {
+		backoff = ndbClient.options.MaxRetryBackoff
+	}
+	// Jitter between half and the full backoff
+	delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	if retryAfter := getRetryAfter(res); retryAfter > delay {
+		delay = retryAfter
+	}
+	if delay > ndbClient.options.MaxRetryBackoff {
+		delay = ndbClient.options.MaxRetryBackoff
+	}
+	return delay
+}
+
+// Returns the delay requested by the Retry-After header (in seconds or as an HTTP date), 0 if absent or invalid.
+func getRetryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// Returns a copy of the request with a fresh body so that it can be sent again
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// Waits for the duration, returns the error of the context if it is done before
+func waitWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a basic auth NDBClient for the url recording the waits between the retries instead of sleeping
+func getRetryTestClient(url string, options ClientOptions, waits *[]time.Duration) *NDBClient {
+	ndbClient := NewNDBClientWithAuth(NewBasicAuth("username", "password"), url, "", true, options)
+	ndbClient.wait = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	return ndbClient
+}
+
+// Returns a mock NDB server responding with the given status codes in order, 200 once they are exhausted.
+// The number of requests received is tracked in requests.
+func getRetryTestServer(statusCodes []int, headers map[string]string, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(requests, 1)) - 1
+		if i < len(statusCodes) {
+			for key, value := range headers {
+				w.Header().Set(key, value)
+			}
+			w.WriteHeader(statusCodes[i])
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+}
+
+func sendRetryTestRequest(ctx context.Context, ndbClient *NDBClient, method string) (int, error) {
+	req, err := ndbClient.NewRequest(ctx, method, "databases", map[string]string{"key": "value"})
+	if err != nil {
+		return 0, err
+	}
+	res, err := ndbClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	return res.StatusCode, nil
+}
+
+// Tests the retries of NDBClient.Do, tests the following cases:
+//  1. GET is retried on 5xx/429 responses until it succeeds
+//  2. GET is retried at most MaxRetries times
+//  3. POST is not retried
+//  4. The Retry-After header is honoured and capped at MaxRetryBackoff
+func TestNDBClient_Do_retries(t *testing.T) {
+	options := ClientOptions{RequestTimeout: time.Second, MaxRetries: 3, RetryBackoff: 100 * time.Millisecond, MaxRetryBackoff: 10 * time.Second}
+	tests := []struct {
+		name         string
+		method       string
+		statusCodes  []int
+		headers      map[string]string
+		wantStatus   int
+		wantRequests int32
+		checkWaits   func(t *testing.T, waits []time.Duration)
+	}{
+		{
+			name:         "Test 1: GET is retried on 5xx/429 responses until it succeeds",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			wantStatus:   http.StatusOK,
+			wantRequests: 4,
+			checkWaits: func(t *testing.T, waits []time.Duration) {
+				// Exponential backoff with jitter between half and the full backoff
+				for i, wait := range waits {
+					backoff := options.RetryBackoff << i
+					if wait < backoff/2 || wait > backoff {
+						t.Errorf("wait %d = %v, want between %v and %v", i, wait, backoff/2, backoff)
+					}
+				}
+			},
+		},
+		{
+			name:         "Test 2: GET is retried at most MaxRetries times",
+			method:       http.MethodGet,
+			statusCodes:  []int{500, 500, 500, 500, 500},
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 4,
+		},
+		{
+			name:         "Test 3: POST is not retried",
+			method:       http.MethodPost,
+			statusCodes:  []int{http.StatusInternalServerError},
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 1,
+		},
+		{
+			name:         "Test 4: Retry-After is honoured and capped at MaxRetryBackoff",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			headers:      map[string]string{"Retry-After": "5"},
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+			checkWaits: func(t *testing.T, waits []time.Duration) {
+				for i, wait := range waits {
+					if wait != 5*time.Second {
+						t.Errorf("wait %d = %v, want %v", i, wait, 5*time.Second)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := getRetryTestServer(tt.statusCodes, tt.headers, &requests)
+			defer server.Close()
+			var waits []time.Duration
+			ndbClient := getRetryTestClient(server.URL, options, &waits)
+
+			status, err := sendRetryTestRequest(context.Background(), ndbClient, tt.method)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("Do() status = %d, want %d", status, tt.wantStatus)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Do() sent %d requests, want %d", requests, tt.wantRequests)
+			}
+			if len(waits) != int(tt.wantRequests)-1 {
+				t.Errorf("Do() waited %d times, want %d", len(waits), tt.wantRequests-1)
+			}
+			if tt.checkWaits != nil {
+				tt.checkWaits(t, waits)
+			}
+		})
+	}
+}
+
+func TestNDBClient_Do_retriesConnectionErrors(t *testing.T) {
+	// Reserve a port with nothing listening on it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + listener.Addr().String()
+	listener.Close()
+
+	var waits []time.Duration
+	ndbClient := getRetryTestClient(url, ClientOptions{MaxRetries: 2}, &waits)
+	if _, err := sendRetryTestRequest(context.Background(), ndbClient, http.MethodGet); err == nil {
+		t.Errorf("Do() expected a connection error")
+	}
+	if len(waits) != 2 {
+		t.Errorf("Do() waited %d times, want 2", len(waits))
+	}
+}
+
+func TestNDBClient_Do_stopsRetryingWhenContextIsDone(t *testing.T) {
+	var requests int32
+	server := getRetryTestServer([]int{500, 500, 500}, nil, &requests)
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	ndbClient := NewNDBClientWithAuth(NewBasicAuth("username", "password"), server.URL, "", true, ClientOptions{MaxRetries: 3})
+	ndbClient.wait = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return waitWithContext(ctx, d)
+	}
+
+	_, err := sendRetryTestRequest(ctx, ndbClient, http.MethodGet)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+	}
+	if requests != 1 {
+		t.Errorf("Do() sent %d requests, want 1", requests)
+	}
+}
+
+func TestNDBClient_Do_enforcesRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var waits []time.Duration
+	ndbClient := getRetryTestClient(server.URL, ClientOptions{RequestTimeout: 50 * time.Millisecond, MaxRetries: 1}, &waits)
+	start := time.Now()
+	if _, err := sendRetryTestRequest(context.Background(), ndbClient, http.MethodGet); err == nil {
+		t.Errorf("Do() expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Do() took %v, the request timeout was not enforced", elapsed)
+	}
+	if len(waits) != 1 {
+		t.Errorf("Do() waited %d times, want 1 (timed out GETs are retried)", len(waits))
+	}
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "Test 1: seconds", value: "3", want: 3 * time.Second},
+		{name: "Test 2: absent", value: "", want: 0},
+		{name: "Test 3: invalid", value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if tt.value != "" {
+				res.Header.Set("Retry-After", tt.value)
+			}
+			if got := getRetryAfter(res); got != tt.want {
+				t.Errorf("getRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// HTTP date
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if got := getRetryAfter(res); got < 58*time.Second || got > time.Minute {
+		t.Errorf("getRetryAfter() = %v, want about 1m", got)
+	}
+}
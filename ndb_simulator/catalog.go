@@ -0,0 +1,126 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_simulator
+
+import (
+	"github.com/google/uuid"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+// Engines for which the simulator has out of box profiles
+var simulatedEngines = []string{
+	common.DATABASE_ENGINE_TYPE_MONGODB,
+	common.DATABASE_ENGINE_TYPE_MSSQL,
+	common.DATABASE_ENGINE_TYPE_MYSQL,
+	common.DATABASE_ENGINE_TYPE_POSTGRES,
+}
+
+// Names of the SLAs of the simulator
+var simulatedSLAs = []string{
+	"NONE",
+	"DEFAULT_OOB_BRASS_SLA",
+	"DEFAULT_OOB_BRONZE_SLA",
+	"DEFAULT_OOB_GOLD_SLA",
+	"DEFAULT_OOB_SILVER_SLA",
+}
+
+// Returns the cluster of the simulator
+func newCluster(id, name string) ndb_api.ClusterResponse {
+	return ndb_api.ClusterResponse{
+		Id:             id,
+		Name:           name,
+		UniqueName:     name,
+		Description:    "Simulated Nutanix cluster",
+		Status:         "UP",
+		Version:        "6.5",
+		HypervisorType: "AHV",
+	}
+}
+
+// Returns the out of box profiles: a compute profile and the software, network and
+// database parameter profiles of every simulated engine
+func newProfiles() (profiles []ndb_api.ProfileResponse) {
+	newProfile := func(name, profileType, engine, topology string) ndb_api.ProfileResponse {
+		versionId := uuid.NewString()
+		return ndb_api.ProfileResponse{
+			Id:              uuid.NewString(),
+			Name:            name,
+			Type:            profileType,
+			EngineType:      engine,
+			LatestVersionId: versionId,
+			Topology:        topology,
+			SystemProfile:   true,
+			Status:          common.PROFILE_STATUS_READY,
+			Versions: []ndb_api.ProfileVersionResponse{
+				{Id: versionId, Name: name, Version: "1.0", Status: common.PROFILE_STATUS_READY, Published: true},
+			},
+		}
+	}
+	profiles = append(profiles, newProfile(common.PROFILE_DEFAULT_OOB_SMALL_COMPUTE, common.PROFILE_TYPE_COMPUTE, common.DATABASE_ENGINE_TYPE_GENERIC, common.TOPOLOGY_ALL))
+	for _, engine := range simulatedEngines {
+		profiles = append(profiles,
+			newProfile(engine+"_SOFTWARE", common.PROFILE_TYPE_SOFTWARE, engine, common.TOPOLOGY_SINGLE),
+			newProfile(engine+"_NETWORK", common.PROFILE_TYPE_NETWORK, engine, common.TOPOLOGY_ALL),
+			newProfile(engine+"_PARAMETERS", common.PROFILE_TYPE_DATABASE_PARAMETER, engine, common.TOPOLOGY_DATABASE),
+		)
+		if engine == common.DATABASE_ENGINE_TYPE_MSSQL {
+			profiles = append(profiles, newProfile(engine+"_INSTANCE_PARAMETERS", common.PROFILE_TYPE_DATABASE_PARAMETER, engine, common.TOPOLOGY_INSTANCE))
+		}
+	}
+	return
+}
+
+// Returns the tags defined on the simulator: the tags of the operator for all the entity types
+func newTags() (tags []ndb_api.TagResponse) {
+	descriptions := map[string]string{
+		common.NDB_TAG_NAME_CLUSTER_ID:   "Id of the Kubernetes cluster of the operator",
+		common.NDB_TAG_NAME_CR_NAME:      "Name of the Database custom resource",
+		common.NDB_TAG_NAME_CR_NAMESPACE: "Namespace of the Database custom resource",
+		common.NDB_TAG_NAME_CR_UID:       "UID of the Database custom resource",
+	}
+	entityTypes := []string{
+		common.TAG_ENTITY_TYPE_DATABASE,
+		common.TAG_ENTITY_TYPE_CLONE,
+		common.TAG_ENTITY_TYPE_TIME_MACHINE,
+		common.TAG_ENTITY_TYPE_DATABASE_SERVER,
+	}
+	for _, entityType := range entityTypes {
+		for _, name := range []string{common.NDB_TAG_NAME_CR_UID, common.NDB_TAG_NAME_CLUSTER_ID, common.NDB_TAG_NAME_CR_NAME, common.NDB_TAG_NAME_CR_NAMESPACE} {
+			tags = append(tags, ndb_api.TagResponse{
+				Id:          uuid.NewString(),
+				Name:        name,
+				Description: descriptions[name],
+				EntityType:  entityType,
+				Status:      common.TAG_STATUS_ENABLED,
+			})
+		}
+	}
+	return
+}
+
+// Returns the out of box SLAs
+func newSLAs() (slas []ndb_api.SLAResponse) {
+	for _, name := range simulatedSLAs {
+		sla := ndb_api.SLAResponse{Id: uuid.NewString(), Name: name, UniqueName: name, Description: "Simulated SLA " + name}
+		if name != "NONE" {
+			sla.ContinuousRetention, sla.DailyRetention, sla.WeeklyRetention, sla.MonthlyRetention, sla.QuarterlyRetention = 7, 7, 2, 2, 1
+		}
+		slas = append(slas, sla)
+	}
+	return
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_simulator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Fault is injected in the responses to the requests matching its method and path
+type Fault struct {
+	// Method of the matching requests, any method if empty
+	Method string `json:"method,omitempty"`
+	// Prefix of the path of the matching requests without the /era/v0.9 prefix (e.g. "databases/provision"), any path if empty
+	Path string `json:"path,omitempty"`
+	// Status of the error response sent instead of handling the request (e.g. 500), the request is handled if 0
+	StatusCode int `json:"statusCode,omitempty"`
+	// Delay before the response is sent
+	Latency time.Duration `json:"-"`
+	// The operation started by the request (provisioning, cloning, deletion or snapshot) fails
+	FailOperation bool `json:"failOperation,omitempty"`
+	// Number of matching requests the fault is injected in, every matching request if 0
+	Count int `json:"count,omitempty"`
+}
+
+// Injects a fault in the responses to the matching requests. The faults are matched in the order they were added.
+func (s *Simulator) AddFault(fault Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Removes all the injected faults
+func (s *Simulator) ClearFaults() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = nil
+}
+
+// Returns the first fault matching the request, the faults with a count are removed once used up
+func (s *Simulator) matchFault(method, path string) *Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, fault := range s.faults {
+		if (fault.Method == "" || strings.EqualFold(fault.Method, method)) && strings.HasPrefix(path, strings.Trim(fault.Path, "/")) {
+			if fault.Count > 0 {
+				fault.Count--
+				if fault.Count == 0 {
+					s.faults = append(s.faults[:i], s.faults[i+1:]...)
+				}
+			}
+			matched := *fault
+			return &matched
+		}
+	}
+	return nil
+}
+
+type failedOperationKey struct{}
+
+// Marks the operation started by the request as failed
+func withFailedOperation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, failedOperationKey{}, true)
+}
+
+func isFailedOperation(ctx context.Context) bool {
+	failed, _ := ctx.Value(failedOperationKey{}).(bool)
+	return failed
+}
+
+// Serves the API of the simulator for the standalone mode:
+//
+//	POST   /simulator/faults adds the fault in the request body
+//	DELETE /simulator/faults removes all the faults
+func (s *Simulator) serveSimulatorAPI(w http.ResponseWriter, r *http.Request) {
+	if strings.Trim(strings.TrimPrefix(r.URL.Path, SIMULATOR_API_PREFIX), "/") != "faults" {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		// The latency is a duration string, e.g. "2s"
+		var request struct {
+			Fault
+			Latency string `json:"latency,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeBadRequest(w, "Invalid fault: "+err.Error())
+			return
+		}
+		if request.Latency != "" {
+			latency, err := time.ParseDuration(request.Latency)
+			if err != nil {
+				writeBadRequest(w, "Invalid fault latency: "+err.Error())
+				return
+			}
+			request.Fault.Latency = latency
+		}
+		s.AddFault(request.Fault)
+		writeJSON(w, http.StatusCreated, request)
+	case http.MethodDelete:
+		s.ClearFaults()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "NDB-405", "Method not allowed", "")
+	}
+}
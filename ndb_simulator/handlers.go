@@ -0,0 +1,610 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_simulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+func (s *Simulator) handleListClusters(w http.ResponseWriter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, s.clusters)
+}
+
+// Lists the profiles, filtered by the type and engine query parameters
+func (s *Simulator) handleListProfiles(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	profileType, engine := r.URL.Query().Get("type"), r.URL.Query().Get("engine")
+	profiles := []ndb_api.ProfileResponse{}
+	for _, profile := range s.profiles {
+		if (profileType == "" || profile.Type == profileType) && (engine == "" || profile.EngineType == engine) {
+			profiles = append(profiles, profile)
+		}
+	}
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+// Lists the tags, filtered by the entityType query parameter
+func (s *Simulator) handleListTags(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entityType := r.URL.Query().Get("entityType")
+	tags := []ndb_api.TagResponse{}
+	for _, tag := range s.tags {
+		if entityType == "" || tag.EntityType == entityType {
+			tags = append(tags, tag)
+		}
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// Returns the error reason if a tag of a request is not defined for the entity type
+func (s *Simulator) validateTags(tags []ndb_api.Tag, entityType string) string {
+	for _, tag := range tags {
+		defined := false
+		for _, definedTag := range s.tags {
+			defined = defined || (definedTag.Id == tag.TagId && definedTag.EntityType == entityType)
+		}
+		if !defined {
+			return "Tag " + tag.TagName + " is not defined for " + entityType
+		}
+	}
+	return ""
+}
+
+// Returns the error reason if a tag of the database servers of the nodes is not defined for DATABASE_SERVER
+func (s *Simulator) validateNodeTags(nodes []ndb_api.Node) string {
+	for _, node := range nodes {
+		if reason := s.validateTags(node.Tags, common.TAG_ENTITY_TYPE_DATABASE_SERVER); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// Returns the tags of the database server of the first node, the simulator creates a single database server
+func getNodeTags(nodes []ndb_api.Node) []ndb_api.Tag {
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0].Tags
+}
+
+func (s *Simulator) handleListSLAs(w http.ResponseWriter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, s.slas)
+}
+
+// Lists the databases or clones, supports the value-type/value filters, detailed and paging (limit and offset)
+func (s *Simulator) handleListDatabases(w http.ResponseWriter, r *http.Request, isClone bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	query := r.URL.Query()
+	valueType, value := query.Get("value-type"), query.Get("value")
+	detailed := query.Get("detailed") == "true"
+	databases := []ndb_api.DatabaseResponse{}
+	for _, db := range s.databases {
+		if db.isClone != isClone {
+			continue
+		}
+		if (valueType == "name" && db.Name != value) || (valueType == "id" && db.Id != value) {
+			continue
+		}
+		databases = append(databases, s.getDatabaseResponse(db, detailed))
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		offset = min(max(offset, 0), len(databases))
+		databases = databases[offset:min(offset+limit, len(databases))]
+	}
+	writeJSON(w, http.StatusOK, databases)
+}
+
+// Returns a database or clone by id, or by name with value-type=name
+func (s *Simulator) handleGetDatabase(w http.ResponseWriter, r *http.Request, idOrName string, isClone bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	byName := r.URL.Query().Get("value-type") == "name"
+	db := s.findDatabase(isClone, func(db *database) bool {
+		return (byName && db.Name == idOrName) || (!byName && db.Id == idOrName)
+	})
+	if db == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.getDatabaseResponse(db, r.URL.Query().Get("detailed") == "true"))
+}
+
+// Returns the response of a request starting an operation on an entity
+func getTaskResponse(op *operation, entityId, entityName, entityType, dbServerId string) ndb_api.TaskInfoSummaryResponse {
+	return ndb_api.TaskInfoSummaryResponse{
+		Name:        op.Name,
+		WorkId:      uuid.NewString(),
+		OperationId: op.Id,
+		DbServerId:  dbServerId,
+		EntityId:    entityId,
+		EntityName:  entityName,
+		EntityType:  entityType,
+		Status:      op.Status,
+	}
+}
+
+// Returns the error reason if the provisioning request references a missing cluster, SLA or profile
+func (s *Simulator) validateProvisioningRequest(req *ndb_api.DatabaseProvisionRequest) string {
+	if req.Name == "" {
+		return "The database name is required"
+	}
+	if s.isDatabaseNameTaken(req.Name) {
+		return "A database with the name " + req.Name + " already exists"
+	}
+	if req.NxClusterId != s.options.ClusterId {
+		return "Cluster " + req.NxClusterId + " not found"
+	}
+	slaFound := false
+	for _, sla := range s.slas {
+		slaFound = slaFound || sla.Id == req.TimeMachineInfo.SlaId
+	}
+	if !slaFound {
+		return "SLA " + req.TimeMachineInfo.SlaId + " not found"
+	}
+	for _, profileId := range []string{req.SoftwareProfileId, req.ComputeProfileId, req.NetworkProfileId, req.DbParameterProfileId} {
+		profileFound := false
+		for _, profile := range s.profiles {
+			profileFound = profileFound || profile.Id == profileId
+		}
+		if !profileFound {
+			return "Profile " + profileId + " not found"
+		}
+	}
+	return ""
+}
+
+func (s *Simulator) handleProvisionDatabase(w http.ResponseWriter, r *http.Request) {
+	var req ndb_api.DatabaseProvisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid request body: "+err.Error())
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	if reason := s.validateProvisioningRequest(&req); reason != "" {
+		writeBadRequest(w, reason)
+		return
+	}
+	if reason := s.validateTags(req.Tags, common.TAG_ENTITY_TYPE_DATABASE); reason != "" {
+		writeBadRequest(w, reason)
+		return
+	}
+	if reason := s.validateTags(req.TimeMachineInfo.Tags, common.TAG_ENTITY_TYPE_TIME_MACHINE); reason != "" {
+		writeBadRequest(w, reason)
+		return
+	}
+	if reason := s.validateNodeTags(req.Nodes); reason != "" {
+		writeBadRequest(w, reason)
+		return
+	}
+	db := s.addDatabase(req.Name, req.DatabaseType, req.NxClusterId, false)
+	db.Description = req.DatabaseDescription
+	db.Tags = req.Tags
+	for _, argument := range req.ActionArguments {
+		if argument.Name == common.PROPERTY_NAME_DATABASE_SIZE {
+			db.Properties = append(db.Properties, ndb_api.Property{Name: common.PROPERTY_NAME_DATABASE_SIZE, Value: argument.Value})
+		}
+	}
+	if tm := s.timeMachines[db.TimeMachineId]; tm != nil && req.TimeMachineInfo.Name != "" {
+		tm.Name = req.TimeMachineInfo.Name
+		tm.Description = req.TimeMachineInfo.Description
+		tm.Sla = s.getTimeMachineSLA(req.TimeMachineInfo.SlaId)
+		tm.Schedule = getTimeMachineSchedule(req.TimeMachineInfo.Schedule)
+		tm.Tags = req.TimeMachineInfo.Tags
+	}
+	if dbServer := s.dbServers[db.DatabaseNodes[0].DatabaseServerId]; dbServer != nil {
+		dbServer.Tags = getNodeTags(req.Nodes)
+		dbServer.Properties = []ndb_api.Property{
+			{Name: common.PROPERTY_NAME_COMPUTE_PROFILE_ID, Value: req.ComputeProfileId},
+			{Name: common.PROPERTY_NAME_NETWORK_PROFILE_ID, Value: req.NetworkProfileId},
+			{Name: common.PROPERTY_NAME_SOFTWARE_PROFILE_ID, Value: req.SoftwareProfileId},
+		}
+	}
+	op := s.startOperation(r.Context(), "Provision database "+req.Name, db.Id, "ERA_DATABASE",
+		func() { s.setDatabaseReady(db) },
+		func() { db.Status = DATABASE_STATUS_ERROR },
+	)
+	writeJSON(w, http.StatusAccepted, getTaskResponse(op, db.Id, db.Name, "ERA_DATABASE", db.DatabaseNodes[0].DatabaseServerId))
+}
+
+// Returns the SLA of a time machine, with the name of the SLA with the id. Requires the lock.
+func (s *Simulator) getTimeMachineSLA(slaId string) ndb_api.TimeMachineSLA {
+	for _, sla := range s.slas {
+		if sla.Id == slaId {
+			return ndb_api.TimeMachineSLA{
+				Id:                  sla.Id,
+				Name:                sla.Name,
+				ContinuousRetention: sla.ContinuousRetention,
+				DailyRetention:      sla.DailyRetention,
+				WeeklyRetention:     sla.WeeklyRetention,
+				MonthlyRetention:    sla.MonthlyRetention,
+				QuarterlyRetention:  sla.QuarterlyRetention,
+				YearlyRetention:     sla.YearlyRetention,
+			}
+		}
+	}
+	return ndb_api.TimeMachineSLA{Id: slaId}
+}
+
+// Returns the schedule of a time machine as reported by NDB for the schedule of a request
+func getTimeMachineSchedule(schedule ndb_api.Schedule) ndb_api.TimeMachineSchedule {
+	return ndb_api.TimeMachineSchedule{
+		Id: uuid.NewString(),
+		SnapshotTimeOfDay: ndb_api.TimeMachineSnapshotTimeOfDay{
+			Hours:   schedule.SnapshotTimeOfDay.Hours,
+			Minutes: schedule.SnapshotTimeOfDay.Minutes,
+			Seconds: schedule.SnapshotTimeOfDay.Seconds,
+		},
+		ContinuousSchedule: ndb_api.TimeMachineContinuousSchedule{
+			Enabled:           schedule.ContinuousSchedule.Enabled,
+			LogBackupInterval: schedule.ContinuousSchedule.LogBackupInterval,
+			SnapshotsPerDay:   schedule.ContinuousSchedule.SnapshotsPerDay,
+		},
+		WeeklySchedule: ndb_api.TimeMachineWeeklySchedule{
+			Enabled:   schedule.WeeklySchedule.Enabled,
+			DayOfWeek: schedule.WeeklySchedule.DayOfWeek,
+		},
+		MonthlySchedule: ndb_api.TimeMachineMonthlySchedule{
+			Enabled:    schedule.MonthlySchedule.Enabled,
+			DayOfMonth: schedule.MonthlySchedule.DayOfMonth,
+		},
+		QuarterlySchedule: ndb_api.TimeMachineQuarterlySchedule{
+			Enabled:    schedule.QuarterlySchedule.Enabled,
+			StartMonth: schedule.QuarterlySchedule.StartMonth,
+			DayOfMonth: schedule.QuarterlySchedule.DayOfMonth,
+		},
+		YearlySchedule: ndb_api.TimeMachineYearlySchedule{
+			Enabled:    schedule.YearlySchedule.Enabled,
+			DayOfMonth: schedule.YearlySchedule.DayOfMonth,
+			Month:      schedule.YearlySchedule.Month,
+		},
+	}
+}
+
+// Updates the name, description and tags of a database or clone as per the reset flags of the request
+func (s *Simulator) handleUpdateDatabase(w http.ResponseWriter, r *http.Request, id string, isClone bool) {
+	var req ndb_api.DatabaseUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid request body: "+err.Error())
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	db := s.findDatabase(isClone, func(db *database) bool { return db.Id == id })
+	if db == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	if req.ResetName {
+		if req.Name != db.Name && s.isDatabaseNameTaken(req.Name) {
+			writeBadRequest(w, "A database with the name "+req.Name+" already exists")
+			return
+		}
+		db.Name = req.Name
+	}
+	if req.ResetTags {
+		entityType := common.TAG_ENTITY_TYPE_DATABASE
+		if isClone {
+			entityType = common.TAG_ENTITY_TYPE_CLONE
+		}
+		if reason := s.validateTags(req.Tags, entityType); reason != "" {
+			writeBadRequest(w, reason)
+			return
+		}
+		db.Tags = req.Tags
+	}
+	if req.ResetDescription {
+		db.Description = req.Description
+	}
+	writeJSON(w, http.StatusOK, s.getDatabaseResponse(db, false))
+}
+
+func (s *Simulator) handleCloneDatabase(w http.ResponseWriter, r *http.Request, tmId string) {
+	var req ndb_api.DatabaseCloneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid request body: "+err.Error())
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	tm := s.timeMachines[tmId]
+	if tm == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	source := s.findDatabase(false, func(db *database) bool { return db.Id == tm.DatabaseId })
+	switch {
+	case req.Name == "":
+		writeBadRequest(w, "The clone name is required")
+		return
+	case s.isDatabaseNameTaken(req.Name):
+		writeBadRequest(w, "A database with the name "+req.Name+" already exists")
+		return
+	case req.NxClusterId != s.options.ClusterId:
+		writeBadRequest(w, "Cluster "+req.NxClusterId+" not found")
+		return
+	case source == nil:
+		writeBadRequest(w, "The source database of time machine "+tmId+" not found")
+		return
+	}
+	if req.SnapshotId != "" {
+		snapshotFound := false
+		for _, snapshotId := range tm.snapshotIds {
+			snapshotFound = snapshotFound || snapshotId == req.SnapshotId
+		}
+		if !snapshotFound {
+			writeBadRequest(w, "Snapshot "+req.SnapshotId+" not found")
+			return
+		}
+	}
+	if reason := s.validateTags(req.Tags, common.TAG_ENTITY_TYPE_CLONE); reason != "" {
+		writeBadRequest(w, reason)
+		return
+	}
+	if reason := s.validateNodeTags(req.Nodes); reason != "" {
+		writeBadRequest(w, reason)
+		return
+	}
+	clone := s.addDatabase(req.Name, source.Type, req.NxClusterId, true)
+	clone.Tags = req.Tags
+	if dbServer := s.dbServers[clone.DatabaseNodes[0].DatabaseServerId]; dbServer != nil {
+		dbServer.Tags = getNodeTags(req.Nodes)
+	}
+	op := s.startOperation(r.Context(), "Clone database "+source.Name+" to "+req.Name, clone.Id, "ERA_DATABASE",
+		func() { s.setDatabaseReady(clone) },
+		func() { clone.Status = DATABASE_STATUS_ERROR },
+	)
+	writeJSON(w, http.StatusAccepted, getTaskResponse(op, clone.Id, clone.Name, "ERA_DATABASE", clone.DatabaseNodes[0].DatabaseServerId))
+}
+
+// Deletes a database or clone, the time machine is deleted if requested
+func (s *Simulator) handleDeleteDatabase(w http.ResponseWriter, r *http.Request, id string, isClone bool) {
+	var req struct {
+		DeleteTimeMachine bool `json:"deleteTimeMachine"`
+	}
+	// The body is optional
+	json.NewDecoder(r.Body).Decode(&req)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	db := s.findDatabase(isClone, func(db *database) bool { return db.Id == id })
+	if db == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	previousStatus := db.Status
+	db.Status = DATABASE_STATUS_DELETING
+	op := s.startOperation(r.Context(), "Delete database "+db.Name, db.Id, "ERA_DATABASE",
+		func() { s.removeDatabase(db.Id, req.DeleteTimeMachine) },
+		func() { db.Status = previousStatus },
+	)
+	writeJSON(w, http.StatusAccepted, getTaskResponse(op, db.Id, db.Name, "ERA_DATABASE", ""))
+}
+
+// Lists the database servers ordered by id
+func (s *Simulator) handleListDatabaseServers(w http.ResponseWriter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	dbServers := make([]ndb_api.DatabaseServerResponse, 0, len(s.dbServers))
+	for _, dbServer := range s.dbServers {
+		dbServers = append(dbServers, *dbServer)
+	}
+	sort.Slice(dbServers, func(i, j int) bool { return dbServers[i].Id < dbServers[j].Id })
+	writeJSON(w, http.StatusOK, dbServers)
+}
+
+func (s *Simulator) handleGetDatabaseServer(w http.ResponseWriter, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	dbServer := s.dbServers[id]
+	if dbServer == nil {
+		writeNotFound(w, "dbservers/"+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, dbServer)
+}
+
+// Updates the name, description and tags of a database server as per the reset flags of the request
+func (s *Simulator) handleUpdateDatabaseServer(w http.ResponseWriter, r *http.Request, id string) {
+	var req ndb_api.DatabaseServerUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid request body: "+err.Error())
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	dbServer := s.dbServers[id]
+	if dbServer == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	if req.ResetTags {
+		if reason := s.validateTags(req.Tags, common.TAG_ENTITY_TYPE_DATABASE_SERVER); reason != "" {
+			writeBadRequest(w, reason)
+			return
+		}
+		dbServer.Tags = req.Tags
+	}
+	if req.ResetName {
+		dbServer.Name = req.Name
+	}
+	writeJSON(w, http.StatusOK, dbServer)
+}
+
+func (s *Simulator) handleDeleteDatabaseServer(w http.ResponseWriter, r *http.Request, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	dbServer := s.dbServers[id]
+	if dbServer == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	op := s.startOperation(r.Context(), "Delete database server "+dbServer.Name, id, "ERA_DBSERVER",
+		func() { delete(s.dbServers, id) },
+		nil,
+	)
+	writeJSON(w, http.StatusAccepted, getTaskResponse(op, id, dbServer.Name, "ERA_DBSERVER", id))
+}
+
+// Lists the operations, filtered by the entity-id query parameter, in order of start
+func (s *Simulator) handleListOperations(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	entityId := r.URL.Query().Get("entity-id")
+	var ops []*operation
+	for _, op := range s.operations {
+		if entityId == "" || op.EntityId == entityId {
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].startedAt.Before(ops[j].startedAt) })
+	response := ndb_api.OperationListResponse{Operations: []ndb_api.OperationResponse{}}
+	for _, op := range ops {
+		response.Operations = append(response.Operations, op.OperationResponse)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Simulator) handleGetOperation(w http.ResponseWriter, r *http.Request, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	op := s.operations[id]
+	if op == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	writeJSON(w, http.StatusOK, op.OperationResponse)
+}
+
+func (s *Simulator) handleGetTimeMachine(w http.ResponseWriter, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	tm := s.timeMachines[id]
+	if tm == nil {
+		writeNotFound(w, "tms/"+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, tm.TimeMachineResponse)
+}
+
+// Updates the name, description, SLA, schedule and tags of a time machine as per the reset flags of the request
+func (s *Simulator) handleUpdateTimeMachine(w http.ResponseWriter, r *http.Request, id string) {
+	var req ndb_api.TimeMachineUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid request body: "+err.Error())
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	tm := s.timeMachines[id]
+	if tm == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	if req.ResetSlaId {
+		sla := s.getTimeMachineSLA(req.SlaId)
+		if sla.Name == "" {
+			writeBadRequest(w, "SLA "+req.SlaId+" not found")
+			return
+		}
+		tm.Sla = sla
+	}
+	if req.ResetTags {
+		if reason := s.validateTags(req.Tags, common.TAG_ENTITY_TYPE_TIME_MACHINE); reason != "" {
+			writeBadRequest(w, reason)
+			return
+		}
+		tm.Tags = req.Tags
+	}
+	if req.ResetName {
+		tm.Name = req.Name
+	}
+	if req.ResetDescription {
+		tm.Description = req.Description
+	}
+	if req.ResetSchedule {
+		tm.Schedule = getTimeMachineSchedule(req.Schedule)
+	}
+	writeJSON(w, http.StatusOK, tm.TimeMachineResponse)
+}
+
+// Returns the snapshots of a time machine, grouped by the cluster of the source database
+func (s *Simulator) handleGetSnapshots(w http.ResponseWriter, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	tm := s.timeMachines[id]
+	if tm == nil {
+		writeNotFound(w, "tms/"+id+"/snapshots")
+		return
+	}
+	snapshots := make([]ndb_api.Snapshot, len(tm.snapshotIds))
+	for i, snapshotId := range tm.snapshotIds {
+		snapshots[i] = ndb_api.Snapshot{Id: snapshotId}
+	}
+	writeJSON(w, http.StatusOK, ndb_api.TimeMachineGetSnapshotsResponse{
+		SnapshotsPerNxCluster: map[string][]ndb_api.SnapshotsParentInfoPerCluster{
+			s.options.ClusterId: {{Snapshots: snapshots}},
+		},
+	})
+}
+
+func (s *Simulator) handleCreateSnapshot(w http.ResponseWriter, r *http.Request, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	tm := s.timeMachines[id]
+	if tm == nil {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	snapshotId := uuid.NewString()
+	op := s.startOperation(r.Context(), "Create snapshot of "+tm.Name, tm.Id, "ERA_TIME_MACHINE",
+		func() { tm.snapshotIds = append(tm.snapshotIds, snapshotId) },
+		nil,
+	)
+	writeJSON(w, http.StatusAccepted, getTaskResponse(op, snapshotId, tm.Name, "ERA_SNAPSHOT", ""))
+}
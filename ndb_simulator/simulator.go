@@ -0,0 +1,243 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package ndb_simulator simulates the subset of the NDB API used by the operator, for development
+and tests without an NDB server and a Nutanix cluster. It keeps an in-memory model of the databases,
+clones, database servers, time machines, snapshots, profiles, SLAs and operations. The operations
+complete after a configurable duration and faults (error responses, latency and failed operations)
+can be injected for the requests matching a method and path.
+*/
+package ndb_simulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+const (
+	// Default time taken by the operations (provisioning, cloning, deletion, snapshots)
+	DEFAULT_OPERATION_DURATION = 10 * time.Second
+	DEFAULT_CLUSTER_ID         = "00000000-0000-0000-0000-000000000001"
+	DEFAULT_CLUSTER_NAME       = "simulated-cluster"
+
+	// Prefix of the NDB API paths, optional in the requests to the simulator
+	API_PREFIX = "/era/v0.9"
+	// Prefix of the paths of the simulator's own API (fault injection)
+	SIMULATOR_API_PREFIX = "/simulator"
+)
+
+// Options of the simulator, the zero value is a simulator accepting any credentials
+type Options struct {
+	// Credentials accepted by the simulator, any credentials are accepted if Username is empty
+	Username string
+	Password string
+	// Time taken by the operations, DEFAULT_OPERATION_DURATION if 0
+	OperationDuration time.Duration
+	// Id and name of the simulated cluster, DEFAULT_CLUSTER_ID and DEFAULT_CLUSTER_NAME if empty
+	ClusterId   string
+	ClusterName string
+	// Returns the current time, time.Now if nil. Used to control the progress of the operations in tests.
+	Now func() time.Time
+}
+
+// Simulator is an http.Handler serving the NDB API. Use httptest.NewServer(simulator) to run it in process,
+// the NDB client's server URL is the URL of the test server (with or without the /era/v0.9 prefix).
+type Simulator struct {
+	options Options
+
+	mutex        sync.Mutex
+	clusters     []ndb_api.ClusterResponse
+	profiles     []ndb_api.ProfileResponse
+	slas         []ndb_api.SLAResponse
+	tags         []ndb_api.TagResponse
+	databases    []*database
+	dbServers    map[string]*ndb_api.DatabaseServerResponse
+	timeMachines map[string]*timeMachine
+	operations   map[string]*operation
+	tokens       map[string]bool
+	faults       []*Fault
+	// Used to assign unique IP addresses to the database servers
+	ipCounter int
+}
+
+// Returns a simulator with the out of box profiles and SLAs and a single cluster
+func New(options Options) *Simulator {
+	if options.OperationDuration == 0 {
+		options.OperationDuration = DEFAULT_OPERATION_DURATION
+	}
+	if options.ClusterId == "" {
+		options.ClusterId = DEFAULT_CLUSTER_ID
+	}
+	if options.ClusterName == "" {
+		options.ClusterName = DEFAULT_CLUSTER_NAME
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	return &Simulator{
+		options:      options,
+		clusters:     []ndb_api.ClusterResponse{newCluster(options.ClusterId, options.ClusterName)},
+		profiles:     newProfiles(),
+		slas:         newSLAs(),
+		tags:         newTags(),
+		dbServers:    make(map[string]*ndb_api.DatabaseServerResponse),
+		timeMachines: make(map[string]*timeMachine),
+		operations:   make(map[string]*operation),
+		tokens:       make(map[string]bool),
+	}
+}
+
+func (s *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, SIMULATOR_API_PREFIX+"/") {
+		s.serveSimulatorAPI(w, r)
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, API_PREFIX), "/")
+	if !s.isAuthorized(r, path) {
+		writeError(w, http.StatusUnauthorized, "NDB-401", "Invalid credentials", "Provide a valid username and password")
+		return
+	}
+	if fault := s.matchFault(r.Method, path); fault != nil {
+		if fault.Latency > 0 {
+			time.Sleep(fault.Latency)
+		}
+		if fault.StatusCode != 0 {
+			writeError(w, fault.StatusCode, "NDB-SIMULATED", "Simulated failure", "")
+			return
+		}
+		if fault.FailOperation {
+			r = r.WithContext(withFailedOperation(r.Context()))
+		}
+	}
+	s.route(w, r, strings.Split(path, "/"))
+}
+
+// Returns true if the request has the basic auth credentials or a token issued by the simulator
+func (s *Simulator) isAuthorized(r *http.Request, path string) bool {
+	if s.options.Username == "" {
+		return true
+	}
+	if username, password, ok := r.BasicAuth(); ok {
+		return username == s.options.Username && password == s.options.Password
+	}
+	// Tokens are only issued for the basic auth credentials
+	if path == "auth/token" {
+		return false
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return found && s.tokens[token]
+}
+
+// Serves the NDB API endpoints used by the operator
+func (s *Simulator) route(w http.ResponseWriter, r *http.Request, segments []string) {
+	resource, id, subresource := segments[0], "", ""
+	if len(segments) > 1 {
+		id = segments[1]
+	}
+	if len(segments) > 2 {
+		subresource = segments[2]
+	}
+	if len(segments) > 3 {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+
+	switch {
+	case resource == "auth" && id == "validate" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, ndb_api.AuthValidateResponse{Status: "success", Message: "Authentication successful"})
+	case resource == "auth" && id == "token" && r.Method == http.MethodGet:
+		s.handleGetToken(w)
+	case resource == "clusters" && id == "" && r.Method == http.MethodGet:
+		s.handleListClusters(w)
+	case resource == "profiles" && id == "" && r.Method == http.MethodGet:
+		s.handleListProfiles(w, r)
+	case resource == "slas" && id == "" && r.Method == http.MethodGet:
+		s.handleListSLAs(w)
+	case resource == "tags" && id == "" && r.Method == http.MethodGet:
+		s.handleListTags(w, r)
+	case resource == "databases" && id == "provision" && r.Method == http.MethodPost:
+		s.handleProvisionDatabase(w, r)
+	case (resource == "databases" || resource == "clones") && id == "" && r.Method == http.MethodGet:
+		s.handleListDatabases(w, r, resource == "clones")
+	case (resource == "databases" || resource == "clones") && id != "" && subresource == "" && r.Method == http.MethodGet:
+		s.handleGetDatabase(w, r, id, resource == "clones")
+	case (resource == "databases" || resource == "clones") && id != "" && subresource == "" && r.Method == http.MethodDelete:
+		s.handleDeleteDatabase(w, r, id, resource == "clones")
+	case (resource == "databases" || resource == "clones") && id != "" && subresource == "" && r.Method == http.MethodPatch:
+		s.handleUpdateDatabase(w, r, id, resource == "clones")
+	case resource == "dbservers" && id == "" && r.Method == http.MethodGet:
+		s.handleListDatabaseServers(w)
+	case resource == "dbservers" && id != "" && subresource == "" && r.Method == http.MethodGet:
+		s.handleGetDatabaseServer(w, id)
+	case resource == "dbservers" && id != "" && subresource == "" && r.Method == http.MethodPatch:
+		s.handleUpdateDatabaseServer(w, r, id)
+	case resource == "dbservers" && id != "" && subresource == "" && r.Method == http.MethodDelete:
+		s.handleDeleteDatabaseServer(w, r, id)
+	case resource == "operations" && id == "" && r.Method == http.MethodGet:
+		s.handleListOperations(w, r)
+	case resource == "operations" && id != "" && subresource == "" && r.Method == http.MethodGet:
+		s.handleGetOperation(w, r, id)
+	case resource == "tms" && id != "" && subresource == "" && r.Method == http.MethodGet:
+		s.handleGetTimeMachine(w, id)
+	case resource == "tms" && id != "" && subresource == "" && r.Method == http.MethodPatch:
+		s.handleUpdateTimeMachine(w, r, id)
+	case resource == "tms" && id != "" && subresource == "snapshots" && r.Method == http.MethodGet:
+		s.handleGetSnapshots(w, id)
+	case resource == "tms" && id != "" && subresource == "snapshots" && r.Method == http.MethodPost:
+		s.handleCreateSnapshot(w, r, id)
+	case resource == "tms" && id != "" && subresource == "clones" && r.Method == http.MethodPost:
+		s.handleCloneDatabase(w, r, id)
+	default:
+		writeNotFound(w, r.URL.Path)
+	}
+}
+
+func (s *Simulator) handleGetToken(w http.ResponseWriter) {
+	token := uuid.NewString()
+	s.mutex.Lock()
+	s.tokens[token] = true
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+// Writes the response as JSON
+func writeJSON(w http.ResponseWriter, statusCode int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Writes an error response in the format of NDB
+func writeError(w http.ResponseWriter, statusCode int, errorCode, reason, remedy string) {
+	writeJSON(w, statusCode, ndb_api.NDBError{ErrorCode: errorCode, Reason: reason, Remedy: remedy, Message: reason})
+}
+
+func writeNotFound(w http.ResponseWriter, path string) {
+	writeError(w, http.StatusNotFound, "NDB-404", "Not found: "+path, "")
+}
+
+func writeBadRequest(w http.ResponseWriter, reason string) {
+	writeError(w, http.StatusBadRequest, "NDB-400", reason, "Correct the request and send it again")
+}
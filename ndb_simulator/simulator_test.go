@@ -0,0 +1,339 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_simulator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_client"
+	"github.com/stretchr/testify/assert"
+)
+
+// Clock controlling the progress of the operations of the simulator
+type testClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Starts a simulator with a test clock and returns it with a client sending requests to it
+func setupSimulator(t *testing.T) (*Simulator, *testClock, *ndb_client.NDBClient) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	simulator := New(Options{Username: "username", Password: "password", OperationDuration: time.Minute, Now: clock.Now})
+	server := httptest.NewServer(simulator)
+	t.Cleanup(server.Close)
+	// Disable the cache of the profiles, SLAs and clusters
+	ndb_api.SetCache(nil)
+	return simulator, clock, ndb_client.NewNDBClient("username", "password", server.URL+API_PREFIX, "", true)
+}
+
+// Returns a provisioning request for a postgres database referencing the profiles and SLA of the simulator
+func getProvisioningRequest(t *testing.T, ndbClient *ndb_client.NDBClient, name string) *ndb_api.DatabaseProvisionRequest {
+	ctx := context.Background()
+	profiles, err := ndb_api.GetAllProfiles(ctx, ndbClient)
+	assert.NoError(t, err)
+	req := &ndb_api.DatabaseProvisionRequest{
+		Name:         name,
+		DatabaseType: common.DATABASE_ENGINE_TYPE_POSTGRES,
+		NxClusterId:  DEFAULT_CLUSTER_ID,
+	}
+	for _, profile := range profiles {
+		switch {
+		case profile.Type == common.PROFILE_TYPE_COMPUTE:
+			req.ComputeProfileId = profile.Id
+		case profile.EngineType != common.DATABASE_ENGINE_TYPE_POSTGRES:
+		case profile.Type == common.PROFILE_TYPE_SOFTWARE:
+			req.SoftwareProfileId, req.SoftwareProfileVersionId = profile.Id, profile.LatestVersionId
+		case profile.Type == common.PROFILE_TYPE_NETWORK:
+			req.NetworkProfileId = profile.Id
+		case profile.Type == common.PROFILE_TYPE_DATABASE_PARAMETER:
+			req.DbParameterProfileId = profile.Id
+		}
+	}
+	slas, err := ndb_api.GetAllSLAs(ctx, ndbClient)
+	assert.NoError(t, err)
+	for _, sla := range slas {
+		if sla.Name == common.SLA_NAME_NONE {
+			req.TimeMachineInfo = ndb_api.TimeMachineInfo{Name: name + "_TM", SlaId: sla.Id}
+		}
+	}
+	return req
+}
+
+// Tests the provisioning of a database, tests the following cases:
+//  1. The operation progresses with the clock and the database is READY when it completes
+//  2. The detailed database has the IP address of its database server
+//  3. A database with the same name is rejected
+//  4. Requests referencing a missing profile are rejected
+func TestSimulator_Provision(t *testing.T) {
+	_, clock, ndbClient := setupSimulator(t)
+	ctx := context.Background()
+
+	req := getProvisioningRequest(t, ndbClient, "test-db")
+	task, err := ndb_api.ProvisionDatabase(ctx, ndbClient, req)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, task.OperationId)
+	assert.NotEmpty(t, task.EntityId)
+
+	database, err := ndb_api.GetDatabaseById(ctx, ndbClient, task.EntityId)
+	assert.NoError(t, err)
+	assert.Equal(t, DATABASE_STATUS_PROVISIONING, database.Status)
+
+	clock.Advance(30 * time.Second)
+	operation, err := ndb_api.GetOperationById(ctx, ndbClient, task.OperationId)
+	assert.NoError(t, err)
+	assert.Equal(t, "", ndb_api.GetOperationStatus(operation))
+	assert.Equal(t, "50", operation.PercentageComplete)
+
+	clock.Advance(30 * time.Second)
+	operation, err = ndb_api.GetOperationById(ctx, ndbClient, task.OperationId)
+	assert.NoError(t, err)
+	assert.Equal(t, ndb_api.OPERATION_STATUS_PASSED, ndb_api.GetOperationStatus(operation))
+	duration, err := ndb_api.GetOperationDuration(operation)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, duration)
+
+	database, err = ndb_api.GetDatabaseByName(ctx, ndbClient, "test-db")
+	assert.NoError(t, err)
+	assert.Equal(t, DATABASE_STATUS_READY, database.Status)
+	assert.NotEmpty(t, database.DatabaseNodes[0].DbServer.IPAddresses)
+
+	_, err = ndb_api.ProvisionDatabase(ctx, ndbClient, req)
+	assert.Equal(t, http.StatusBadRequest, ndb_api.AsNDBError(err).StatusCode)
+
+	req = getProvisioningRequest(t, ndbClient, "other-db")
+	req.NetworkProfileId = "missing"
+	_, err = ndb_api.ProvisionDatabase(ctx, ndbClient, req)
+	assert.Equal(t, http.StatusBadRequest, ndb_api.AsNDBError(err).StatusCode)
+}
+
+// Tests the updates of a database and its time machine, tests the following cases:
+//  1. The database server reports the profiles and tags of the provisioning request and is listed
+//  2. The description of the database is only updated with its reset flag
+//  3. The SLA and schedule of the time machine are updated, an unknown SLA is rejected
+func TestSimulator_Update(t *testing.T) {
+	_, clock, ndbClient := setupSimulator(t)
+	ctx := context.Background()
+
+	req := getProvisioningRequest(t, ndbClient, "test-db")
+	req.DatabaseDescription = "description"
+	definedTags, err := ndb_api.GetTagsForEntityType(ctx, ndbClient, common.TAG_ENTITY_TYPE_DATABASE_SERVER)
+	assert.NoError(t, err)
+	dbServerTags, undefined := ndb_api.ResolveTags(definedTags, map[string]string{common.NDB_TAG_NAME_CLUSTER_ID: "cluster-1"})
+	assert.Empty(t, undefined)
+	req.Nodes = []ndb_api.Node{{VmName: "test-db_VM", Tags: dbServerTags}}
+	task, err := ndb_api.ProvisionDatabase(ctx, ndbClient, req)
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+	database, err := ndb_api.GetDatabaseById(ctx, ndbClient, task.EntityId)
+	assert.NoError(t, err)
+	assert.Equal(t, "description", database.Description)
+
+	dbServer, err := ndb_api.GetDatabaseServerById(ctx, ndbClient, task.DbServerId)
+	assert.NoError(t, err)
+	assert.Contains(t, dbServer.Properties, ndb_api.Property{Name: common.PROPERTY_NAME_COMPUTE_PROFILE_ID, Value: req.ComputeProfileId})
+	assert.Equal(t, dbServerTags, dbServer.Tags)
+	dbServers, err := ndb_api.ListDatabaseServers(ctx, ndbClient, ndb_api.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []ndb_api.DatabaseServerResponse{*dbServer}, dbServers)
+
+	database, err = ndb_api.UpdateDatabase(ctx, ndbClient, database.Id, &ndb_api.DatabaseUpdateRequest{Description: "ignored"})
+	assert.NoError(t, err)
+	assert.Equal(t, "description", database.Description)
+	database, err = ndb_api.UpdateDatabase(ctx, ndbClient, database.Id, &ndb_api.DatabaseUpdateRequest{Description: "updated", ResetDescription: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", database.Description)
+
+	slas, err := ndb_api.GetAllSLAs(ctx, ndbClient)
+	assert.NoError(t, err)
+	schedule := ndb_api.Schedule{SnapshotTimeOfDay: ndb_api.SnapshotTimeOfDay{Hours: 6}}
+	timeMachine, err := ndb_api.UpdateTimeMachine(ctx, ndbClient, database.TimeMachineId, &ndb_api.TimeMachineUpdateRequest{
+		SlaId: slas[1].Id, Schedule: schedule, ResetSlaId: true, ResetSchedule: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, slas[1].Name, timeMachine.Sla.Name)
+	assert.Equal(t, 6, timeMachine.Schedule.SnapshotTimeOfDay.Hours)
+
+	_, err = ndb_api.UpdateTimeMachine(ctx, ndbClient, database.TimeMachineId, &ndb_api.TimeMachineUpdateRequest{SlaId: "missing", ResetSlaId: true})
+	assert.Equal(t, http.StatusBadRequest, ndb_api.AsNDBError(err).StatusCode)
+}
+
+// Tests cloning and snapshots, tests the following cases:
+//  1. A database added with AddDatabase has a time machine with a snapshot
+//  2. A created snapshot is listed once its operation completes
+//  3. A clone from a snapshot is READY once its operation completes and is listed as a clone
+//  4. Cloning a missing snapshot is rejected
+func TestSimulator_Clone(t *testing.T) {
+	simulator, clock, ndbClient := setupSimulator(t)
+	ctx := context.Background()
+
+	source, _ := simulator.GetDatabase(simulator.AddDatabase("source-db", common.DATABASE_ENGINE_TYPE_MYSQL))
+	snapshots, err := ndb_api.GetSnapshotsForTM(ctx, ndbClient, source.TimeMachineId)
+	assert.NoError(t, err)
+	assert.Len(t, snapshots.SnapshotsPerNxCluster[DEFAULT_CLUSTER_ID][0].Snapshots, 1)
+
+	_, err = ndb_api.CreateSnapshotForTM(ctx, ndbClient, source.TimeMachineId, "snapshot", "UTC", "1")
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+	snapshots, err = ndb_api.GetSnapshotsForTM(ctx, ndbClient, source.TimeMachineId)
+	assert.NoError(t, err)
+	assert.Len(t, snapshots.SnapshotsPerNxCluster[DEFAULT_CLUSTER_ID][0].Snapshots, 2)
+
+	req := &ndb_api.DatabaseCloneRequest{
+		Name:          "test-clone",
+		NxClusterId:   DEFAULT_CLUSTER_ID,
+		TimeMachineId: source.TimeMachineId,
+		SnapshotId:    snapshots.SnapshotsPerNxCluster[DEFAULT_CLUSTER_ID][0].Snapshots[1].Id,
+	}
+	task, err := ndb_api.ProvisionClone(ctx, ndbClient, req)
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+	clone, err := ndb_api.GetCloneById(ctx, ndbClient, task.EntityId)
+	assert.NoError(t, err)
+	assert.Equal(t, DATABASE_STATUS_READY, clone.Status)
+	assert.Equal(t, common.DATABASE_ENGINE_TYPE_MYSQL, clone.Type)
+
+	clones, err := ndb_api.GetAllClones(ctx, ndbClient)
+	assert.NoError(t, err)
+	assert.Len(t, clones, 1)
+	databases, err := ndb_api.GetAllDatabases(ctx, ndbClient)
+	assert.NoError(t, err)
+	assert.Len(t, databases, 1)
+
+	req.Name, req.SnapshotId = "other-clone", "missing"
+	_, err = ndb_api.ProvisionClone(ctx, ndbClient, req)
+	assert.Equal(t, http.StatusBadRequest, ndb_api.AsNDBError(err).StatusCode)
+}
+
+// Tests the deletion of databases and database servers, tests the following cases:
+//  1. The database is DELETING until the operation completes and is not found afterwards
+//  2. The time machine is deleted if requested
+//  3. The database server is deleted
+//  4. Deleting a missing database returns 404
+func TestSimulator_Delete(t *testing.T) {
+	simulator, clock, ndbClient := setupSimulator(t)
+	ctx := context.Background()
+
+	database, _ := simulator.GetDatabase(simulator.AddDatabase("test-db", common.DATABASE_ENGINE_TYPE_POSTGRES))
+	dbServerId := database.DatabaseNodes[0].DatabaseServerId
+
+	_, err := ndb_api.DeprovisionDatabase(ctx, ndbClient, database.Id, ndb_api.GenerateDeprovisionDatabaseRequest())
+	assert.NoError(t, err)
+	response, err := ndb_api.GetDatabaseById(ctx, ndbClient, database.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, DATABASE_STATUS_DELETING, response.Status)
+
+	clock.Advance(time.Minute)
+	_, err = ndb_api.GetDatabaseById(ctx, ndbClient, database.Id)
+	assert.True(t, ndb_api.IsNotFound(err))
+	_, err = ndb_api.GetTimeMachineById(ctx, ndbClient, database.TimeMachineId)
+	assert.True(t, ndb_api.IsNotFound(err))
+
+	_, err = ndb_api.DeprovisionDatabaseServer(ctx, ndbClient, dbServerId, ndb_api.GenerateDeprovisionDatabaseServerRequest())
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+	assert.False(t, simulator.HasDatabaseServer(dbServerId))
+
+	_, err = ndb_api.DeprovisionDatabase(ctx, ndbClient, database.Id, ndb_api.GenerateDeprovisionDatabaseRequest())
+	assert.True(t, ndb_api.IsNotFound(err))
+}
+
+// Tests the injected faults, tests the following cases:
+//  1. A fault with a status code is returned for the matching requests only, until its count is used up
+//     (GET requests are retried by the client, so a single 5xx response is not returned)
+//  2. A fault with latency delays the response
+//  3. A fault failing the operation results in a FAILED operation and an ERROR database
+func TestSimulator_Faults(t *testing.T) {
+	simulator, clock, ndbClient := setupSimulator(t)
+	ctx := context.Background()
+
+	simulator.AddFault(Fault{Method: http.MethodGet, Path: "databases", StatusCode: http.StatusInternalServerError, Count: 1})
+	_, err := ndb_api.GetAllClusters(ctx, ndbClient)
+	assert.NoError(t, err)
+	_, err = ndb_api.GetAllDatabases(ctx, ndbClient)
+	assert.NoError(t, err)
+
+	simulator.AddFault(Fault{Method: http.MethodPost, Path: "databases/provision", StatusCode: http.StatusServiceUnavailable, Count: 1})
+	_, err = ndb_api.ProvisionDatabase(ctx, ndbClient, getProvisioningRequest(t, ndbClient, "test-db"))
+	assert.Equal(t, http.StatusServiceUnavailable, ndb_api.AsNDBError(err).StatusCode)
+
+	simulator.AddFault(Fault{Path: "clusters", Latency: 100 * time.Millisecond, Count: 1})
+	start := time.Now()
+	_, err = ndb_api.GetAllClusters(ctx, ndbClient)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	simulator.AddFault(Fault{Path: "databases/provision", FailOperation: true})
+	task, err := ndb_api.ProvisionDatabase(ctx, ndbClient, getProvisioningRequest(t, ndbClient, "test-db"))
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+	operation, err := ndb_api.GetOperationById(ctx, ndbClient, task.OperationId)
+	assert.NoError(t, err)
+	assert.Equal(t, ndb_api.OPERATION_STATUS_FAILED, ndb_api.GetOperationStatus(operation))
+	database, err := ndb_api.GetDatabaseById(ctx, ndbClient, task.EntityId)
+	assert.NoError(t, err)
+	assert.Equal(t, DATABASE_STATUS_ERROR, database.Status)
+
+	simulator.ClearFaults()
+	_, err = ndb_api.ProvisionDatabase(ctx, ndbClient, getProvisioningRequest(t, ndbClient, "other-db"))
+	assert.NoError(t, err)
+}
+
+// Tests the authentication, tests the following cases:
+//  1. Invalid credentials are rejected with 401
+//  2. A token issued for valid credentials is accepted
+//  3. Databases are listed in pages
+func TestSimulator_AuthAndPaging(t *testing.T) {
+	simulator, _, ndbClient := setupSimulator(t)
+	ctx := context.Background()
+
+	invalidClient := ndb_client.NewNDBClient("username", "invalid", ndbClient.GetServerURL(), "", true)
+	_, err := ndb_api.AuthValidate(ctx, invalidClient)
+	assert.True(t, ndb_api.IsUnauthorized(err))
+
+	tokenClient := ndb_client.NewNDBClientWithAuth(ndb_client.NewTokenAuth("username", "password", time.Hour), ndbClient.GetServerURL(), "", true, ndb_client.ClientOptions{})
+	_, err = ndb_api.AuthValidate(ctx, tokenClient)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"db-1", "db-2", "db-3"} {
+		simulator.AddDatabase(name, common.DATABASE_ENGINE_TYPE_POSTGRES)
+	}
+	databases, err := ndb_api.ListDatabases(ctx, tokenClient, ndb_api.ListOptions{PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, databases, 3)
+	databases, err = ndb_api.ListDatabases(ctx, tokenClient, ndb_api.ListOptions{ValueType: "name", Value: "db-2"})
+	assert.NoError(t, err)
+	assert.Len(t, databases, 1)
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2022-2023 Nutanix, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ndb_simulator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nutanix-cloud-native/ndb-operator/common"
+	"github.com/nutanix-cloud-native/ndb-operator/ndb_api"
+)
+
+const (
+	// Statuses of the databases and clones
+	DATABASE_STATUS_DELETING     = "DELETING"
+	DATABASE_STATUS_ERROR        = "ERROR"
+	DATABASE_STATUS_PROVISIONING = "PROVISIONING"
+	DATABASE_STATUS_READY        = "READY"
+
+	// Statuses of the operations, as returned by NDB
+	OPERATION_STATUS_RUNNING = "1"
+	OPERATION_STATUS_FAILED  = "4"
+	OPERATION_STATUS_PASSED  = "5"
+)
+
+type database struct {
+	ndb_api.DatabaseResponse
+	isClone bool
+}
+
+type timeMachine struct {
+	ndb_api.TimeMachineResponse
+	snapshotIds []string
+}
+
+type operation struct {
+	ndb_api.OperationResponse
+	startedAt time.Time
+	fail      bool
+	completed bool
+	// Applied to the model when the operation completes
+	onSuccess func()
+	onFailure func()
+}
+
+// Starts an operation on an entity completing after the configured duration, it fails if the request
+// matched a fault failing the operation. Requires the lock.
+func (s *Simulator) startOperation(ctx context.Context, name, entityId, entityType string, onSuccess, onFailure func()) *operation {
+	now := s.options.Now()
+	op := &operation{
+		startedAt: now,
+		OperationResponse: ndb_api.OperationResponse{
+			Id:                 uuid.NewString(),
+			Name:               name,
+			Status:             OPERATION_STATUS_RUNNING,
+			PercentageComplete: "0",
+			StartTime:          now.UTC().Format(ndb_api.OPERATION_TIME_LAYOUT),
+			EntityId:           entityId,
+			EntityType:         entityType,
+		},
+		fail:      isFailedOperation(ctx),
+		onSuccess: onSuccess,
+		onFailure: onFailure,
+	}
+	s.operations[op.Id] = op
+	return op
+}
+
+// Completes the operations whose duration has elapsed (in order of start) and updates the
+// progress of the running operations. Called with the lock before serving every request.
+func (s *Simulator) advance() {
+	now := s.options.Now()
+	var due []*operation
+	for _, op := range s.operations {
+		if op.completed {
+			continue
+		}
+		elapsed := now.Sub(op.startedAt)
+		if elapsed >= s.options.OperationDuration {
+			due = append(due, op)
+		} else {
+			op.PercentageComplete = strconv.Itoa(int(100 * elapsed / s.options.OperationDuration))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].startedAt.Before(due[j].startedAt) })
+	for _, op := range due {
+		op.completed = true
+		op.EndTime = now.UTC().Format(ndb_api.OPERATION_TIME_LAYOUT)
+		if op.fail {
+			op.Status = OPERATION_STATUS_FAILED
+			op.Message = "Simulated failure of the operation " + op.Name
+			if op.onFailure != nil {
+				op.onFailure()
+			}
+		} else {
+			op.Status = OPERATION_STATUS_PASSED
+			op.PercentageComplete = "100"
+			op.Message = "Operation " + op.Name + " completed"
+			if op.onSuccess != nil {
+				op.onSuccess()
+			}
+		}
+	}
+}
+
+// Returns the database or clone with the id or name, nil if there is none. Requires the lock.
+func (s *Simulator) findDatabase(isClone bool, match func(*database) bool) *database {
+	for _, db := range s.databases {
+		if db.isClone == isClone && match(db) {
+			return db
+		}
+	}
+	return nil
+}
+
+// Returns true if a database or clone has the name. Requires the lock.
+func (s *Simulator) isDatabaseNameTaken(name string) bool {
+	for _, db := range s.databases {
+		if db.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes a database or clone from the model. Requires the lock.
+func (s *Simulator) removeDatabase(id string, deleteTimeMachine bool) {
+	for i, db := range s.databases {
+		if db.Id == id {
+			s.databases = append(s.databases[:i], s.databases[i+1:]...)
+			if deleteTimeMachine {
+				delete(s.timeMachines, db.TimeMachineId)
+			}
+			return
+		}
+	}
+}
+
+// Adds a database or clone with a new database server and time machine, in the PROVISIONING status.
+// Requires the lock.
+func (s *Simulator) addDatabase(name, engine, clusterId string, isClone bool) *database {
+	s.ipCounter++
+	dbServer := &ndb_api.DatabaseServerResponse{
+		Id:          uuid.NewString(),
+		Name:        name + "_VM",
+		IPAddresses: []string{fmt.Sprintf("10.%d.%d.%d", 10+s.ipCounter/65536, s.ipCounter/256%256, s.ipCounter%256)},
+		NxClusterId: clusterId,
+		Status:      DATABASE_STATUS_PROVISIONING,
+	}
+	s.dbServers[dbServer.Id] = dbServer
+	db := &database{
+		DatabaseResponse: ndb_api.DatabaseResponse{
+			Id:          uuid.NewString(),
+			Name:        name,
+			Status:      DATABASE_STATUS_PROVISIONING,
+			Type:        engine,
+			DateCreated: s.options.Now().UTC().Format(ndb_api.OPERATION_TIME_LAYOUT),
+		},
+		isClone: isClone,
+	}
+	db.DatabaseNodes = []ndb_api.DatabaseNode{{Id: uuid.NewString(), Name: name, DatabaseServerId: dbServer.Id}}
+	tm := &timeMachine{TimeMachineResponse: ndb_api.TimeMachineResponse{
+		Id:         uuid.NewString(),
+		Name:       name + "_TM",
+		DatabaseId: db.Id,
+		Status:     DATABASE_STATUS_PROVISIONING,
+	}}
+	s.timeMachines[tm.Id] = tm
+	db.TimeMachineId = tm.Id
+	s.databases = append(s.databases, db)
+	return db
+}
+
+// Marks a provisioned database and its time machine READY, the time machine gets an initial snapshot.
+// Requires the lock.
+func (s *Simulator) setDatabaseReady(db *database) {
+	db.Status = DATABASE_STATUS_READY
+	for _, node := range db.DatabaseNodes {
+		if dbServer := s.dbServers[node.DatabaseServerId]; dbServer != nil {
+			dbServer.Status = DATABASE_STATUS_READY
+		}
+	}
+	if tm := s.timeMachines[db.TimeMachineId]; tm != nil {
+		tm.Status = DATABASE_STATUS_READY
+		tm.snapshotIds = append(tm.snapshotIds, uuid.NewString())
+	}
+}
+
+// Returns the response for a database or clone, the database nodes include the database server if detailed
+func (s *Simulator) getDatabaseResponse(db *database, detailed bool) ndb_api.DatabaseResponse {
+	response := db.DatabaseResponse
+	response.DatabaseNodes = make([]ndb_api.DatabaseNode, len(db.DatabaseNodes))
+	for i, node := range db.DatabaseNodes {
+		response.DatabaseNodes[i] = node
+		if dbServer := s.dbServers[node.DatabaseServerId]; detailed && dbServer != nil {
+			response.DatabaseNodes[i].DbServer = ndb_api.DatabaseServer{
+				Id:          dbServer.Id,
+				Name:        dbServer.Name,
+				IPAddresses: dbServer.IPAddresses,
+				NxClusterId: dbServer.NxClusterId,
+			}
+		}
+	}
+	return response
+}
+
+// Adds a READY database, for example the source database of clones. Returns the id of the database.
+func (s *Simulator) AddDatabase(name, engine string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	db := s.addDatabase(name, engine, s.options.ClusterId, false)
+	s.setDatabaseReady(db)
+	return db.Id
+}
+
+// Removes a database or clone and its time machine immediately, as if it was deleted outside of the operator.
+// Returns false if there is no database or clone with the id.
+func (s *Simulator) RemoveDatabase(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, db := range s.databases {
+		if db.Id == id {
+			s.removeDatabase(id, true)
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the detailed response of the database or clone with the id
+func (s *Simulator) GetDatabase(id string) (response ndb_api.DatabaseResponse, found bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	for _, db := range s.databases {
+		if db.Id == id {
+			return s.getDatabaseResponse(db, true), true
+		}
+	}
+	return
+}
+
+// Applies changes to a database or clone and its time machine, as if they were made outside of the operator
+// (for example in the NDB UI). Returns false if there is no database or clone with the id.
+func (s *Simulator) ModifyDatabase(id string, modify func(database *ndb_api.DatabaseResponse, timeMachine *ndb_api.TimeMachineResponse)) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, db := range s.databases {
+		if db.Id == id {
+			timeMachine := &ndb_api.TimeMachineResponse{}
+			if tm := s.timeMachines[db.TimeMachineId]; tm != nil {
+				timeMachine = &tm.TimeMachineResponse
+			}
+			modify(&db.DatabaseResponse, timeMachine)
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the time machine with the id
+func (s *Simulator) GetTimeMachine(id string) (response ndb_api.TimeMachineResponse, found bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	if tm := s.timeMachines[id]; tm != nil {
+		return tm.TimeMachineResponse, true
+	}
+	return
+}
+
+// Defines an enabled tag for the entity type (e.g. DATABASE), as if it was created in the NDB UI.
+// Returns the id of the tag.
+func (s *Simulator) AddTag(name, entityType string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	tag := ndb_api.TagResponse{Id: uuid.NewString(), Name: name, EntityType: entityType, Status: common.TAG_STATUS_ENABLED}
+	s.tags = append(s.tags, tag)
+	return tag.Id
+}
+
+// Returns the database server with the id
+func (s *Simulator) GetDatabaseServer(id string) (response ndb_api.DatabaseServerResponse, found bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	if dbServer := s.dbServers[id]; dbServer != nil {
+		return *dbServer, true
+	}
+	return
+}
+
+// Returns true if the database server with the id exists
+func (s *Simulator) HasDatabaseServer(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance()
+	_, found := s.dbServers[id]
+	return found
+}
+
+// Modifies the database server with the id, for example to tag it.
+// Returns false if there is no database server with the id.
+func (s *Simulator) ModifyDatabaseServer(id string, modify func(dbServer *ndb_api.DatabaseServerResponse)) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	dbServer := s.dbServers[id]
+	if dbServer == nil {
+		return false
+	}
+	modify(dbServer)
+	return true
+}